@@ -0,0 +1,13 @@
+package api
+
+import _ "embed"
+
+// Spec is the OpenAPI 3 document describing every route of the service.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// DocsHTML renders Spec in the browser without external assets.
+//
+//go:embed docs.html
+var DocsHTML []byte
@@ -1,50 +1,225 @@
 package config
 
 import (
-	"log"
-
-	"github.com/spf13/viper"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Tracing     TracingConfig
+	Log         LogConfig
+	Maintenance MaintenanceConfig
+	Admin       AdminConfig
+	Ledger      LedgerConfig
+	Scheduler   SchedulerConfig
+	Fees        FeesConfig
+	Interest    InterestConfig
+	Bonus       BonusConfig
+	Escrow      EscrowConfig
 }
 
 type ServerConfig struct {
 	Port string
+	// DrainDelay is how long readiness reports failure before the server
+	// stops accepting connections, giving load balancers time to react.
+	DrainDelay         time.Duration
+	ShutdownTimeout    time.Duration
+	HealthCheckTimeout time.Duration
+	// ValidateRequests checks requests against api/openapi.json.
+	ValidateRequests bool
+	// RequestTimeout bounds the context of every API request.
+	RequestTimeout time.Duration
+	// TrustedProxies are the addresses and networks whose X-Forwarded-For
+	// is believed when finding the client address. None by default.
+	TrustedProxies []string
 }
 
+// Storage drivers accepted in DatabaseConfig.Driver.
+const (
+	DriverPostgres = "postgres"
+	// DriverMemory keeps wallets in process memory; they are lost on exit.
+	DriverMemory = "memory"
+	DriverYDB    = "ydb"
+)
+
+// Transaction isolation levels accepted in DatabaseConfig.Isolation.
+const (
+	IsolationReadCommitted  = "read_committed"
+	IsolationRepeatableRead = "repeatable_read"
+	IsolationSerializable   = "serializable"
+)
+
 type DatabaseConfig struct {
+	Driver string
+	// YDBDSN is used instead of the Postgres settings by DriverYDB.
+	YDBDSN string
+
 	Host     string
 	Port     int
 	User     string
 	Password string
 	Name     string
 	Test     bool
+
+	SSLMode         string
+	ConnectTimeout  time.Duration
+	ApplicationName string
+
+	Pool PoolConfig
+	// StatementTimeout and LockTimeout are applied as session parameters;
+	// zero leaves the server default (no timeout).
+	StatementTimeout time.Duration
+	LockTimeout      time.Duration
+
+	// Isolation is the level of transactions that do not ask for one.
+	Isolation string
+	Retry     RetryConfig
+
+	// ReplicaDSN points at a read-only replica; empty disables replica reads.
+	ReplicaDSN string
+	// ReplicaMaxLag is the staleness above which reads fall back to the
+	// primary.
+	ReplicaMaxLag           time.Duration
+	ReplicaLagCheckInterval time.Duration
 }
 
-const configPath = "./config.env"
+// RetryConfig controls how a unit of work is repeated after a serialization
+// failure, a deadlock or a dropped connection.
+type RetryConfig struct {
+	// MaxAttempts counts the first run; 1 disables retries.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Budget bounds the time from the first attempt after which no retry is
+	// started; zero means no bound.
+	Budget time.Duration
+}
 
-func LoadConfig() *Config {
-	var cfg Config
+type PoolConfig struct {
+	MaxConns          int
+	MinConns          int
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+}
 
-	v := viper.New()
-	v.SetConfigFile(configPath)
-	v.SetConfigType("env")
+type TracingConfig struct {
+	// Exporter is one of "none", "stdout" or "otlp".
+	Exporter     string
+	OTLPEndpoint string
+	OTLPInsecure bool
+	ServiceName  string
+	SampleRatio  float64
+}
 
-	if err := v.ReadInConfig(); err != nil {
-		log.Fatalf("Failed to read config file: %v", err)
-	}
+type LogConfig struct {
+	// Level is one of "debug", "info", "warn" or "error".
+	Level string
+	// Format is "json" or "text".
+	Format string
+}
+
+type MaintenanceConfig struct {
+	// Enabled starts the server read-only; the mode can be switched later
+	// through the admin API or SIGUSR1.
+	Enabled    bool
+	RetryAfter time.Duration
+	// ReadFromReplica keeps reads on the replica while maintenance is on,
+	// however far it lags behind.
+	ReadFromReplica bool
+}
 
-	cfg.Server.Port = v.GetString("SERVER_PORT")
+type AdminConfig struct {
+	// Token authorizes /admin requests; empty rejects all of them.
+	Token string
+	// AuditorToken only authorizes reading the audit log.
+	AuditorToken string
+}
 
-	cfg.Database.Host = v.GetString("DATABASE_HOST")
-	cfg.Database.Port = v.GetInt("DATABASE_PORT")
-	cfg.Database.User = v.GetString("DATABASE_USER")
-	cfg.Database.Password = v.GetString("DATABASE_PASSWORD")
-	cfg.Database.Name = v.GetString("DATABASE_NAME")
-	cfg.Database.Test = v.GetBool("DATABASE_TEST")
+type LedgerConfig struct {
+	// CheckpointInterval is how often serve exports a signed checkpoint of
+	// the transaction chains; zero disables periodic checkpoints.
+	CheckpointInterval time.Duration
+	CheckpointDir      string
+	// SigningKeyFile holds the PEM encoded Ed25519 key checkpoints are
+	// signed with.
+	SigningKeyFile string
+	// SnapshotInterval is how often serve snapshots wallet balances for
+	// point-in-time queries; zero disables snapshots.
+	SnapshotInterval time.Duration
+}
+
+type SchedulerConfig struct {
+	// PollInterval is how often serve looks for due scheduled operations;
+	// zero disables the worker.
+	PollInterval time.Duration
+	// BatchSize bounds the schedules claimed per poll.
+	BatchSize int
+	// Lease is how long a claimed schedule stays with its worker before
+	// another one may take it over.
+	Lease time.Duration
+	// MaxAttempts is the default number of attempts of a scheduled run.
+	MaxAttempts int
+	// RetryDelay is the backoff after the first failed attempt, doubled on
+	// each next one.
+	RetryDelay time.Duration
+}
+
+type FeesConfig struct {
+	// File holds the fee schedule; empty charges no fees.
+	File string
+	// WalletID is the wallet fees are credited to.
+	WalletID string
+}
+
+type InterestConfig struct {
+	// Interval is how often serve accrues and pays out interest; zero
+	// disables the worker.
+	Interval time.Duration
+}
+
+type BonusConfig struct {
+	// SpendingOrder is whether withdrawals spend bonus or cash first, one
+	// of bonus_first and cash_first.
+	SpendingOrder string
+	// ExpiryInterval is how often serve removes expired bonus; zero
+	// disables the worker.
+	ExpiryInterval time.Duration
+}
+
+type EscrowConfig struct {
+	// ExpiryInterval is how often serve refunds escrows past their
+	// deadline; zero disables the worker.
+	ExpiryInterval time.Duration
+}
+
+// DSN builds a postgres:// connection string with credentials escaped and
+// connection options passed as query parameters.
+func (c DatabaseConfig) DSN() string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.User, c.Password),
+		Host:   c.Host + ":" + strconv.Itoa(c.Port),
+		Path:   "/" + c.Name,
+	}
+
+	q := url.Values{}
+	if c.SSLMode != "" {
+		q.Set("sslmode", c.SSLMode)
+	}
+	if c.ConnectTimeout > 0 {
+		// connect_timeout is in whole seconds and 0 waits forever, so a
+		// sub-second timeout is rounded up rather than down.
+		q.Set("connect_timeout", strconv.Itoa(int(math.Ceil(c.ConnectTimeout.Seconds()))))
+	}
+	if c.ApplicationName != "" {
+		q.Set("application_name", c.ApplicationName)
+	}
+	u.RawQuery = q.Encode()
 
-	return &cfg
+	return u.String()
 }
@@ -0,0 +1,388 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultConfigPath is read when present; a missing file is not an error.
+	defaultConfigPath = "./config.env"
+	configFileEnv     = "CONFIG_FILE"
+	configFileFlag    = "config"
+)
+
+// setting describes one configuration value and where it can come from:
+// path in a YAML file, environment variable (also the key in .env files)
+// and command-line flag derived from the environment variable name.
+type setting struct {
+	path  string
+	env   string
+	def   any
+	usage string
+}
+
+var settings = []setting{
+	{"server.port", "SERVER_PORT", "8080", "HTTP listen port"},
+	{"server.drain_delay", "SERVER_DRAIN_DELAY", 5 * time.Second, "time readiness fails before shutdown starts"},
+	{"server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT", 5 * time.Second, "graceful shutdown timeout"},
+	{"server.health_check_timeout", "SERVER_HEALTH_CHECK_TIMEOUT", 2 * time.Second, "timeout of readiness checks"},
+	{"server.validate_requests", "SERVER_VALIDATE_REQUESTS", false, "validate requests against the OpenAPI spec"},
+	{"server.request_timeout", "SERVER_REQUEST_TIMEOUT", 15 * time.Second, "deadline of every API request, 0 disables"},
+	{"server.trusted_proxies", "SERVER_TRUSTED_PROXIES", "", "comma-separated proxy addresses or CIDRs trusted to set X-Forwarded-For"},
+
+	{"database.driver", "DATABASE_DRIVER", DriverPostgres, "storage driver: postgres, memory, ydb"},
+	{"database.ydb_dsn", "DATABASE_YDB_DSN", "grpc://localhost:2136/local", "YDB connection string used by the ydb driver"},
+	{"database.host", "DATABASE_HOST", "localhost", "database host"},
+	{"database.port", "DATABASE_PORT", 5432, "database port"},
+	{"database.user", "DATABASE_USER", "postgres", "database user"},
+	{"database.password", "DATABASE_PASSWORD", "", "database password"},
+	{"database.password_file", "DATABASE_PASSWORD_FILE", "", "file containing the database password"},
+	{"database.name", "DATABASE_NAME", "app", "database name"},
+	{"database.test", "DATABASE_TEST", false, "load test wallets"},
+	{"database.sslmode", "DATABASE_SSLMODE", "prefer", "sslmode: disable, allow, prefer, require, verify-ca, verify-full"},
+	{"database.connect_timeout", "DATABASE_CONNECT_TIMEOUT", 5 * time.Second, "timeout of establishing a connection"},
+	{"database.application_name", "DATABASE_APPLICATION_NAME", "wallet-service", "application_name reported to postgres"},
+	{"database.pool_max_conns", "DATABASE_POOL_MAX_CONNS", 0, "maximum pool size, 0 uses the driver default"},
+	{"database.pool_min_conns", "DATABASE_POOL_MIN_CONNS", 0, "connections kept open when idle"},
+	{"database.pool_max_conn_lifetime", "DATABASE_POOL_MAX_CONN_LIFETIME", time.Hour, "maximum age of a connection"},
+	{"database.pool_max_conn_idle_time", "DATABASE_POOL_MAX_CONN_IDLE_TIME", 30 * time.Minute, "idle time after which a connection is closed"},
+	{"database.pool_health_check_period", "DATABASE_POOL_HEALTH_CHECK_PERIOD", time.Minute, "interval of idle connection health checks"},
+	{"database.statement_timeout", "DATABASE_STATEMENT_TIMEOUT", 30 * time.Second, "statement_timeout, 0 disables"},
+	{"database.lock_timeout", "DATABASE_LOCK_TIMEOUT", 5 * time.Second, "lock_timeout for row locks, 0 disables"},
+	{"database.tx_isolation", "DATABASE_TX_ISOLATION", IsolationReadCommitted, "default transaction isolation: read_committed, repeatable_read, serializable"},
+	{"database.tx_max_attempts", "DATABASE_TX_MAX_ATTEMPTS", 5, "attempts of a transaction failing with a transient error, 1 disables retries"},
+	{"database.tx_retry_base_delay", "DATABASE_TX_RETRY_BASE_DELAY", 10 * time.Millisecond, "backoff before the first retry, doubled on each next one"},
+	{"database.tx_retry_max_delay", "DATABASE_TX_RETRY_MAX_DELAY", 500 * time.Millisecond, "upper bound of the backoff between retries"},
+	{"database.tx_retry_budget", "DATABASE_TX_RETRY_BUDGET", 2 * time.Second, "time after which no retry is started, 0 disables the bound"},
+	{"database.replica_dsn", "DATABASE_REPLICA_DSN", "", "connection string of a read-only replica"},
+	{"database.replica_max_lag", "DATABASE_REPLICA_MAX_LAG", 5 * time.Second, "replica lag above which reads go to the primary"},
+	{"database.replica_lag_check_interval", "DATABASE_REPLICA_LAG_CHECK_INTERVAL", time.Second, "how often replica lag is measured"},
+
+	{"tracing.exporter", "TRACING_EXPORTER", "none", "trace exporter: none, stdout or otlp"},
+	{"tracing.otlp_endpoint", "TRACING_OTLP_ENDPOINT", "", "OTLP gRPC endpoint"},
+	{"tracing.otlp_insecure", "TRACING_OTLP_INSECURE", false, "disable TLS for the OTLP exporter"},
+	{"tracing.service_name", "TRACING_SERVICE_NAME", "wallet-service", "service.name resource attribute"},
+	{"tracing.sample_ratio", "TRACING_SAMPLE_RATIO", 1.0, "fraction of traces to sample"},
+
+	{"log.level", "LOG_LEVEL", "info", "log level: debug, info, warn or error"},
+	{"log.format", "LOG_FORMAT", "json", "log format: json or text"},
+
+	{"maintenance.enabled", "MAINTENANCE_ENABLED", false, "start in read-only maintenance mode"},
+	{"maintenance.retry_after", "MAINTENANCE_RETRY_AFTER", time.Minute, "Retry-After sent with rejected mutations"},
+	{"maintenance.read_from_replica", "MAINTENANCE_READ_FROM_REPLICA", false, "serve reads from the replica during maintenance regardless of lag"},
+
+	{"admin.token", "ADMIN_TOKEN", "", "bearer token of the admin API, empty disables it"},
+	{"admin.auditor_token", "ADMIN_AUDITOR_TOKEN", "", "bearer token allowed to read the audit log only"},
+
+	{"ledger.checkpoint_interval", "LEDGER_CHECKPOINT_INTERVAL", time.Duration(0), "how often to export a signed checkpoint of the transaction chains, 0 disables"},
+	{"ledger.checkpoint_dir", "LEDGER_CHECKPOINT_DIR", "checkpoints", "directory checkpoints are written to"},
+	{"ledger.signing_key_file", "LEDGER_SIGNING_KEY_FILE", "", "PEM encoded Ed25519 key checkpoints are signed with"},
+	{"ledger.snapshot_interval", "LEDGER_SNAPSHOT_INTERVAL", time.Hour, "how often to snapshot wallet balances for point-in-time queries, 0 disables"},
+
+	{"scheduler.poll_interval", "SCHEDULER_POLL_INTERVAL", time.Second, "how often to run due scheduled operations, 0 disables the worker"},
+	{"scheduler.batch_size", "SCHEDULER_BATCH_SIZE", 10, "scheduled operations claimed per poll"},
+	{"scheduler.lease", "SCHEDULER_LEASE", time.Minute, "time a claimed scheduled operation stays with its worker"},
+	{"scheduler.max_attempts", "SCHEDULER_MAX_ATTEMPTS", 5, "default attempts of a scheduled run"},
+	{"scheduler.retry_delay", "SCHEDULER_RETRY_DELAY", 30 * time.Second, "backoff after the first failed attempt of a scheduled run, doubled on each next one"},
+
+	{"fees.file", "FEES_FILE", "", "JSON fee schedule, empty charges no fees"},
+	{"fees.wallet_id", "FEES_WALLET_ID", "", "wallet fees are credited to, required with FEES_FILE"},
+
+	{"interest.interval", "INTEREST_INTERVAL", time.Hour, "how often to accrue and pay out interest on savings wallets, 0 disables"},
+
+	{"bonus.spending_order", "BONUS_SPENDING_ORDER", "bonus_first", "whether withdrawals spend bonus or cash first: bonus_first or cash_first"},
+	{"bonus.expiry_interval", "BONUS_EXPIRY_INTERVAL", time.Hour, "how often to remove expired bonus, 0 disables"},
+
+	{"escrow.expiry_interval", "ESCROW_EXPIRY_INTERVAL", time.Minute, "how often to refund escrows past their deadline, 0 disables"},
+}
+
+func flagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(env), "_", "-")
+}
+
+// RegisterFlags adds --config and one flag per setting to fs.
+func RegisterFlags(fs *pflag.FlagSet) {
+	fs.String(configFileFlag, "", "path to a .env or YAML config file (default "+defaultConfigPath+" if present)")
+	for _, s := range settings {
+		fs.String(flagName(s.env), "", s.usage+" ["+s.env+"]")
+	}
+}
+
+// Load parses args as flags and loads the configuration.
+func Load(args []string) (*Config, error) {
+	fs := pflag.NewFlagSet("wallet-service", pflag.ContinueOnError)
+	RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return LoadFromFlags(fs)
+}
+
+// LoadFromFlags merges, in increasing priority, defaults, the optional
+// config file, environment variables and flags set on fs, then validates
+// the result. All problems are reported together.
+func LoadFromFlags(fs *pflag.FlagSet) (*Config, error) {
+	v := viper.New()
+
+	for _, s := range settings {
+		v.SetDefault(s.path, s.def)
+		if err := v.BindEnv(s.path, s.env); err != nil {
+			return nil, err
+		}
+		if f := fs.Lookup(flagName(s.env)); f != nil {
+			if err := v.BindPFlag(s.path, f); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := readConfigFile(v, fs); err != nil {
+		return nil, err
+	}
+
+	r := &reader{v: v}
+	cfg := r.read()
+
+	if err := errors.Join(append(r.errs, cfg.Validate())...); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func readConfigFile(v *viper.Viper, fs *pflag.FlagSet) error {
+	path, explicit := os.LookupEnv(configFileEnv)
+	if f := fs.Lookup(configFileFlag); f != nil && f.Changed {
+		path, explicit = f.Value.String(), true
+	}
+	if !explicit {
+		path = defaultConfigPath
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		v.SetConfigFile(path)
+		v.SetConfigType("yaml")
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("read config file %s: %w", path, err)
+		}
+	case ".env":
+		return readEnvFile(v, path)
+	default:
+		return fmt.Errorf("config file %s: unsupported extension %q, use .env, .yaml or .yml", path, ext)
+	}
+
+	return nil
+}
+
+// readEnvFile loads KEY=value pairs and maps them onto setting paths so
+// they sit in the same layer as a YAML file.
+func readEnvFile(v *viper.Viper, path string) error {
+	envFile := viper.New()
+	envFile.SetConfigFile(path)
+	envFile.SetConfigType("env")
+	if err := envFile.ReadInConfig(); err != nil {
+		return fmt.Errorf("read config file %s: %w", path, err)
+	}
+
+	values := map[string]any{}
+	for _, s := range settings {
+		key := strings.ToLower(s.env)
+		if !envFile.IsSet(key) {
+			continue
+		}
+		section, name, _ := strings.Cut(s.path, ".")
+		if values[section] == nil {
+			values[section] = map[string]any{}
+		}
+		values[section].(map[string]any)[name] = envFile.Get(key)
+	}
+
+	return v.MergeConfigMap(values)
+}
+
+// reader converts raw values and remembers every conversion error. A value
+// that cannot be converted falls back to its default so that validation
+// does not report the same setting twice.
+type reader struct {
+	v    *viper.Viper
+	errs []error
+}
+
+func (r *reader) read() *Config {
+	var cfg Config
+
+	cfg.Server.Port = r.string("server.port")
+	cfg.Server.DrainDelay = r.duration("server.drain_delay")
+	cfg.Server.ShutdownTimeout = r.duration("server.shutdown_timeout")
+	cfg.Server.HealthCheckTimeout = r.duration("server.health_check_timeout")
+	cfg.Server.ValidateRequests = r.bool("server.validate_requests")
+	cfg.Server.RequestTimeout = r.duration("server.request_timeout")
+	cfg.Server.TrustedProxies = r.list("server.trusted_proxies")
+
+	cfg.Database.Driver = r.string("database.driver")
+	cfg.Database.YDBDSN = r.string("database.ydb_dsn")
+	cfg.Database.Host = r.string("database.host")
+	cfg.Database.Port = r.int("database.port")
+	cfg.Database.User = r.string("database.user")
+	cfg.Database.Password = r.string("database.password")
+	cfg.Database.Name = r.string("database.name")
+	cfg.Database.Test = r.bool("database.test")
+	cfg.Database.SSLMode = r.string("database.sslmode")
+	cfg.Database.ConnectTimeout = r.duration("database.connect_timeout")
+	cfg.Database.ApplicationName = r.string("database.application_name")
+	cfg.Database.Pool.MaxConns = r.int("database.pool_max_conns")
+	cfg.Database.Pool.MinConns = r.int("database.pool_min_conns")
+	cfg.Database.Pool.MaxConnLifetime = r.duration("database.pool_max_conn_lifetime")
+	cfg.Database.Pool.MaxConnIdleTime = r.duration("database.pool_max_conn_idle_time")
+	cfg.Database.Pool.HealthCheckPeriod = r.duration("database.pool_health_check_period")
+	cfg.Database.StatementTimeout = r.duration("database.statement_timeout")
+	cfg.Database.LockTimeout = r.duration("database.lock_timeout")
+	cfg.Database.Isolation = r.string("database.tx_isolation")
+	cfg.Database.Retry.MaxAttempts = r.int("database.tx_max_attempts")
+	cfg.Database.Retry.BaseDelay = r.duration("database.tx_retry_base_delay")
+	cfg.Database.Retry.MaxDelay = r.duration("database.tx_retry_max_delay")
+	cfg.Database.Retry.Budget = r.duration("database.tx_retry_budget")
+	cfg.Database.ReplicaDSN = r.string("database.replica_dsn")
+	cfg.Database.ReplicaMaxLag = r.duration("database.replica_max_lag")
+	cfg.Database.ReplicaLagCheckInterval = r.duration("database.replica_lag_check_interval")
+
+	if passwordFile := r.string("database.password_file"); passwordFile != "" {
+		cfg.Database.Password = r.secret("database.password", passwordFile)
+	}
+
+	cfg.Tracing.Exporter = r.string("tracing.exporter")
+	cfg.Tracing.OTLPEndpoint = r.string("tracing.otlp_endpoint")
+	cfg.Tracing.OTLPInsecure = r.bool("tracing.otlp_insecure")
+	cfg.Tracing.ServiceName = r.string("tracing.service_name")
+	cfg.Tracing.SampleRatio = r.float("tracing.sample_ratio")
+
+	cfg.Log.Level = strings.ToLower(r.string("log.level"))
+	cfg.Log.Format = strings.ToLower(r.string("log.format"))
+
+	cfg.Maintenance.Enabled = r.bool("maintenance.enabled")
+	cfg.Maintenance.RetryAfter = r.duration("maintenance.retry_after")
+	cfg.Maintenance.ReadFromReplica = r.bool("maintenance.read_from_replica")
+
+	cfg.Admin.Token = r.string("admin.token")
+	cfg.Admin.AuditorToken = r.string("admin.auditor_token")
+
+	cfg.Ledger.CheckpointInterval = r.duration("ledger.checkpoint_interval")
+	cfg.Ledger.CheckpointDir = r.string("ledger.checkpoint_dir")
+	cfg.Ledger.SigningKeyFile = r.string("ledger.signing_key_file")
+	cfg.Ledger.SnapshotInterval = r.duration("ledger.snapshot_interval")
+
+	cfg.Scheduler.PollInterval = r.duration("scheduler.poll_interval")
+	cfg.Scheduler.BatchSize = r.int("scheduler.batch_size")
+	cfg.Scheduler.Lease = r.duration("scheduler.lease")
+	cfg.Scheduler.MaxAttempts = r.int("scheduler.max_attempts")
+	cfg.Scheduler.RetryDelay = r.duration("scheduler.retry_delay")
+
+	cfg.Fees.File = r.string("fees.file")
+	cfg.Fees.WalletID = r.string("fees.wallet_id")
+
+	cfg.Interest.Interval = r.duration("interest.interval")
+
+	cfg.Bonus.SpendingOrder = r.string("bonus.spending_order")
+	cfg.Bonus.ExpiryInterval = r.duration("bonus.expiry_interval")
+
+	cfg.Escrow.ExpiryInterval = r.duration("escrow.expiry_interval")
+
+	return &cfg
+}
+
+func (r *reader) fail(path string, err error) {
+	r.errs = append(r.errs, fmt.Errorf("%s: %w", lookup(path).env, err))
+}
+
+func (r *reader) string(path string) string {
+	s, err := cast.ToStringE(r.v.Get(path))
+	if err != nil {
+		r.fail(path, err)
+	}
+	return strings.TrimSpace(s)
+}
+
+// list splits a comma-separated value, dropping empty items.
+func (r *reader) list(path string) []string {
+	var items []string
+	for _, item := range strings.Split(r.string(path), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (r *reader) int(path string) int {
+	i, err := cast.ToIntE(r.v.Get(path))
+	if err != nil {
+		r.fail(path, fmt.Errorf("must be an integer"))
+		return cast.ToInt(lookup(path).def)
+	}
+	return i
+}
+
+func (r *reader) bool(path string) bool {
+	b, err := cast.ToBoolE(r.v.Get(path))
+	if err != nil {
+		r.fail(path, fmt.Errorf("must be true or false"))
+		return cast.ToBool(lookup(path).def)
+	}
+	return b
+}
+
+func (r *reader) float(path string) float64 {
+	f, err := cast.ToFloat64E(r.v.Get(path))
+	if err != nil {
+		r.fail(path, fmt.Errorf("must be a number"))
+		return cast.ToFloat64(lookup(path).def)
+	}
+	return f
+}
+
+func (r *reader) duration(path string) time.Duration {
+	d, err := cast.ToDurationE(r.v.Get(path))
+	if err != nil {
+		r.fail(path, fmt.Errorf("must be a duration such as 5s"))
+		return cast.ToDuration(lookup(path).def)
+	}
+	return d
+}
+
+// secret reads a value from a file, as mounted by Docker or Kubernetes
+// secrets. Setting both the value and the file is ambiguous.
+func (r *reader) secret(path, file string) string {
+	if r.v.GetString(path) != "" {
+		r.fail(path+"_file", fmt.Errorf("cannot be used together with %s", lookup(path).env))
+		return ""
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		r.fail(path+"_file", err)
+		return ""
+	}
+	return strings.TrimRight(string(b), "\r\n")
+}
+
+func lookup(path string) setting {
+	for _, s := range settings {
+		if s.path == path {
+			return s
+		}
+	}
+	panic("config: unknown setting " + path)
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+var (
+	sslModes        = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	logLevels       = []string{"debug", "info", "warn", "error"}
+	logFormats      = []string{"json", "text"}
+	tracingExporter = []string{"none", "stdout", "otlp"}
+	drivers         = []string{DriverPostgres, DriverMemory, DriverYDB}
+	isolations      = []string{IsolationReadCommitted, IsolationRepeatableRead, IsolationSerializable}
+	spendingOrders  = []string{"bonus_first", "cash_first"}
+)
+
+// Validate reports every invalid value at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, env, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: "+format, append([]any{env}, args...)...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port <= 65535, "SERVER_PORT", "must be a port number, got %q", c.Server.Port)
+	check(c.Server.DrainDelay >= 0, "SERVER_DRAIN_DELAY", "must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "SERVER_SHUTDOWN_TIMEOUT", "must be positive")
+	check(c.Server.HealthCheckTimeout > 0, "SERVER_HEALTH_CHECK_TIMEOUT", "must be positive")
+	check(c.Server.RequestTimeout >= 0, "SERVER_REQUEST_TIMEOUT", "must not be negative")
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "SERVER_TRUSTED_PROXIES", "must be IP addresses or CIDRs, got %q", proxy)
+	}
+
+	check(slices.Contains(drivers, c.Database.Driver), "DATABASE_DRIVER", "must be one of %v, got %q", drivers, c.Database.Driver)
+	check(c.Database.Driver == DriverPostgres || c.Database.ReplicaDSN == "", "DATABASE_REPLICA_DSN", "is only supported by the postgres driver")
+	check(c.Database.Driver != DriverYDB || c.Database.YDBDSN != "", "DATABASE_YDB_DSN", "is required by the ydb driver")
+	check(c.Database.Host != "", "DATABASE_HOST", "is required")
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "DATABASE_PORT", "must be a port number, got %d", c.Database.Port)
+	check(c.Database.User != "", "DATABASE_USER", "is required")
+	check(c.Database.Name != "", "DATABASE_NAME", "is required")
+	check(slices.Contains(sslModes, c.Database.SSLMode), "DATABASE_SSLMODE", "must be one of %v, got %q", sslModes, c.Database.SSLMode)
+	check(c.Database.ConnectTimeout >= 0, "DATABASE_CONNECT_TIMEOUT", "must not be negative")
+	check(c.Database.Pool.MaxConns >= 0, "DATABASE_POOL_MAX_CONNS", "must not be negative")
+	check(c.Database.Pool.MinConns >= 0, "DATABASE_POOL_MIN_CONNS", "must not be negative")
+	check(c.Database.Pool.MaxConns == 0 || c.Database.Pool.MinConns <= c.Database.Pool.MaxConns,
+		"DATABASE_POOL_MIN_CONNS", "must not exceed DATABASE_POOL_MAX_CONNS")
+	check(c.Database.Pool.MaxConnLifetime > 0, "DATABASE_POOL_MAX_CONN_LIFETIME", "must be positive")
+	check(c.Database.Pool.MaxConnIdleTime > 0, "DATABASE_POOL_MAX_CONN_IDLE_TIME", "must be positive")
+	check(c.Database.Pool.HealthCheckPeriod > 0, "DATABASE_POOL_HEALTH_CHECK_PERIOD", "must be positive")
+	check(c.Database.StatementTimeout >= 0, "DATABASE_STATEMENT_TIMEOUT", "must not be negative")
+	check(c.Database.LockTimeout >= 0, "DATABASE_LOCK_TIMEOUT", "must not be negative")
+	check(slices.Contains(isolations, c.Database.Isolation), "DATABASE_TX_ISOLATION", "must be one of %v, got %q", isolations, c.Database.Isolation)
+	check(c.Database.Retry.MaxAttempts >= 1, "DATABASE_TX_MAX_ATTEMPTS", "must be at least 1")
+	check(c.Database.Retry.BaseDelay >= 0, "DATABASE_TX_RETRY_BASE_DELAY", "must not be negative")
+	check(c.Database.Retry.MaxDelay >= c.Database.Retry.BaseDelay, "DATABASE_TX_RETRY_MAX_DELAY", "must not be below DATABASE_TX_RETRY_BASE_DELAY")
+	check(c.Database.Retry.Budget >= 0, "DATABASE_TX_RETRY_BUDGET", "must not be negative")
+	check(c.Database.ReplicaMaxLag >= 0, "DATABASE_REPLICA_MAX_LAG", "must not be negative")
+	check(c.Database.ReplicaLagCheckInterval > 0, "DATABASE_REPLICA_LAG_CHECK_INTERVAL", "must be positive")
+
+	check(slices.Contains(tracingExporter, c.Tracing.Exporter), "TRACING_EXPORTER", "must be one of %v, got %q", tracingExporter, c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+
+	check(c.Maintenance.RetryAfter > 0, "MAINTENANCE_RETRY_AFTER", "must be positive")
+	check(!c.Maintenance.ReadFromReplica || c.Database.ReplicaDSN != "", "MAINTENANCE_READ_FROM_REPLICA", "requires DATABASE_REPLICA_DSN")
+
+	check(c.Ledger.CheckpointInterval >= 0, "LEDGER_CHECKPOINT_INTERVAL", "must not be negative")
+	check(c.Ledger.CheckpointInterval == 0 || c.Ledger.SigningKeyFile != "", "LEDGER_CHECKPOINT_INTERVAL", "requires LEDGER_SIGNING_KEY_FILE")
+	check(c.Ledger.CheckpointDir != "", "LEDGER_CHECKPOINT_DIR", "is required")
+	check(c.Ledger.SnapshotInterval >= 0, "LEDGER_SNAPSHOT_INTERVAL", "must not be negative")
+
+	check(c.Scheduler.PollInterval >= 0, "SCHEDULER_POLL_INTERVAL", "must not be negative")
+	check(c.Scheduler.BatchSize > 0, "SCHEDULER_BATCH_SIZE", "must be positive")
+	check(c.Scheduler.Lease > 0, "SCHEDULER_LEASE", "must be positive")
+	check(c.Scheduler.MaxAttempts > 0, "SCHEDULER_MAX_ATTEMPTS", "must be positive")
+	check(c.Scheduler.RetryDelay >= 0, "SCHEDULER_RETRY_DELAY", "must not be negative")
+
+	if c.Fees.File != "" || c.Fees.WalletID != "" {
+		_, err := uuid.Parse(c.Fees.WalletID)
+		check(err == nil, "FEES_WALLET_ID", "must be a wallet id, got %q", c.Fees.WalletID)
+	}
+
+	check(c.Interest.Interval >= 0, "INTEREST_INTERVAL", "must not be negative")
+
+	check(slices.Contains(spendingOrders, c.Bonus.SpendingOrder), "BONUS_SPENDING_ORDER", "must be one of %v, got %q", spendingOrders, c.Bonus.SpendingOrder)
+	check(c.Bonus.ExpiryInterval >= 0, "BONUS_EXPIRY_INTERVAL", "must not be negative")
+
+	check(c.Escrow.ExpiryInterval >= 0, "ESCROW_EXPIRY_INTERVAL", "must not be negative")
+
+	check(slices.Contains(logLevels, c.Log.Level), "LOG_LEVEL", "must be one of %v, got %q", logLevels, c.Log.Level)
+	check(slices.Contains(logFormats, c.Log.Format), "LOG_FORMAT", "must be one of %v, got %q", logFormats, c.Log.Format)
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,36 @@
+// Package audit carries who is behind a request from the transport layer to
+// the code that writes the audit log.
+package audit
+
+import "context"
+
+// Actors of requests that carry no identity of their own.
+const (
+	Anonymous = "anonymous"
+	Admin     = "admin"
+	Auditor   = "auditor"
+	Signal    = "signal"
+	CLI       = "cli"
+	Interest  = "interest"
+	Expiry    = "expiry"
+)
+
+type Actor struct {
+	ID       string
+	SourceIP string
+}
+
+type actorKey struct{}
+
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+// ActorFrom returns the actor stored in ctx, or Anonymous without a source
+// address when there is none.
+func ActorFrom(ctx context.Context) Actor {
+	if a, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return a
+	}
+	return Actor{ID: Anonymous}
+}
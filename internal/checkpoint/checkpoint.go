@@ -0,0 +1,204 @@
+// Package checkpoint signs the heads of the transaction chains and exports
+// them to files kept outside the database.
+//
+// A checkpoint is a JSON document with the head of every chain, the root hash
+// over them and an Ed25519 signature of the root. Anyone holding the public
+// key can later check that the chains still pass through the recorded heads.
+package checkpoint
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/metrics"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidSignature = errors.New("checkpoint signature is invalid")
+
+// Source produces the checkpoint to sign.
+type Source interface {
+	Checkpoint(ctx context.Context) (*domain.Checkpoint, error)
+}
+
+// File is the exported form of a signed checkpoint.
+type File struct {
+	At    time.Time   `json:"at"`
+	Root  domain.Hash `json:"root"`
+	Heads []Head      `json:"heads"`
+	// PublicKey identifies the signing key; trust it only after comparing
+	// with a copy obtained elsewhere.
+	PublicKey string `json:"publicKey"`
+	Signature string `json:"signature"`
+}
+
+type Head struct {
+	WalletID uuid.UUID   `json:"walletId"`
+	Seq      int64       `json:"seq"`
+	Hash     domain.Hash `json:"hash"`
+}
+
+// LoadKey reads a PEM encoded PKCS #8 Ed25519 private key, as produced by
+// `openssl genpkey -algorithm ed25519`.
+func LoadKey(path string) (ed25519.PrivateKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is %T, not Ed25519", path, key)
+	}
+	return edKey, nil
+}
+
+// Sign seals cp with key.
+func Sign(cp *domain.Checkpoint, key ed25519.PrivateKey) *File {
+	f := &File{
+		At:        cp.At,
+		Root:      cp.Root,
+		Heads:     make([]Head, 0, len(cp.Heads)),
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, cp.SignedContent())),
+	}
+	for _, head := range cp.Heads {
+		f.Heads = append(f.Heads, Head(head))
+	}
+	return f
+}
+
+// Verify checks that the heads add up to the root and that the signature
+// was made by pub. It returns the verified checkpoint.
+func Verify(f *File, pub ed25519.PublicKey) (*domain.Checkpoint, error) {
+	heads := make([]domain.ChainHead, 0, len(f.Heads))
+	for _, head := range f.Heads {
+		heads = append(heads, domain.ChainHead(head))
+	}
+	cp := &domain.Checkpoint{At: f.At, Heads: heads, Root: domain.CheckpointRoot(heads)}
+	if cp.Root != f.Root {
+		return nil, fmt.Errorf("%w: heads do not add up to the root", ErrInvalidSignature)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(f.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if !ed25519.Verify(pub, cp.SignedContent(), sig) {
+		return nil, ErrInvalidSignature
+	}
+	return cp, nil
+}
+
+// Write stores f in dir under a name derived from its time. The file
+// appears complete or not at all.
+func Write(dir string, f *File) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("create checkpoint directory: %w", err)
+	}
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "checkpoint-"+f.At.UTC().Format("20060102T150405.000000000Z")+".json")
+	tmp, err := os.CreateTemp(dir, ".checkpoint-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("write checkpoint: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err = tmp.Write(append(b, '\n')); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("write checkpoint: %w", err)
+	}
+	return path, nil
+}
+
+// Exporter signs checkpoints from a source and writes them to a directory.
+type Exporter struct {
+	source Source
+	key    ed25519.PrivateKey
+	dir    string
+	log    *slog.Logger
+}
+
+func NewExporter(source Source, key ed25519.PrivateKey, dir string, log *slog.Logger) *Exporter {
+	return &Exporter{source: source, key: key, dir: dir, log: log}
+}
+
+// Export takes, signs and writes one checkpoint and returns the file path.
+func (e *Exporter) Export(ctx context.Context) (_ string, err error) {
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "error"
+		}
+		metrics.Checkpoints.WithLabelValues(outcome).Inc()
+	}()
+
+	cp, err := e.source.Checkpoint(ctx)
+	if err != nil {
+		return "", fmt.Errorf("take checkpoint: %w", err)
+	}
+
+	path, err := Write(e.dir, Sign(cp, e.key))
+	if err != nil {
+		return "", err
+	}
+	metrics.LastCheckpoint.Set(float64(cp.At.Unix()))
+
+	e.log.InfoContext(ctx, "checkpoint exported",
+		slog.String("path", path),
+		slog.Int("wallets", len(cp.Heads)),
+		slog.String("root", cp.Root.String()),
+	)
+	return path, nil
+}
+
+// Run exports a checkpoint every interval until ctx is done. Failures are
+// logged and retried at the next tick.
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := e.Export(ctx); err != nil && ctx.Err() == nil {
+				e.log.ErrorContext(ctx, "failed to export checkpoint", logger.Err(err))
+			}
+		}
+	}
+}
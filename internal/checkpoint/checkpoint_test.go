@@ -0,0 +1,103 @@
+package checkpoint
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSource struct {
+	cp *domain.Checkpoint
+}
+
+func (s staticSource) Checkpoint(context.Context) (*domain.Checkpoint, error) {
+	return s.cp, nil
+}
+
+func testCheckpoint() *domain.Checkpoint {
+	return domain.NewCheckpoint(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), []domain.ChainHead{
+		{WalletID: uuid.New(), Seq: 7, Hash: domain.Hash{7}},
+		{WalletID: uuid.New(), Seq: 2, Hash: domain.Hash{2}},
+	})
+}
+
+func TestSign_Verify_RoundTrip(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	cp := testCheckpoint()
+
+	verified, err := Verify(Sign(cp, key), pub)
+
+	require.NoError(t, err)
+	assert.Equal(t, cp.Root, verified.Root)
+	assert.Equal(t, cp.Heads, verified.Heads)
+}
+
+func TestVerify_EditedHead_Rejected(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	f := Sign(testCheckpoint(), key)
+
+	f.Heads[0].Seq--
+
+	_, err = Verify(f, pub)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerify_OtherKey_Rejected(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	_, err = Verify(Sign(testCheckpoint(), key), otherPub)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestExporter_Export_WritesVerifiableFile(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	dir := filepath.Join(t.TempDir(), "checkpoints")
+	cp := testCheckpoint()
+
+	path, err := NewExporter(staticSource{cp}, key, dir, slog.New(slog.DiscardHandler)).Export(t.Context())
+	require.NoError(t, err)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var f File
+	require.NoError(t, json.Unmarshal(b, &f))
+	_, err = Verify(&f, pub)
+	assert.NoError(t, err)
+
+	// Временные файлы не остаются в каталоге
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLoadKey_PKCS8_Loaded(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	loaded, err := LoadKey(path)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(loaded))
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"wallet-service/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+func (a *app) expireBonusesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "expire-bonuses",
+		Short: "Remove expired bonus from wallets",
+		Long: "Remove what is left of every bonus that has expired, recording it in the\n" +
+			"ledger, as serve does every BONUS_EXPIRY_INTERVAL. Prints the number of\n" +
+			"wallets that lost bonus.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			repositories, closeRepository, err := a.openRepository(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeRepository()
+
+			opts, err := a.walletOptions(cmd.Context(), repositories)
+			if err != nil {
+				return err
+			}
+			services := service.NewService(repositories, a.log, opts...)
+			if services.Bonus == nil {
+				return fmt.Errorf("command is not available with DATABASE_DRIVER=%s", a.cfg.Database.Driver)
+			}
+
+			expired, err := services.Bonus.Expire(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "expired %d\n", expired)
+			return err
+		},
+	}
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"wallet-service/internal/checkpoint"
+	"wallet-service/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+type chainReportOutput struct {
+	Wallets      int               `json:"wallets"`
+	Transactions int64             `json:"transactions"`
+	Intact       bool              `json:"intact"`
+	Broken       *chainBreakOutput `json:"broken,omitempty"`
+}
+
+type chainBreakOutput struct {
+	WalletID string `json:"walletId"`
+	Seq      int64  `json:"seq"`
+	Reason   string `json:"reason"`
+}
+
+func (a *app) verifyChainCommand() *cobra.Command {
+	var walletID string
+
+	cmd := &cobra.Command{
+		Use:   "verify-chain",
+		Short: "Walk the transaction chains and report the first broken link",
+		Long: "Walk the transaction chains of all wallets, or of one with --wallet, and\n" +
+			"print a report. Exits with code 1 when a link is broken.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var id *uuid.UUID
+			if walletID != "" {
+				parsed, err := uuid.Parse(walletID)
+				if err != nil {
+					return fmt.Errorf("invalid wallet id %q", walletID)
+				}
+				id = &parsed
+			}
+
+			repositories, closeRepository, err := a.openRepository(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeRepository()
+
+			services := service.NewService(repositories, a.log)
+			report, err := services.Chain.Verify(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			out := chainReportOutput{
+				Wallets:      report.Wallets,
+				Transactions: report.Transactions,
+				Intact:       report.Break == nil,
+			}
+			if report.Break != nil {
+				out.Broken = &chainBreakOutput{
+					WalletID: report.Break.WalletID.String(),
+					Seq:      report.Break.Seq,
+					Reason:   report.Break.Reason,
+				}
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(out); err != nil {
+				return err
+			}
+
+			if report.Break != nil {
+				return report.Break
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&walletID, "wallet", "", "verify only the wallet with this id")
+
+	return cmd
+}
+
+func (a *app) checkpointCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "checkpoint",
+		Short: "Export a signed checkpoint of the transaction chains",
+		Long: "Sign the current head of every transaction chain with LEDGER_SIGNING_KEY_FILE\n" +
+			"and write it to LEDGER_CHECKPOINT_DIR. Prints the path of the file.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if a.cfg.Ledger.SigningKeyFile == "" {
+				return fmt.Errorf("LEDGER_SIGNING_KEY_FILE is required")
+			}
+			key, err := checkpoint.LoadKey(a.cfg.Ledger.SigningKeyFile)
+			if err != nil {
+				return err
+			}
+
+			repositories, closeRepository, err := a.openRepository(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeRepository()
+
+			services := service.NewService(repositories, a.log)
+			path, err := checkpoint.NewExporter(services.Chain, key, a.cfg.Ledger.CheckpointDir, a.log).Export(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), path)
+			return err
+		},
+	}
+}
+
+func (a *app) snapshotCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "snapshot",
+		Short: "Snapshot wallet balances for point-in-time queries",
+		Long: "Record the balance at the head of every transaction chain, as serve does every\n" +
+			"LEDGER_SNAPSHOT_INTERVAL. Prints the number of wallets snapshotted.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			repositories, closeRepository, err := a.openRepository(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeRepository()
+
+			services := service.NewService(repositories, a.log)
+			count, err := services.History.Snapshot(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), count)
+			return err
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"wallet-service/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+func (a *app) expireEscrowsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "expire-escrows",
+		Short: "Refund escrows past their deadline",
+		Long: "Refund every escrow that has not been released or cancelled by its deadline\n" +
+			"to the buyer, as serve does every ESCROW_EXPIRY_INTERVAL. Prints the number\n" +
+			"of escrows refunded.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			repositories, closeRepository, err := a.openRepository(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeRepository()
+
+			opts, err := a.walletOptions(cmd.Context(), repositories)
+			if err != nil {
+				return err
+			}
+			services := service.NewService(repositories, a.log, opts...)
+			if services.Escrow == nil {
+				return fmt.Errorf("command is not available with DATABASE_DRIVER=%s", a.cfg.Database.Driver)
+			}
+
+			expired, err := services.Escrow.Expire(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "expired %d\n", expired)
+			return err
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"wallet-service/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+func (a *app) interestCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "interest",
+		Short: "Accrue and pay out interest on savings wallets",
+		Long: "Accrue interest for every day that has ended and pay out every month that has\n" +
+			"been accrued, as serve does every INTEREST_INTERVAL. Running it again pays\n" +
+			"nothing twice. Prints the number of accruals and payouts made.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			repositories, closeRepository, err := a.openRepository(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeRepository()
+
+			opts, err := a.walletOptions(cmd.Context(), repositories)
+			if err != nil {
+				return err
+			}
+			services := service.NewService(repositories, a.log, opts...)
+			if services.Interest == nil {
+				return fmt.Errorf("command is not available with DATABASE_DRIVER=%s", a.cfg.Database.Driver)
+			}
+
+			accrued, paid, err := services.Interest.Run(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "accrued %d\npaid %d\n", accrued, paid)
+			return err
+		},
+	}
+}
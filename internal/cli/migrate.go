@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/migrate"
+	"wallet-service/internal/repository"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/spf13/cobra"
+)
+
+func (a *app) migrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the database schema",
+		Long: "Manage the database schema. With DATABASE_TEST=true the test wallets\n" +
+			"from migrations/test are applied as well. With DATABASE_DRIVER=ydb only\n" +
+			"`up` is available: it creates the missing tables.",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply all pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if a.cfg.Database.Driver == config.DriverYDB {
+					return a.bootstrapYDB(cmd)
+				}
+				return a.withMigrations(func(ctx context.Context, p *goose.Provider, out io.Writer) error {
+					results, err := p.Up(ctx)
+					printResults(out, results)
+					return err
+				})(cmd, args)
+			},
+		},
+		&cobra.Command{
+			Use:   "down",
+			Short: "Roll back the latest migration",
+			Args:  cobra.NoArgs,
+			RunE: a.withMigrations(func(ctx context.Context, p *goose.Provider, out io.Writer) error {
+				result, err := p.Down(ctx)
+				if result != nil {
+					printResults(out, []*goose.MigrationResult{result})
+				}
+				return err
+			}),
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show applied and pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: a.withMigrations(func(ctx context.Context, p *goose.Provider, out io.Writer) error {
+				statuses, err := p.Status(ctx)
+				if err != nil {
+					return err
+				}
+				return printStatus(out, statuses)
+			}),
+		},
+		&cobra.Command{
+			Use:   "to VERSION",
+			Short: "Migrate up or down to VERSION",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				version, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil || version < 0 {
+					return fmt.Errorf("invalid version %q", args[0])
+				}
+				return a.withMigrations(func(ctx context.Context, p *goose.Provider, out io.Writer) error {
+					return migrateTo(ctx, p, out, version)
+				})(cmd, args)
+			},
+		},
+	)
+
+	return cmd
+}
+
+func (a *app) withMigrations(run func(ctx context.Context, p *goose.Provider, out io.Writer) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		if driver := a.cfg.Database.Driver; driver != config.DriverPostgres {
+			return fmt.Errorf("command is not available with DATABASE_DRIVER=%s", driver)
+		}
+
+		sqlDB, err := sql.Open("pgx", a.cfg.Database.DSN())
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := sqlDB.Close(); err != nil {
+				a.log.Warn("failed to close sqlDB", logger.Err(err))
+			}
+		}()
+
+		provider, err := migrate.NewProvider(sqlDB, a.migrations, a.cfg.Database.Test, a.log)
+		if err != nil {
+			return err
+		}
+
+		return run(cmd.Context(), provider, cmd.OutOrStdout())
+	}
+}
+
+func migrateTo(ctx context.Context, p *goose.Provider, out io.Writer, version int64) error {
+	current, err := p.GetDBVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	var results []*goose.MigrationResult
+	if version >= current {
+		results, err = p.UpTo(ctx, version)
+	} else {
+		results, err = p.DownTo(ctx, version)
+	}
+	printResults(out, results)
+	return err
+}
+
+func printResults(out io.Writer, results []*goose.MigrationResult) {
+	if len(results) == 0 {
+		fmt.Fprintln(out, "no migrations to apply")
+		return
+	}
+	for _, result := range results {
+		fmt.Fprintln(out, result)
+	}
+}
+
+func printStatus(out io.Writer, statuses []*goose.MigrationStatus) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tSOURCE")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.State == goose.StateApplied {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Source.Version, status.State, appliedAt, status.Source.Path)
+	}
+	return w.Flush()
+}
+
+// bootstrapYDB creates the YDB tables; YDB has no versioned migrations.
+func (a *app) bootstrapYDB(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	y, err := repository.OpenYDB(ctx, a.cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer a.closeYDB(y)()
+
+	if err := repository.BootstrapYDBSchema(ctx, y.DB); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), "ydb schema is up to date")
+	return err
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"wallet-service/config"
+	"wallet-service/internal/fees"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// logToStdout marks commands whose logs go to stdout; the others keep
+// stdout for their output and log to stderr.
+const logToStdout = "log-to-stdout"
+
+type app struct {
+	migrations fs.FS
+	cfg        *config.Config
+	log        *slog.Logger
+}
+
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string {
+	return e.err.Error()
+}
+
+func (e *configError) Unwrap() error {
+	return e.err
+}
+
+// Execute runs the subcommand selected by args and returns the process exit
+// code: 2 for an invalid configuration, 1 for any other failure.
+func Execute(ctx context.Context, migrations fs.FS, args []string) int {
+	a := &app{migrations: migrations}
+	root := a.rootCommand()
+	root.SetArgs(args)
+
+	err := root.ExecuteContext(ctx)
+	if err == nil {
+		return 0
+	}
+
+	var cfgErr *configError
+	if errors.As(err, &cfgErr) {
+		fmt.Fprintf(root.ErrOrStderr(), "invalid configuration:\n%v\n", err)
+		return 2
+	}
+	if a.log != nil {
+		a.log.Error("command failed", logger.Err(err))
+	} else {
+		fmt.Fprintf(root.ErrOrStderr(), "Error: %v\n", err)
+	}
+	return 1
+}
+
+func (a *app) rootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "wallet-service",
+		Short:         "Wallet balance service",
+		SilenceErrors: true,
+		CompletionOptions: cobra.CompletionOptions{
+			DisableDefaultCmd: true,
+		},
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			// Usage only helps with argument errors, not with failures
+			// while running.
+			cmd.SilenceUsage = true
+			return a.load(cmd)
+		},
+	}
+	config.RegisterFlags(root.PersistentFlags())
+
+	root.AddCommand(
+		a.serveCommand(),
+		a.migrateCommand(),
+		a.seedCommand(),
+		a.walletCommand(),
+		a.verifyChainCommand(),
+		a.checkpointCommand(),
+		a.snapshotCommand(),
+		a.interestCommand(),
+		a.expireBonusesCommand(),
+		a.expireEscrowsCommand(),
+		a.statementCommand(),
+	)
+
+	return root
+}
+
+func (a *app) load(cmd *cobra.Command) error {
+	cfg, err := config.LoadFromFlags(cmd.Flags())
+	if err != nil {
+		return &configError{err: err}
+	}
+
+	var out io.Writer = cmd.ErrOrStderr()
+	if _, ok := cmd.Annotations[logToStdout]; ok {
+		out = cmd.OutOrStdout()
+	}
+	log, err := logger.New(out, cfg.Log)
+	if err != nil {
+		return fmt.Errorf("create logger: %w", err)
+	}
+	slog.SetDefault(log)
+
+	a.cfg, a.log = cfg, log
+	return nil
+}
+
+// openRepository connects to the database for the operator commands. The
+// returned func closes the connections.
+func (a *app) openRepository(ctx context.Context) (*repository.Repository, func(), error) {
+	switch a.cfg.Database.Driver {
+	case config.DriverPostgres:
+		pool, err := repository.NewPool(ctx, a.cfg.Database)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open database pool: %w", err)
+		}
+
+		repositories, err := repository.NewPostgresRepository(pool, a.log, repository.WithDefaultIsolation(a.cfg.Database.Isolation))
+		if err != nil {
+			pool.Close()
+			return nil, nil, err
+		}
+
+		return repositories, pool.Close, nil
+	case config.DriverYDB:
+		y, err := repository.OpenYDB(ctx, a.cfg.Database)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repository.NewYDBRepository(y.DB, a.log), a.closeYDB(y), nil
+	default:
+		return nil, nil, errMemoryDriver
+	}
+}
+
+// walletOptions applies the retry policy, the spending order and the fee
+// schedule of the config to wallet operations. The fee wallet must exist in repositories.
+func (a *app) walletOptions(ctx context.Context, repositories *repository.Repository) ([]service.Option, error) {
+	opts := []service.Option{service.WithRetry(a.cfg.Database.Retry), service.WithSpendingOrder(a.cfg.Bonus.SpendingOrder)}
+	if a.cfg.Fees.File == "" {
+		return opts, nil
+	}
+
+	schedule, err := fees.Load(a.cfg.Fees.File)
+	if err != nil {
+		return nil, err
+	}
+	collector := uuid.MustParse(a.cfg.Fees.WalletID)
+	wallet, err := repositories.Get(ctx, collector)
+	if err != nil {
+		return nil, fmt.Errorf("fee wallet %s: %w", collector, err)
+	}
+	wallet.Release()
+
+	return append(opts, service.WithFees(schedule, collector)), nil
+}
+
+// errMemoryDriver rejects operator commands under the memory driver: the
+// wallets live inside the serve process and cannot be reached from here.
+var errMemoryDriver = fmt.Errorf("command is not available with DATABASE_DRIVER=%s", config.DriverMemory)
+
+func (a *app) closeYDB(y *repository.YDB) func() {
+	return func() {
+		if err := y.Close(context.Background()); err != nil {
+			a.log.Warn("failed to close ydb", logger.Err(err))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute_InvalidConfig_ExitCode2(t *testing.T) {
+	code := Execute(t.Context(), fstest.MapFS{}, []string{"serve", "--log-level", "bogus"})
+
+	assert.Equal(t, 2, code)
+}
+
+func TestExecute_UnknownCommand_ExitCode1(t *testing.T) {
+	code := Execute(t.Context(), fstest.MapFS{}, []string{"unknown"})
+
+	assert.Equal(t, 1, code)
+}
+
+func TestExecute_MigrateWithMemoryDriver_ExitCode1(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("DATABASE_DRIVER", "memory")
+
+	// Память процесса недоступна операторским командам
+	code := Execute(t.Context(), fstest.MapFS{}, []string{"migrate", "status"})
+
+	assert.Equal(t, 1, code)
+}
+
+func TestExecute_Help_ExitCode0(t *testing.T) {
+	code := Execute(t.Context(), fstest.MapFS{}, []string{"migrate", "--help"})
+
+	assert.Equal(t, 0, code)
+}
+
+func TestRootCommand_Subcommands_Registered(t *testing.T) {
+	root := (&app{}).rootCommand()
+
+	for _, path := range [][]string{
+		{"serve"},
+		{"migrate", "up"},
+		{"migrate", "down"},
+		{"migrate", "status"},
+		{"migrate", "to"},
+		{"seed"},
+		{"wallet", "get"},
+		{"wallet", "deposit"},
+		{"wallet", "withdraw"},
+		{"verify-chain"},
+		{"checkpoint"},
+		{"snapshot"},
+		{"interest"},
+		{"expire-bonuses"},
+		{"expire-escrows"},
+		{"statement"},
+	} {
+		cmd, _, err := root.Find(path)
+		assert.NoError(t, err, path)
+		assert.Equal(t, path[len(path)-1], cmd.Name())
+	}
+}
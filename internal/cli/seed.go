@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+const (
+	seedFormatCSV  = "csv"
+	seedFormatJSON = "json"
+)
+
+type seedWallet struct {
+	WalletID uuid.UUID `json:"walletId"`
+	Balance  int64     `json:"balance"`
+}
+
+func (a *app) seedCommand() *cobra.Command {
+	var (
+		format       string
+		skipExisting bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "seed FILE",
+		Short: "Create wallets from a CSV or JSON file",
+		Long: "Create wallets from a CSV file with a walletId,balance header or a JSON\n" +
+			"array of {\"walletId\": ..., \"balance\": ...}. All wallets are created in\n" +
+			"one transaction; an existing wallet aborts the seed unless --skip-existing.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "" {
+				format = strings.TrimPrefix(strings.ToLower(filepath.Ext(args[0])), ".")
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			wallets, err := readSeed(f, format)
+			if err != nil {
+				return fmt.Errorf("read %s: %w", args[0], err)
+			}
+
+			return a.seed(cmd, wallets, skipExisting)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "file format: csv or json (default from the file extension)")
+	cmd.Flags().BoolVar(&skipExisting, "skip-existing", false, "leave wallets that already exist untouched")
+
+	return cmd
+}
+
+func (a *app) seed(cmd *cobra.Command, wallets []seedWallet, skipExisting bool) error {
+	ctx := cmd.Context()
+
+	repositories, closeRepository, err := a.openRepository(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeRepository()
+
+	txCtx, tx, err := repositories.WithTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, repository.ErrTxClosed) {
+			a.log.Warn("failed to roll back transaction", logger.Err(err))
+		}
+	}()
+
+	var created, skipped int
+	for _, w := range wallets {
+		wallet, err := domain.NewWallet(w.WalletID, w.Balance)
+		if err != nil {
+			return fmt.Errorf("wallet %s: %w", w.WalletID, err)
+		}
+
+		_, err = repositories.Create(txCtx, wallet)
+		wallet.Release()
+		if errors.Is(err, domain.ErrWalletExists) && skipExisting {
+			skipped++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("wallet %s: %w", w.WalletID, err)
+		}
+		created++
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "created %d wallets, skipped %d existing\n", created, skipped)
+	return nil
+}
+
+func readSeed(r io.Reader, format string) ([]seedWallet, error) {
+	switch format {
+	case seedFormatCSV:
+		return readSeedCSV(r)
+	case seedFormatJSON:
+		var wallets []seedWallet
+		if err := json.NewDecoder(r).Decode(&wallets); err != nil {
+			return nil, err
+		}
+		return wallets, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q, want %s or %s", format, seedFormatCSV, seedFormatJSON)
+	}
+}
+
+func readSeedCSV(r io.Reader) ([]seedWallet, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header")
+	}
+
+	idCol, balanceCol := -1, -1
+	for i, name := range records[0] {
+		switch strings.TrimSpace(name) {
+		case "walletId":
+			idCol = i
+		case "balance":
+			balanceCol = i
+		}
+	}
+	if idCol < 0 || balanceCol < 0 {
+		return nil, errors.New("header must contain walletId and balance")
+	}
+
+	wallets := make([]seedWallet, 0, len(records)-1)
+	for i, record := range records[1:] {
+		line := i + 2
+
+		id, err := uuid.Parse(strings.TrimSpace(record[idCol]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: walletId: %w", line, err)
+		}
+		balance, err := strconv.ParseInt(strings.TrimSpace(record[balanceCol]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: balance: %w", line, err)
+		}
+
+		wallets = append(wallets, seedWallet{WalletID: id, Balance: balance})
+	}
+
+	return wallets, nil
+}
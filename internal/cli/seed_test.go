@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSeed_CSV_ReturnsWallets(t *testing.T) {
+	input := "balance,walletId\n100,3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901\n0, 5d2c7e80-1a34-4b74-8cc2-9f0e4f3c2a13\n"
+
+	wallets, err := readSeed(strings.NewReader(input), seedFormatCSV)
+
+	require.NoError(t, err)
+	assert.Equal(t, []seedWallet{
+		{WalletID: uuid.MustParse("3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901"), Balance: 100},
+		{WalletID: uuid.MustParse("5d2c7e80-1a34-4b74-8cc2-9f0e4f3c2a13"), Balance: 0},
+	}, wallets)
+}
+
+func TestReadSeed_JSON_ReturnsWallets(t *testing.T) {
+	input := `[{"walletId": "3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901", "balance": 100}]`
+
+	wallets, err := readSeed(strings.NewReader(input), seedFormatJSON)
+
+	require.NoError(t, err)
+	assert.Equal(t, []seedWallet{
+		{WalletID: uuid.MustParse("3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901"), Balance: 100},
+	}, wallets)
+}
+
+func TestReadSeed_CSVInvalidBalance_ReturnsLine(t *testing.T) {
+	input := "walletId,balance\n3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901,abc\n"
+
+	_, err := readSeed(strings.NewReader(input), seedFormatCSV)
+
+	assert.ErrorContains(t, err, "line 2: balance")
+}
+
+func TestReadSeed_CSVMissingColumn_ReturnsError(t *testing.T) {
+	_, err := readSeed(strings.NewReader("walletId\n3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901\n"), seedFormatCSV)
+
+	assert.Error(t, err)
+}
+
+func TestReadSeed_UnknownFormat_ReturnsError(t *testing.T) {
+	_, err := readSeed(strings.NewReader(""), "xml")
+
+	assert.ErrorContains(t, err, "unsupported format")
+}
@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/checkpoint"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/handler"
+	"wallet-service/internal/health"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/maintenance"
+	"wallet-service/internal/metrics"
+	"wallet-service/internal/migrate"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/memory"
+	"wallet-service/internal/service"
+	"wallet-service/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+)
+
+func (a *app) serveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the HTTP API",
+		Long: "Serve the HTTP API. The schema is not migrated: run `migrate up` first,\n" +
+			"readiness stays failed until the database reaches the embedded version.",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{logToStdout: ""},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return a.serve(cmd.Context())
+		},
+	}
+}
+
+func (a *app) serve(ctx context.Context) error {
+	gin.SetMode(gin.ReleaseMode)
+	cfg, log := a.cfg, a.log
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("set up tracing: %w", err)
+	}
+
+	mode := maintenance.New(cfg.Maintenance.Enabled, cfg.Maintenance.RetryAfter)
+	healthChecker := health.New(cfg.Server.HealthCheckTimeout)
+
+	repositories, closeStorage, err := a.openStorage(ctx, mode, healthChecker)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	walletOpts, err := a.walletOptions(ctx, repositories)
+	if err != nil {
+		return err
+	}
+	services := service.NewService(repositories, log, walletOpts...)
+	var scheduler *service.ScheduleService
+	if repositories.Schedules != nil {
+		scheduler = service.NewScheduleService(repositories.Schedules, services.Wallet, repositories.Audit, cfg.Scheduler, log)
+		services.Schedule = scheduler
+	}
+	handlers := handler.NewHandler(services, healthChecker, log,
+		handler.WithRequestValidation(cfg.Server.ValidateRequests),
+		handler.WithRequestTimeout(cfg.Server.RequestTimeout),
+		handler.WithMaintenance(mode),
+		handler.WithAdminToken(cfg.Admin.Token),
+		handler.WithAuditorToken(cfg.Admin.AuditorToken),
+		handler.WithTrustedProxies(cfg.Server.TrustedProxies),
+	)
+
+	if cfg.Ledger.CheckpointInterval > 0 && services.Chain != nil {
+		key, err := checkpoint.LoadKey(cfg.Ledger.SigningKeyFile)
+		if err != nil {
+			return err
+		}
+		exportCtx, stopExport := context.WithCancel(ctx)
+		defer stopExport()
+		go checkpoint.NewExporter(services.Chain, key, cfg.Ledger.CheckpointDir, log).Run(exportCtx, cfg.Ledger.CheckpointInterval)
+	}
+
+	if cfg.Ledger.SnapshotInterval > 0 && repositories.Ledger != nil {
+		snapshotCtx, stopSnapshots := context.WithCancel(ctx)
+		defer stopSnapshots()
+		go service.NewHistoryService(repositories.Wallet, repositories.Ledger, log).RunSnapshots(snapshotCtx, cfg.Ledger.SnapshotInterval)
+	}
+
+	if cfg.Scheduler.PollInterval > 0 && scheduler != nil {
+		schedulerCtx, stopScheduler := context.WithCancel(ctx)
+		defer stopScheduler()
+		go scheduler.RunWorker(schedulerCtx, cfg.Scheduler.PollInterval, mode)
+	}
+
+	if cfg.Interest.Interval > 0 && services.Interest != nil {
+		interestCtx, stopInterest := context.WithCancel(ctx)
+		defer stopInterest()
+		go service.RunInterestWorker(interestCtx, services.Interest, cfg.Interest.Interval, mode, log)
+	}
+
+	if cfg.Bonus.ExpiryInterval > 0 && services.Bonus != nil {
+		bonusCtx, stopBonus := context.WithCancel(ctx)
+		defer stopBonus()
+		go service.RunBonusExpiryWorker(bonusCtx, services.Bonus, cfg.Bonus.ExpiryInterval, mode, log)
+	}
+
+	if cfg.Escrow.ExpiryInterval > 0 && services.Escrow != nil {
+		escrowCtx, stopEscrow := context.WithCancel(ctx)
+		defer stopEscrow()
+		go service.RunEscrowExpiryWorker(escrowCtx, services.Escrow, cfg.Escrow.ExpiryInterval, mode, log)
+	}
+
+	router, err := handlers.Router()
+	if err != nil {
+		return fmt.Errorf("build router: %w", err)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	toggle := make(chan os.Signal, 1)
+	signal.Notify(toggle, syscall.SIGUSR1)
+	defer signal.Stop(toggle)
+	go func() {
+		for range toggle {
+			enabled := mode.Toggle()
+			log.Info("maintenance mode changed", slog.Bool("enabled", enabled), slog.String("source", "signal"))
+			a.auditMaintenance(ctx, services, enabled)
+		}
+	}()
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.Server.Port),
+		Handler: router,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	log.Info("server is running", slog.String("port", cfg.Server.Port), slog.Bool("maintenance", mode.Enabled()))
+
+	select {
+	case err = <-serveErr:
+		return fmt.Errorf("listen on port %s: %w", cfg.Server.Port, err)
+	case <-stop:
+	}
+
+	healthChecker.StartDraining()
+	log.Info("shutting down server", slog.Duration("drain_delay", cfg.Server.DrainDelay))
+
+	time.Sleep(cfg.Server.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("server forced to shutdown: %w", err)
+	}
+	if err = <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		log.Warn("server stopped with error", logger.Err(err))
+	}
+
+	closeStorage()
+
+	if err = shutdownTracing(shutdownCtx); err != nil {
+		log.Warn("failed to flush traces", logger.Err(err))
+	}
+
+	log.Info("server gracefully stopped")
+	return nil
+}
+
+// auditMaintenance records a maintenance switch made by SIGUSR1.
+func (a *app) auditMaintenance(ctx context.Context, services *service.Service, enabled bool) {
+	if services.Audit == nil {
+		return
+	}
+
+	action := domain.AuditMaintenanceDisable
+	if enabled {
+		action = domain.AuditMaintenanceEnable
+	}
+	ctx = audit.WithActor(ctx, audit.Actor{ID: audit.Signal})
+	if err := services.Audit.Record(ctx, action, "success"); err != nil {
+		metrics.AuditWriteFailures.Inc()
+		a.log.ErrorContext(ctx, "failed to audit maintenance switch", logger.Err(err))
+	}
+}
+
+// openStorage builds the repositories for the configured driver and registers
+// their health checks. The returned func releases the connections.
+func (a *app) openStorage(ctx context.Context, mode *maintenance.Mode, healthChecker *health.Health) (*repository.Repository, func(), error) {
+	cfg, log := a.cfg, a.log
+
+	switch cfg.Database.Driver {
+	case config.DriverMemory:
+		opts := []memory.Option{memory.WithDefaultIsolation(cfg.Database.Isolation)}
+		if cfg.Database.Test {
+			opts = append(opts, memory.WithWallets(memory.TestWallets))
+		}
+		log.Warn("wallets are kept in memory and lost on exit", slog.String("driver", cfg.Database.Driver))
+		return memory.NewRepository(opts...), func() {}, nil
+	case config.DriverYDB:
+		y, err := repository.OpenYDB(ctx, cfg.Database)
+		if err != nil {
+			return nil, nil, err
+		}
+		healthChecker.Register("database", y.PingContext)
+		healthChecker.Register("schema", repository.YDBSchemaCheck(y.DB))
+		return repository.NewYDBRepository(y.DB, log), a.closeYDB(y), nil
+	}
+
+	schemaVersion, err := migrate.LatestVersion(a.migrations, cfg.Database.Test)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read embedded migrations: %w", err)
+	}
+
+	pool, err := repository.NewPool(ctx, cfg.Database)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open database pool: %w", err)
+	}
+	closers := []func(){pool.Close}
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	prometheus.MustRegister(metrics.NewPoolCollector(pool))
+
+	repoOpts := []repository.Option{repository.WithDefaultIsolation(cfg.Database.Isolation)}
+	if cfg.Database.ReplicaDSN != "" {
+		replicaPool, err := repository.NewReplicaPool(ctx, cfg.Database)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("open replica pool: %w", err)
+		}
+		closers = append(closers, replicaPool.Close)
+
+		var replicaOpts []repository.ReplicaOption
+		if cfg.Maintenance.ReadFromReplica {
+			replicaOpts = append(replicaOpts, repository.PreferWhen(mode.Enabled))
+		}
+		replica := repository.NewReplica(replicaPool, cfg.Database.ReplicaMaxLag, log, replicaOpts...)
+
+		monitorCtx, stopMonitor := context.WithCancel(ctx)
+		closers = append(closers, stopMonitor)
+		go replica.Monitor(monitorCtx, cfg.Database.ReplicaLagCheckInterval)
+
+		repoOpts = append(repoOpts, repository.WithReplica(replica))
+	}
+
+	repositories, err := repository.NewPostgresRepository(pool, log, repoOpts...)
+	if err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+
+	healthChecker.Register("database", health.PingCheck(pool))
+	healthChecker.Register("migrations", health.MigrationCheck(pool, schemaVersion))
+
+	return repositories, closeAll, nil
+}
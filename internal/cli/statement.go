@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	"wallet-service/internal/statement"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func (a *app) statementCommand() *cobra.Command {
+	var walletID, from, to, format, output string
+
+	cmd := &cobra.Command{
+		Use:   "statement",
+		Short: "Export wallet statements as CSV or JSON Lines",
+		Long: "Export the statement of one wallet with --wallet, or of every wallet one after\n" +
+			"another. The period is [--from, --to) in RFC 3339; without --from it starts\n" +
+			"at the first transaction, without --to it ends now.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var id *uuid.UUID
+			if walletID != "" {
+				parsed, err := uuid.Parse(walletID)
+				if err != nil {
+					return fmt.Errorf("invalid wallet id %q", walletID)
+				}
+				id = &parsed
+			}
+			if !slices.Contains(statement.Formats, format) {
+				return fmt.Errorf("invalid format %q, want one of %s", format, strings.Join(statement.Formats, ", "))
+			}
+			periodFrom, err := parseStatementTime("from", from, time.Time{})
+			if err != nil {
+				return err
+			}
+			periodTo, err := parseStatementTime("to", to, time.Now())
+			if err != nil {
+				return err
+			}
+
+			repositories, closeRepository, err := a.openRepository(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer closeRepository()
+
+			var w io.Writer = cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("create output: %w", err)
+				}
+				defer func() { _ = f.Close() }()
+				w = f
+			}
+			enc, err := statement.NewEncoder(w, format)
+			if err != nil {
+				return err
+			}
+
+			services := service.NewService(repositories, a.log)
+			emit := func(line *domain.StatementLine) error { return enc.Encode(line) }
+			if id != nil {
+				err = services.Statement.Statement(cmd.Context(), *id, periodFrom, periodTo, emit)
+			} else {
+				err = services.Statement.Statements(cmd.Context(), periodFrom, periodTo, emit)
+			}
+			if flushErr := enc.Flush(); err == nil {
+				err = flushErr
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&walletID, "wallet", "", "export only the wallet with this id")
+	cmd.Flags().StringVar(&from, "from", "", "start of the period, inclusive")
+	cmd.Flags().StringVar(&to, "to", "", "end of the period, exclusive")
+	cmd.Flags().StringVar(&format, "format", statement.FormatCSV, "csv or jsonl")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write to this file instead of stdout")
+
+	return cmd
+}
+
+func parseStatementTime(flag, s string, fallback time.Time) (time.Time, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q: want RFC 3339", flag, s)
+	}
+	return t, nil
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/user"
+	"strconv"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+type walletOutput struct {
+	WalletID string `json:"walletId"`
+	Balance  int64  `json:"balance"`
+	Fee      int64  `json:"fee,omitempty"`
+}
+
+func (a *app) walletCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wallet",
+		Short: "Inspect and adjust wallets without going through the API",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "get ID",
+			Short: "Print the wallet balance",
+			Args:  cobra.ExactArgs(1),
+			RunE: a.withWallet(func(ctx context.Context, s service.Wallet, id uuid.UUID, _ []string) (*domain.Receipt, error) {
+				wallet, err := s.Get(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				return &domain.Receipt{Wallet: wallet}, nil
+			}),
+		},
+		&cobra.Command{
+			Use:   "deposit ID AMOUNT",
+			Short: "Add AMOUNT to the wallet",
+			Args:  cobra.ExactArgs(2),
+			RunE: a.withWallet(func(ctx context.Context, s service.Wallet, id uuid.UUID, args []string) (*domain.Receipt, error) {
+				amount, err := parseAmount(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return s.Deposit(ctx, id, amount)
+			}),
+		},
+		&cobra.Command{
+			Use:   "withdraw ID AMOUNT",
+			Short: "Take AMOUNT from the wallet",
+			Args:  cobra.ExactArgs(2),
+			RunE: a.withWallet(func(ctx context.Context, s service.Wallet, id uuid.UUID, args []string) (*domain.Receipt, error) {
+				amount, err := parseAmount(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return s.Withdraw(ctx, id, amount)
+			}),
+		},
+	)
+
+	return cmd
+}
+
+func (a *app) withWallet(run func(ctx context.Context, s service.Wallet, id uuid.UUID, args []string) (*domain.Receipt, error)) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		id, err := uuid.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid wallet id %q", args[0])
+		}
+
+		ctx := audit.WithActor(cmd.Context(), audit.Actor{ID: cliActor()})
+		repositories, closeRepository, err := a.openRepository(ctx)
+		if err != nil {
+			return err
+		}
+		defer closeRepository()
+
+		opts, err := a.walletOptions(ctx, repositories)
+		if err != nil {
+			return err
+		}
+		services := service.NewService(repositories, a.log, opts...)
+		receipt, err := run(ctx, services.Wallet, id, args[1:])
+		if err != nil {
+			return err
+		}
+		wallet := receipt.Wallet
+		defer wallet.Release()
+
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(walletOutput{WalletID: wallet.ID().String(), Balance: wallet.Balance(), Fee: receipt.Fee})
+	}
+}
+
+// cliActor names the operating system user running the command in the audit
+// log.
+func cliActor() string {
+	u, err := user.Current()
+	if err != nil {
+		return audit.CLI
+	}
+	return audit.CLI + ":" + u.Username
+}
+
+func parseAmount(s string) (int64, error) {
+	amount, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return amount, nil
+}
@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var base = time.Date(2026, 10, 19, 12, 34, 56, 0, time.UTC) // понедельник
+
+func TestNext(t *testing.T) {
+	for expr, want := range map[string]time.Time{
+		"* * * * *":      time.Date(2026, 10, 19, 12, 35, 0, 0, time.UTC),
+		"*/15 * * * *":   time.Date(2026, 10, 19, 12, 45, 0, 0, time.UTC),
+		"0 9 * * *":      time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC),
+		"30 8 1 * *":     time.Date(2026, 11, 1, 8, 30, 0, 0, time.UTC),
+		"0 0 * * 5":      time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":      time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC),
+		"0 10-12 * * *":  time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC),
+		"0 0 1 1,7 *":    time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":     time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		"@monthly":       time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		"5/20 12 * * *":  time.Date(2026, 10, 19, 12, 45, 0, 0, time.UTC),
+		"0 0 13 * 5":     time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC),
+		"0 12 19 10 1-5": time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC),
+	} {
+		s, err := Parse(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, s.Next(base), expr)
+	}
+}
+
+func TestNext_OnTheMinute_Skipped(t *testing.T) {
+	s, err := Parse("0 * * * *")
+	require.NoError(t, err)
+
+	// Момент срабатывания не повторяется
+	at := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)
+	assert.Equal(t, at.Add(time.Hour), s.Next(at))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"0 0 30 2 *",
+	} {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
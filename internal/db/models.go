@@ -8,7 +8,119 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AppAuditLog struct {
+	ID            int64
+	At            pgtype.Timestamptz
+	Actor         string
+	SourceIp      string
+	RequestID     string
+	Action        string
+	WalletID      pgtype.UUID
+	BalanceBefore pgtype.Int8
+	BalanceAfter  pgtype.Int8
+	Outcome       string
+}
+
+type AppEscrow struct {
+	ID        pgtype.UUID
+	BuyerID   pgtype.UUID
+	SellerID  pgtype.UUID
+	Amount    int64
+	Status    string
+	ExpiresAt pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type AppEscrowTransition struct {
+	ID         int64
+	EscrowID   pgtype.UUID
+	Event      string
+	FromStatus string
+	ToStatus   string
+	Actor      string
+	At         pgtype.Timestamptz
+}
+
+type AppInterestAccount struct {
+	WalletID      pgtype.UUID
+	Rate          int64
+	Accrued       int64
+	NextAccrualAt pgtype.Timestamptz
+	NextPayoutAt  pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type AppInterestAccrual struct {
+	WalletID pgtype.UUID
+	Day      pgtype.Timestamptz
+	Balance  int64
+	Rate     int64
+	Amount   int64
+}
+
+type AppInterestPayout struct {
+	WalletID pgtype.UUID
+	Period   pgtype.Timestamptz
+	Amount   int64
+	PaidAt   pgtype.Timestamptz
+}
+
+type AppSchedule struct {
+	ID          pgtype.UUID
+	WalletID    pgtype.UUID
+	Operation   string
+	Amount      int64
+	Cron        string
+	Status      string
+	NextRunAt   pgtype.Timestamptz
+	Attempts    int32
+	MaxAttempts int32
+	Runs        int64
+	Failures    int64
+	LastRunAt   pgtype.Timestamptz
+	LastError   string
+	CreatedBy   string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	ClaimedAt   pgtype.Timestamptz
+	LeaseUntil  pgtype.Timestamptz
+}
+
 type AppWallet struct {
 	ID      pgtype.UUID
 	Balance int64
 }
+
+type AppWalletBalanceSnapshot struct {
+	WalletID pgtype.UUID
+	Seq      int64
+	Balance  int64
+	At       pgtype.Timestamptz
+}
+
+type AppWalletBonus struct {
+	ID        pgtype.UUID
+	WalletID  pgtype.UUID
+	Amount    int64
+	ExpiresAt pgtype.Timestamptz
+	GrantedAt pgtype.Timestamptz
+}
+
+type AppWalletTier struct {
+	WalletID  pgtype.UUID
+	Tier      string
+	UpdatedAt pgtype.Timestamptz
+}
+
+type AppWalletTransaction struct {
+	WalletID     pgtype.UUID
+	Seq          int64
+	Kind         string
+	Amount       int64
+	BalanceAfter int64
+	CreatedAt    pgtype.Timestamptz
+	PrevHash     []byte
+	Hash         []byte
+}
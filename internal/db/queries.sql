@@ -13,4 +13,261 @@ FOR UPDATE;
 UPDATE app.wallets
 SET balance = $2
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: Create :one
+INSERT INTO app.wallets (id, balance)
+VALUES ($1, $2)
+ON CONFLICT (id) DO NOTHING
+RETURNING *;
+
+-- name: AppendAudit :one
+INSERT INTO app.audit_log (actor, source_ip, request_id, action, wallet_id, balance_before, balance_after, outcome)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, at;
+
+-- name: ListAudit :many
+SELECT id, at, actor, source_ip, request_id, action, wallet_id, balance_before, balance_after, outcome
+FROM app.audit_log
+WHERE (sqlc.narg(wallet_id)::uuid IS NULL OR wallet_id = sqlc.narg(wallet_id))
+  AND (sqlc.narg(actor)::text IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(outcome)::text IS NULL OR outcome = sqlc.narg(outcome))
+  AND (sqlc.narg(from_at)::timestamptz IS NULL OR at >= sqlc.narg(from_at))
+  AND (sqlc.narg(to_at)::timestamptz IS NULL OR at < sqlc.narg(to_at))
+  AND (sqlc.narg(before_id)::bigint IS NULL OR id < sqlc.narg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: AppendTransaction :exec
+INSERT INTO app.wallet_transactions (wallet_id, seq, kind, amount, balance_after, created_at, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: LastTransaction :one
+SELECT *
+FROM app.wallet_transactions
+WHERE wallet_id = $1
+ORDER BY seq DESC
+LIMIT 1;
+
+-- name: LastTransactionBefore :one
+SELECT *
+FROM app.wallet_transactions
+WHERE wallet_id = $1 AND created_at < $2
+ORDER BY created_at DESC, seq DESC
+LIMIT 1;
+
+-- name: ListTransactions :many
+SELECT *
+FROM app.wallet_transactions
+WHERE wallet_id = $1 AND seq > $2
+ORDER BY seq
+LIMIT $3;
+
+-- name: SaveSnapshot :exec
+INSERT INTO app.wallet_balance_snapshots (wallet_id, seq, balance, at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (wallet_id, seq) DO NOTHING;
+
+-- name: LastSnapshotBefore :one
+SELECT *
+FROM app.wallet_balance_snapshots
+WHERE wallet_id = $1 AND at < $2
+ORDER BY at DESC, seq DESC
+LIMIT 1;
+
+-- name: ListWalletIDs :many
+SELECT id
+FROM app.wallets
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: CreateSchedule :one
+INSERT INTO app.schedules (id, wallet_id, operation, amount, cron, status, next_run_at, max_attempts, created_by, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetSchedule :one
+SELECT *
+FROM app.schedules
+WHERE id = $1;
+
+-- name: ListSchedules :many
+SELECT *
+FROM app.schedules
+WHERE wallet_id = sqlc.arg(wallet_id)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: CancelSchedule :one
+UPDATE app.schedules
+SET status = 'cancelled', updated_at = $2
+WHERE id = $1 AND status IN ('pending', 'retrying')
+RETURNING *;
+
+-- name: ClaimSchedules :many
+UPDATE app.schedules AS s
+SET status = 'running',
+    attempts = s.attempts + 1,
+    claimed_at = now(),
+    lease_until = now() + make_interval(secs => sqlc.arg(lease_seconds)::float8),
+    updated_at = now()
+FROM (
+    SELECT id, status, claimed_at
+    FROM app.schedules
+    WHERE (status IN ('pending', 'retrying') AND next_run_at <= now())
+       OR (status = 'running' AND lease_until < now())
+    ORDER BY next_run_at
+    LIMIT sqlc.arg(row_limit)
+    FOR UPDATE SKIP LOCKED
+) AS due
+WHERE s.id = due.id
+RETURNING s.id, s.wallet_id, s.operation, s.amount, s.cron, s.status, s.next_run_at, s.attempts, s.max_attempts, s.runs, s.failures, s.last_run_at, s.last_error, s.created_by, s.created_at, s.updated_at, s.claimed_at, s.lease_until, due.status AS previous_status, due.claimed_at AS previous_claimed_at;
+
+-- name: FinishSchedule :execrows
+UPDATE app.schedules
+SET status = $3,
+    next_run_at = $4,
+    attempts = $5,
+    runs = $6,
+    failures = $7,
+    last_run_at = $8,
+    last_error = $9,
+    updated_at = $10,
+    claimed_at = NULL,
+    lease_until = NULL
+WHERE id = $1 AND status = 'running' AND claimed_at = $2;
+
+-- name: GetWalletTier :one
+SELECT tier
+FROM app.wallet_tiers
+WHERE wallet_id = $1;
+
+-- name: SetWalletTier :exec
+INSERT INTO app.wallet_tiers (wallet_id, tier, updated_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (wallet_id) DO UPDATE
+SET tier = EXCLUDED.tier,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: SetInterestRate :one
+INSERT INTO app.interest_accounts (wallet_id, rate, next_accrual_at, next_payout_at, created_at, updated_at)
+VALUES (sqlc.arg(wallet_id), sqlc.arg(rate), sqlc.arg(next_accrual_at), sqlc.arg(next_payout_at), sqlc.arg(updated_at), sqlc.arg(updated_at))
+ON CONFLICT (wallet_id) DO UPDATE
+SET rate = EXCLUDED.rate,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: GetInterestAccount :one
+SELECT *
+FROM app.interest_accounts
+WHERE wallet_id = $1;
+
+-- name: ListInterestAccounts :many
+SELECT *
+FROM app.interest_accounts
+WHERE wallet_id > $1
+ORDER BY wallet_id
+LIMIT $2;
+
+-- name: AccrueInterest :execrows
+WITH advanced AS (
+    UPDATE app.interest_accounts
+    SET accrued = accrued + sqlc.arg(amount),
+        next_accrual_at = sqlc.arg(next_accrual_at),
+        updated_at = sqlc.arg(updated_at)
+    WHERE wallet_id = sqlc.arg(wallet_id) AND next_accrual_at = sqlc.arg(day)
+    RETURNING wallet_id
+)
+INSERT INTO app.interest_accruals (wallet_id, day, balance, rate, amount)
+SELECT wallet_id, sqlc.arg(day), sqlc.arg(balance), sqlc.arg(rate), sqlc.arg(amount)
+FROM advanced;
+
+-- name: SumInterestAccruals :one
+SELECT COALESCE(SUM(amount), 0)::bigint
+FROM app.interest_accruals
+WHERE wallet_id = sqlc.arg(wallet_id)
+  AND day >= sqlc.arg(from_day)
+  AND day < sqlc.arg(to_day);
+
+-- name: PayInterest :execrows
+WITH paid AS (
+    UPDATE app.interest_accounts
+    SET accrued = accrued - sqlc.arg(amount),
+        next_payout_at = sqlc.arg(next_payout_at),
+        updated_at = sqlc.arg(paid_at)
+    WHERE wallet_id = sqlc.arg(wallet_id)
+      AND next_payout_at = sqlc.arg(period)
+      AND next_accrual_at >= sqlc.arg(next_payout_at)
+    RETURNING wallet_id
+)
+INSERT INTO app.interest_payouts (wallet_id, period, amount, paid_at)
+SELECT wallet_id, sqlc.arg(period), sqlc.arg(amount), sqlc.arg(paid_at)
+FROM paid;
+
+-- name: ListWalletBonuses :many
+SELECT *
+FROM app.wallet_bonuses
+WHERE wallet_id = $1
+ORDER BY expires_at, id;
+
+-- name: SaveWalletBonuses :exec
+WITH kept AS (
+    INSERT INTO app.wallet_bonuses (id, wallet_id, amount, expires_at, granted_at)
+    SELECT b.id, sqlc.arg(wallet_id), b.amount, b.expires_at, b.granted_at
+    FROM unnest(sqlc.arg(ids)::uuid[], sqlc.arg(amounts)::bigint[], sqlc.arg(expires_at)::timestamptz[], sqlc.arg(granted_at)::timestamptz[])
+        AS b (id, amount, expires_at, granted_at)
+    ON CONFLICT (id) DO UPDATE
+    SET amount = EXCLUDED.amount
+    RETURNING id
+)
+DELETE FROM app.wallet_bonuses
+WHERE wallet_id = sqlc.arg(wallet_id)
+  AND id NOT IN (SELECT id FROM kept);
+
+-- name: ListExpiredBonusWallets :many
+SELECT DISTINCT wallet_id
+FROM app.wallet_bonuses
+WHERE expires_at <= $1 AND wallet_id > $2
+ORDER BY wallet_id
+LIMIT $3;
+
+-- name: CreateEscrow :exec
+INSERT INTO app.escrows (id, buyer_id, seller_id, amount, status, expires_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetEscrow :one
+SELECT *
+FROM app.escrows
+WHERE id = $1;
+
+-- name: GetEscrowForUpdate :one
+SELECT *
+FROM app.escrows
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateEscrowStatus :exec
+UPDATE app.escrows
+SET status = $2, updated_at = $3
+WHERE id = $1;
+
+-- name: AppendEscrowTransition :exec
+INSERT INTO app.escrow_transitions (escrow_id, event, from_status, to_status, actor, at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListEscrowTransitions :many
+SELECT *
+FROM app.escrow_transitions
+WHERE escrow_id = $1
+ORDER BY id;
+
+-- name: ListExpiredEscrows :many
+SELECT id
+FROM app.escrows
+WHERE status IN ('held', 'buyer_confirmed', 'seller_confirmed')
+  AND expires_at <= $1 AND id > $2
+ORDER BY id
+LIMIT $3;
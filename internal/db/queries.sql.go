@@ -11,6 +11,421 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const accrueInterest = `-- name: AccrueInterest :execrows
+WITH advanced AS (
+    UPDATE app.interest_accounts
+    SET accrued = accrued + $1,
+        next_accrual_at = $2,
+        updated_at = $3
+    WHERE wallet_id = $4 AND next_accrual_at = $5
+    RETURNING wallet_id
+)
+INSERT INTO app.interest_accruals (wallet_id, day, balance, rate, amount)
+SELECT wallet_id, $5, $6, $7, $1
+FROM advanced
+`
+
+type AccrueInterestParams struct {
+	Amount        int64
+	NextAccrualAt pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+	WalletID      pgtype.UUID
+	Day           pgtype.Timestamptz
+	Balance       int64
+	Rate          int64
+}
+
+func (q *Queries) AccrueInterest(ctx context.Context, arg AccrueInterestParams) (int64, error) {
+	result, err := q.db.Exec(ctx, accrueInterest,
+		arg.Amount,
+		arg.NextAccrualAt,
+		arg.UpdatedAt,
+		arg.WalletID,
+		arg.Day,
+		arg.Balance,
+		arg.Rate,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const appendAudit = `-- name: AppendAudit :one
+INSERT INTO app.audit_log (actor, source_ip, request_id, action, wallet_id, balance_before, balance_after, outcome)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, at
+`
+
+type AppendAuditParams struct {
+	Actor         string
+	SourceIp      string
+	RequestID     string
+	Action        string
+	WalletID      pgtype.UUID
+	BalanceBefore pgtype.Int8
+	BalanceAfter  pgtype.Int8
+	Outcome       string
+}
+
+type AppendAuditRow struct {
+	ID int64
+	At pgtype.Timestamptz
+}
+
+func (q *Queries) AppendAudit(ctx context.Context, arg AppendAuditParams) (AppendAuditRow, error) {
+	row := q.db.QueryRow(ctx, appendAudit,
+		arg.Actor,
+		arg.SourceIp,
+		arg.RequestID,
+		arg.Action,
+		arg.WalletID,
+		arg.BalanceBefore,
+		arg.BalanceAfter,
+		arg.Outcome,
+	)
+	var i AppendAuditRow
+	err := row.Scan(&i.ID, &i.At)
+	return i, err
+}
+
+const appendEscrowTransition = `-- name: AppendEscrowTransition :exec
+INSERT INTO app.escrow_transitions (escrow_id, event, from_status, to_status, actor, at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type AppendEscrowTransitionParams struct {
+	EscrowID   pgtype.UUID
+	Event      string
+	FromStatus string
+	ToStatus   string
+	Actor      string
+	At         pgtype.Timestamptz
+}
+
+func (q *Queries) AppendEscrowTransition(ctx context.Context, arg AppendEscrowTransitionParams) error {
+	_, err := q.db.Exec(ctx, appendEscrowTransition,
+		arg.EscrowID,
+		arg.Event,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.Actor,
+		arg.At,
+	)
+	return err
+}
+
+const appendTransaction = `-- name: AppendTransaction :exec
+INSERT INTO app.wallet_transactions (wallet_id, seq, kind, amount, balance_after, created_at, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type AppendTransactionParams struct {
+	WalletID     pgtype.UUID
+	Seq          int64
+	Kind         string
+	Amount       int64
+	BalanceAfter int64
+	CreatedAt    pgtype.Timestamptz
+	PrevHash     []byte
+	Hash         []byte
+}
+
+func (q *Queries) AppendTransaction(ctx context.Context, arg AppendTransactionParams) error {
+	_, err := q.db.Exec(ctx, appendTransaction,
+		arg.WalletID,
+		arg.Seq,
+		arg.Kind,
+		arg.Amount,
+		arg.BalanceAfter,
+		arg.CreatedAt,
+		arg.PrevHash,
+		arg.Hash,
+	)
+	return err
+}
+
+const cancelSchedule = `-- name: CancelSchedule :one
+UPDATE app.schedules
+SET status = 'cancelled', updated_at = $2
+WHERE id = $1 AND status IN ('pending', 'retrying')
+RETURNING id, wallet_id, operation, amount, cron, status, next_run_at, attempts, max_attempts, runs, failures, last_run_at, last_error, created_by, created_at, updated_at, claimed_at, lease_until
+`
+
+type CancelScheduleParams struct {
+	ID        pgtype.UUID
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CancelSchedule(ctx context.Context, arg CancelScheduleParams) (AppSchedule, error) {
+	row := q.db.QueryRow(ctx, cancelSchedule, arg.ID, arg.UpdatedAt)
+	var i AppSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Operation,
+		&i.Amount,
+		&i.Cron,
+		&i.Status,
+		&i.NextRunAt,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.Runs,
+		&i.Failures,
+		&i.LastRunAt,
+		&i.LastError,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClaimedAt,
+		&i.LeaseUntil,
+	)
+	return i, err
+}
+
+const claimSchedules = `-- name: ClaimSchedules :many
+UPDATE app.schedules AS s
+SET status = 'running',
+    attempts = s.attempts + 1,
+    claimed_at = now(),
+    lease_until = now() + make_interval(secs => $1::float8),
+    updated_at = now()
+FROM (
+    SELECT id, status, claimed_at
+    FROM app.schedules
+    WHERE (status IN ('pending', 'retrying') AND next_run_at <= now())
+       OR (status = 'running' AND lease_until < now())
+    ORDER BY next_run_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+) AS due
+WHERE s.id = due.id
+RETURNING s.id, s.wallet_id, s.operation, s.amount, s.cron, s.status, s.next_run_at, s.attempts, s.max_attempts, s.runs, s.failures, s.last_run_at, s.last_error, s.created_by, s.created_at, s.updated_at, s.claimed_at, s.lease_until, due.status AS previous_status, due.claimed_at AS previous_claimed_at
+`
+
+type ClaimSchedulesParams struct {
+	LeaseSeconds float64
+	RowLimit     int32
+}
+
+type ClaimSchedulesRow struct {
+	ID                pgtype.UUID
+	WalletID          pgtype.UUID
+	Operation         string
+	Amount            int64
+	Cron              string
+	Status            string
+	NextRunAt         pgtype.Timestamptz
+	Attempts          int32
+	MaxAttempts       int32
+	Runs              int64
+	Failures          int64
+	LastRunAt         pgtype.Timestamptz
+	LastError         string
+	CreatedBy         string
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+	ClaimedAt         pgtype.Timestamptz
+	LeaseUntil        pgtype.Timestamptz
+	PreviousStatus    string
+	PreviousClaimedAt pgtype.Timestamptz
+}
+
+func (q *Queries) ClaimSchedules(ctx context.Context, arg ClaimSchedulesParams) ([]ClaimSchedulesRow, error) {
+	rows, err := q.db.Query(ctx, claimSchedules, arg.LeaseSeconds, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimSchedulesRow
+	for rows.Next() {
+		var i ClaimSchedulesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.WalletID,
+			&i.Operation,
+			&i.Amount,
+			&i.Cron,
+			&i.Status,
+			&i.NextRunAt,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.Runs,
+			&i.Failures,
+			&i.LastRunAt,
+			&i.LastError,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClaimedAt,
+			&i.LeaseUntil,
+			&i.PreviousStatus,
+			&i.PreviousClaimedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const create = `-- name: Create :one
+INSERT INTO app.wallets (id, balance)
+VALUES ($1, $2)
+ON CONFLICT (id) DO NOTHING
+RETURNING id, balance
+`
+
+type CreateParams struct {
+	ID      pgtype.UUID
+	Balance int64
+}
+
+func (q *Queries) Create(ctx context.Context, arg CreateParams) (AppWallet, error) {
+	row := q.db.QueryRow(ctx, create, arg.ID, arg.Balance)
+	var i AppWallet
+	err := row.Scan(&i.ID, &i.Balance)
+	return i, err
+}
+
+const createEscrow = `-- name: CreateEscrow :exec
+INSERT INTO app.escrows (id, buyer_id, seller_id, amount, status, expires_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type CreateEscrowParams struct {
+	ID        pgtype.UUID
+	BuyerID   pgtype.UUID
+	SellerID  pgtype.UUID
+	Amount    int64
+	Status    string
+	ExpiresAt pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateEscrow(ctx context.Context, arg CreateEscrowParams) error {
+	_, err := q.db.Exec(ctx, createEscrow,
+		arg.ID,
+		arg.BuyerID,
+		arg.SellerID,
+		arg.Amount,
+		arg.Status,
+		arg.ExpiresAt,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const createSchedule = `-- name: CreateSchedule :one
+INSERT INTO app.schedules (id, wallet_id, operation, amount, cron, status, next_run_at, max_attempts, created_by, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, wallet_id, operation, amount, cron, status, next_run_at, attempts, max_attempts, runs, failures, last_run_at, last_error, created_by, created_at, updated_at, claimed_at, lease_until
+`
+
+type CreateScheduleParams struct {
+	ID          pgtype.UUID
+	WalletID    pgtype.UUID
+	Operation   string
+	Amount      int64
+	Cron        string
+	Status      string
+	NextRunAt   pgtype.Timestamptz
+	MaxAttempts int32
+	CreatedBy   string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams) (AppSchedule, error) {
+	row := q.db.QueryRow(ctx, createSchedule,
+		arg.ID,
+		arg.WalletID,
+		arg.Operation,
+		arg.Amount,
+		arg.Cron,
+		arg.Status,
+		arg.NextRunAt,
+		arg.MaxAttempts,
+		arg.CreatedBy,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i AppSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Operation,
+		&i.Amount,
+		&i.Cron,
+		&i.Status,
+		&i.NextRunAt,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.Runs,
+		&i.Failures,
+		&i.LastRunAt,
+		&i.LastError,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClaimedAt,
+		&i.LeaseUntil,
+	)
+	return i, err
+}
+
+const finishSchedule = `-- name: FinishSchedule :execrows
+UPDATE app.schedules
+SET status = $3,
+    next_run_at = $4,
+    attempts = $5,
+    runs = $6,
+    failures = $7,
+    last_run_at = $8,
+    last_error = $9,
+    updated_at = $10,
+    claimed_at = NULL,
+    lease_until = NULL
+WHERE id = $1 AND status = 'running' AND claimed_at = $2
+`
+
+type FinishScheduleParams struct {
+	ID        pgtype.UUID
+	ClaimedAt pgtype.Timestamptz
+	Status    string
+	NextRunAt pgtype.Timestamptz
+	Attempts  int32
+	Runs      int64
+	Failures  int64
+	LastRunAt pgtype.Timestamptz
+	LastError string
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) FinishSchedule(ctx context.Context, arg FinishScheduleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, finishSchedule,
+		arg.ID,
+		arg.ClaimedAt,
+		arg.Status,
+		arg.NextRunAt,
+		arg.Attempts,
+		arg.Runs,
+		arg.Failures,
+		arg.LastRunAt,
+		arg.LastError,
+		arg.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const get = `-- name: Get :one
 SELECT id, balance
 FROM app.wallets
@@ -24,6 +439,51 @@ func (q *Queries) Get(ctx context.Context, id pgtype.UUID) (AppWallet, error) {
 	return i, err
 }
 
+const getEscrow = `-- name: GetEscrow :one
+SELECT id, buyer_id, seller_id, amount, status, expires_at, created_at, updated_at
+FROM app.escrows
+WHERE id = $1
+`
+
+func (q *Queries) GetEscrow(ctx context.Context, id pgtype.UUID) (AppEscrow, error) {
+	row := q.db.QueryRow(ctx, getEscrow, id)
+	var i AppEscrow
+	err := row.Scan(
+		&i.ID,
+		&i.BuyerID,
+		&i.SellerID,
+		&i.Amount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEscrowForUpdate = `-- name: GetEscrowForUpdate :one
+SELECT id, buyer_id, seller_id, amount, status, expires_at, created_at, updated_at
+FROM app.escrows
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetEscrowForUpdate(ctx context.Context, id pgtype.UUID) (AppEscrow, error) {
+	row := q.db.QueryRow(ctx, getEscrowForUpdate, id)
+	var i AppEscrow
+	err := row.Scan(
+		&i.ID,
+		&i.BuyerID,
+		&i.SellerID,
+		&i.Amount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getForUpdate = `-- name: GetForUpdate :one
 SELECT id, balance
 FROM app.wallets
@@ -38,6 +498,696 @@ func (q *Queries) GetForUpdate(ctx context.Context, id pgtype.UUID) (AppWallet,
 	return i, err
 }
 
+const getInterestAccount = `-- name: GetInterestAccount :one
+SELECT wallet_id, rate, accrued, next_accrual_at, next_payout_at, created_at, updated_at
+FROM app.interest_accounts
+WHERE wallet_id = $1
+`
+
+func (q *Queries) GetInterestAccount(ctx context.Context, walletID pgtype.UUID) (AppInterestAccount, error) {
+	row := q.db.QueryRow(ctx, getInterestAccount, walletID)
+	var i AppInterestAccount
+	err := row.Scan(
+		&i.WalletID,
+		&i.Rate,
+		&i.Accrued,
+		&i.NextAccrualAt,
+		&i.NextPayoutAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSchedule = `-- name: GetSchedule :one
+SELECT id, wallet_id, operation, amount, cron, status, next_run_at, attempts, max_attempts, runs, failures, last_run_at, last_error, created_by, created_at, updated_at, claimed_at, lease_until
+FROM app.schedules
+WHERE id = $1
+`
+
+func (q *Queries) GetSchedule(ctx context.Context, id pgtype.UUID) (AppSchedule, error) {
+	row := q.db.QueryRow(ctx, getSchedule, id)
+	var i AppSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.WalletID,
+		&i.Operation,
+		&i.Amount,
+		&i.Cron,
+		&i.Status,
+		&i.NextRunAt,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.Runs,
+		&i.Failures,
+		&i.LastRunAt,
+		&i.LastError,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClaimedAt,
+		&i.LeaseUntil,
+	)
+	return i, err
+}
+
+const getWalletTier = `-- name: GetWalletTier :one
+SELECT tier
+FROM app.wallet_tiers
+WHERE wallet_id = $1
+`
+
+func (q *Queries) GetWalletTier(ctx context.Context, walletID pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getWalletTier, walletID)
+	var tier string
+	err := row.Scan(&tier)
+	return tier, err
+}
+
+const lastSnapshotBefore = `-- name: LastSnapshotBefore :one
+SELECT wallet_id, seq, balance, at
+FROM app.wallet_balance_snapshots
+WHERE wallet_id = $1 AND at < $2
+ORDER BY at DESC, seq DESC
+LIMIT 1
+`
+
+type LastSnapshotBeforeParams struct {
+	WalletID pgtype.UUID
+	At       pgtype.Timestamptz
+}
+
+func (q *Queries) LastSnapshotBefore(ctx context.Context, arg LastSnapshotBeforeParams) (AppWalletBalanceSnapshot, error) {
+	row := q.db.QueryRow(ctx, lastSnapshotBefore, arg.WalletID, arg.At)
+	var i AppWalletBalanceSnapshot
+	err := row.Scan(
+		&i.WalletID,
+		&i.Seq,
+		&i.Balance,
+		&i.At,
+	)
+	return i, err
+}
+
+const lastTransaction = `-- name: LastTransaction :one
+SELECT wallet_id, seq, kind, amount, balance_after, created_at, prev_hash, hash
+FROM app.wallet_transactions
+WHERE wallet_id = $1
+ORDER BY seq DESC
+LIMIT 1
+`
+
+func (q *Queries) LastTransaction(ctx context.Context, walletID pgtype.UUID) (AppWalletTransaction, error) {
+	row := q.db.QueryRow(ctx, lastTransaction, walletID)
+	var i AppWalletTransaction
+	err := row.Scan(
+		&i.WalletID,
+		&i.Seq,
+		&i.Kind,
+		&i.Amount,
+		&i.BalanceAfter,
+		&i.CreatedAt,
+		&i.PrevHash,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const lastTransactionBefore = `-- name: LastTransactionBefore :one
+SELECT wallet_id, seq, kind, amount, balance_after, created_at, prev_hash, hash
+FROM app.wallet_transactions
+WHERE wallet_id = $1 AND created_at < $2
+ORDER BY created_at DESC, seq DESC
+LIMIT 1
+`
+
+type LastTransactionBeforeParams struct {
+	WalletID  pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) LastTransactionBefore(ctx context.Context, arg LastTransactionBeforeParams) (AppWalletTransaction, error) {
+	row := q.db.QueryRow(ctx, lastTransactionBefore, arg.WalletID, arg.CreatedAt)
+	var i AppWalletTransaction
+	err := row.Scan(
+		&i.WalletID,
+		&i.Seq,
+		&i.Kind,
+		&i.Amount,
+		&i.BalanceAfter,
+		&i.CreatedAt,
+		&i.PrevHash,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const listAudit = `-- name: ListAudit :many
+SELECT id, at, actor, source_ip, request_id, action, wallet_id, balance_before, balance_after, outcome
+FROM app.audit_log
+WHERE ($1::uuid IS NULL OR wallet_id = $1)
+  AND ($2::text IS NULL OR actor = $2)
+  AND ($3::text IS NULL OR action = $3)
+  AND ($4::text IS NULL OR outcome = $4)
+  AND ($5::timestamptz IS NULL OR at >= $5)
+  AND ($6::timestamptz IS NULL OR at < $6)
+  AND ($7::bigint IS NULL OR id < $7)
+ORDER BY id DESC
+LIMIT $8
+`
+
+type ListAuditParams struct {
+	WalletID pgtype.UUID
+	Actor    pgtype.Text
+	Action   pgtype.Text
+	Outcome  pgtype.Text
+	FromAt   pgtype.Timestamptz
+	ToAt     pgtype.Timestamptz
+	BeforeID pgtype.Int8
+	RowLimit int32
+}
+
+func (q *Queries) ListAudit(ctx context.Context, arg ListAuditParams) ([]AppAuditLog, error) {
+	rows, err := q.db.Query(ctx, listAudit,
+		arg.WalletID,
+		arg.Actor,
+		arg.Action,
+		arg.Outcome,
+		arg.FromAt,
+		arg.ToAt,
+		arg.BeforeID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AppAuditLog
+	for rows.Next() {
+		var i AppAuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.At,
+			&i.Actor,
+			&i.SourceIp,
+			&i.RequestID,
+			&i.Action,
+			&i.WalletID,
+			&i.BalanceBefore,
+			&i.BalanceAfter,
+			&i.Outcome,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEscrowTransitions = `-- name: ListEscrowTransitions :many
+SELECT id, escrow_id, event, from_status, to_status, actor, at
+FROM app.escrow_transitions
+WHERE escrow_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListEscrowTransitions(ctx context.Context, escrowID pgtype.UUID) ([]AppEscrowTransition, error) {
+	rows, err := q.db.Query(ctx, listEscrowTransitions, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AppEscrowTransition
+	for rows.Next() {
+		var i AppEscrowTransition
+		if err := rows.Scan(
+			&i.ID,
+			&i.EscrowID,
+			&i.Event,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.Actor,
+			&i.At,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredBonusWallets = `-- name: ListExpiredBonusWallets :many
+SELECT DISTINCT wallet_id
+FROM app.wallet_bonuses
+WHERE expires_at <= $1 AND wallet_id > $2
+ORDER BY wallet_id
+LIMIT $3
+`
+
+type ListExpiredBonusWalletsParams struct {
+	ExpiresAt pgtype.Timestamptz
+	WalletID  pgtype.UUID
+	Limit     int32
+}
+
+func (q *Queries) ListExpiredBonusWallets(ctx context.Context, arg ListExpiredBonusWalletsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listExpiredBonusWallets, arg.ExpiresAt, arg.WalletID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var wallet_id pgtype.UUID
+		if err := rows.Scan(&wallet_id); err != nil {
+			return nil, err
+		}
+		items = append(items, wallet_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredEscrows = `-- name: ListExpiredEscrows :many
+SELECT id
+FROM app.escrows
+WHERE status IN ('held', 'buyer_confirmed', 'seller_confirmed')
+  AND expires_at <= $1 AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListExpiredEscrowsParams struct {
+	ExpiresAt pgtype.Timestamptz
+	ID        pgtype.UUID
+	Limit     int32
+}
+
+func (q *Queries) ListExpiredEscrows(ctx context.Context, arg ListExpiredEscrowsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listExpiredEscrows, arg.ExpiresAt, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestAccounts = `-- name: ListInterestAccounts :many
+SELECT wallet_id, rate, accrued, next_accrual_at, next_payout_at, created_at, updated_at
+FROM app.interest_accounts
+WHERE wallet_id > $1
+ORDER BY wallet_id
+LIMIT $2
+`
+
+type ListInterestAccountsParams struct {
+	WalletID pgtype.UUID
+	Limit    int32
+}
+
+func (q *Queries) ListInterestAccounts(ctx context.Context, arg ListInterestAccountsParams) ([]AppInterestAccount, error) {
+	rows, err := q.db.Query(ctx, listInterestAccounts, arg.WalletID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AppInterestAccount
+	for rows.Next() {
+		var i AppInterestAccount
+		if err := rows.Scan(
+			&i.WalletID,
+			&i.Rate,
+			&i.Accrued,
+			&i.NextAccrualAt,
+			&i.NextPayoutAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSchedules = `-- name: ListSchedules :many
+SELECT id, wallet_id, operation, amount, cron, status, next_run_at, attempts, max_attempts, runs, failures, last_run_at, last_error, created_by, created_at, updated_at, claimed_at, lease_until
+FROM app.schedules
+WHERE wallet_id = $1
+  AND ($2::text IS NULL OR status = $2)
+ORDER BY created_at DESC, id
+LIMIT $3
+`
+
+type ListSchedulesParams struct {
+	WalletID pgtype.UUID
+	Status   pgtype.Text
+	RowLimit int32
+}
+
+func (q *Queries) ListSchedules(ctx context.Context, arg ListSchedulesParams) ([]AppSchedule, error) {
+	rows, err := q.db.Query(ctx, listSchedules, arg.WalletID, arg.Status, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AppSchedule
+	for rows.Next() {
+		var i AppSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.WalletID,
+			&i.Operation,
+			&i.Amount,
+			&i.Cron,
+			&i.Status,
+			&i.NextRunAt,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.Runs,
+			&i.Failures,
+			&i.LastRunAt,
+			&i.LastError,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClaimedAt,
+			&i.LeaseUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactions = `-- name: ListTransactions :many
+SELECT wallet_id, seq, kind, amount, balance_after, created_at, prev_hash, hash
+FROM app.wallet_transactions
+WHERE wallet_id = $1 AND seq > $2
+ORDER BY seq
+LIMIT $3
+`
+
+type ListTransactionsParams struct {
+	WalletID pgtype.UUID
+	Seq      int64
+	Limit    int32
+}
+
+func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]AppWalletTransaction, error) {
+	rows, err := q.db.Query(ctx, listTransactions, arg.WalletID, arg.Seq, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AppWalletTransaction
+	for rows.Next() {
+		var i AppWalletTransaction
+		if err := rows.Scan(
+			&i.WalletID,
+			&i.Seq,
+			&i.Kind,
+			&i.Amount,
+			&i.BalanceAfter,
+			&i.CreatedAt,
+			&i.PrevHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWalletBonuses = `-- name: ListWalletBonuses :many
+SELECT id, wallet_id, amount, expires_at, granted_at
+FROM app.wallet_bonuses
+WHERE wallet_id = $1
+ORDER BY expires_at, id
+`
+
+func (q *Queries) ListWalletBonuses(ctx context.Context, walletID pgtype.UUID) ([]AppWalletBonus, error) {
+	rows, err := q.db.Query(ctx, listWalletBonuses, walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AppWalletBonus
+	for rows.Next() {
+		var i AppWalletBonus
+		if err := rows.Scan(
+			&i.ID,
+			&i.WalletID,
+			&i.Amount,
+			&i.ExpiresAt,
+			&i.GrantedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWalletIDs = `-- name: ListWalletIDs :many
+SELECT id
+FROM app.wallets
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListWalletIDsParams struct {
+	ID    pgtype.UUID
+	Limit int32
+}
+
+func (q *Queries) ListWalletIDs(ctx context.Context, arg ListWalletIDsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listWalletIDs, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const payInterest = `-- name: PayInterest :execrows
+WITH paid AS (
+    UPDATE app.interest_accounts
+    SET accrued = accrued - $1,
+        next_payout_at = $2,
+        updated_at = $3
+    WHERE wallet_id = $4
+      AND next_payout_at = $5
+      AND next_accrual_at >= $2
+    RETURNING wallet_id
+)
+INSERT INTO app.interest_payouts (wallet_id, period, amount, paid_at)
+SELECT wallet_id, $5, $1, $3
+FROM paid
+`
+
+type PayInterestParams struct {
+	Amount       int64
+	NextPayoutAt pgtype.Timestamptz
+	PaidAt       pgtype.Timestamptz
+	WalletID     pgtype.UUID
+	Period       pgtype.Timestamptz
+}
+
+func (q *Queries) PayInterest(ctx context.Context, arg PayInterestParams) (int64, error) {
+	result, err := q.db.Exec(ctx, payInterest,
+		arg.Amount,
+		arg.NextPayoutAt,
+		arg.PaidAt,
+		arg.WalletID,
+		arg.Period,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const saveSnapshot = `-- name: SaveSnapshot :exec
+INSERT INTO app.wallet_balance_snapshots (wallet_id, seq, balance, at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (wallet_id, seq) DO NOTHING
+`
+
+type SaveSnapshotParams struct {
+	WalletID pgtype.UUID
+	Seq      int64
+	Balance  int64
+	At       pgtype.Timestamptz
+}
+
+func (q *Queries) SaveSnapshot(ctx context.Context, arg SaveSnapshotParams) error {
+	_, err := q.db.Exec(ctx, saveSnapshot,
+		arg.WalletID,
+		arg.Seq,
+		arg.Balance,
+		arg.At,
+	)
+	return err
+}
+
+const saveWalletBonuses = `-- name: SaveWalletBonuses :exec
+WITH kept AS (
+    INSERT INTO app.wallet_bonuses (id, wallet_id, amount, expires_at, granted_at)
+    SELECT b.id, $1, b.amount, b.expires_at, b.granted_at
+    FROM unnest($2::uuid[], $3::bigint[], $4::timestamptz[], $5::timestamptz[])
+        AS b (id, amount, expires_at, granted_at)
+    ON CONFLICT (id) DO UPDATE
+    SET amount = EXCLUDED.amount
+    RETURNING id
+)
+DELETE FROM app.wallet_bonuses
+WHERE wallet_id = $1
+  AND id NOT IN (SELECT id FROM kept)
+`
+
+type SaveWalletBonusesParams struct {
+	WalletID  pgtype.UUID
+	Ids       []pgtype.UUID
+	Amounts   []int64
+	ExpiresAt []pgtype.Timestamptz
+	GrantedAt []pgtype.Timestamptz
+}
+
+func (q *Queries) SaveWalletBonuses(ctx context.Context, arg SaveWalletBonusesParams) error {
+	_, err := q.db.Exec(ctx, saveWalletBonuses,
+		arg.WalletID,
+		arg.Ids,
+		arg.Amounts,
+		arg.ExpiresAt,
+		arg.GrantedAt,
+	)
+	return err
+}
+
+const setInterestRate = `-- name: SetInterestRate :one
+INSERT INTO app.interest_accounts (wallet_id, rate, next_accrual_at, next_payout_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $5)
+ON CONFLICT (wallet_id) DO UPDATE
+SET rate = EXCLUDED.rate,
+    updated_at = EXCLUDED.updated_at
+RETURNING wallet_id, rate, accrued, next_accrual_at, next_payout_at, created_at, updated_at
+`
+
+type SetInterestRateParams struct {
+	WalletID      pgtype.UUID
+	Rate          int64
+	NextAccrualAt pgtype.Timestamptz
+	NextPayoutAt  pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+func (q *Queries) SetInterestRate(ctx context.Context, arg SetInterestRateParams) (AppInterestAccount, error) {
+	row := q.db.QueryRow(ctx, setInterestRate,
+		arg.WalletID,
+		arg.Rate,
+		arg.NextAccrualAt,
+		arg.NextPayoutAt,
+		arg.UpdatedAt,
+	)
+	var i AppInterestAccount
+	err := row.Scan(
+		&i.WalletID,
+		&i.Rate,
+		&i.Accrued,
+		&i.NextAccrualAt,
+		&i.NextPayoutAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setWalletTier = `-- name: SetWalletTier :exec
+INSERT INTO app.wallet_tiers (wallet_id, tier, updated_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (wallet_id) DO UPDATE
+SET tier = EXCLUDED.tier,
+    updated_at = EXCLUDED.updated_at
+`
+
+type SetWalletTierParams struct {
+	WalletID  pgtype.UUID
+	Tier      string
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) SetWalletTier(ctx context.Context, arg SetWalletTierParams) error {
+	_, err := q.db.Exec(ctx, setWalletTier, arg.WalletID, arg.Tier, arg.UpdatedAt)
+	return err
+}
+
+const sumInterestAccruals = `-- name: SumInterestAccruals :one
+SELECT COALESCE(SUM(amount), 0)::bigint
+FROM app.interest_accruals
+WHERE wallet_id = $1
+  AND day >= $2
+  AND day < $3
+`
+
+type SumInterestAccrualsParams struct {
+	WalletID pgtype.UUID
+	FromDay  pgtype.Timestamptz
+	ToDay    pgtype.Timestamptz
+}
+
+func (q *Queries) SumInterestAccruals(ctx context.Context, arg SumInterestAccrualsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, sumInterestAccruals, arg.WalletID, arg.FromDay, arg.ToDay)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const update = `-- name: Update :one
 UPDATE app.wallets
 SET balance = $2
@@ -56,3 +1206,20 @@ func (q *Queries) Update(ctx context.Context, arg UpdateParams) (AppWallet, erro
 	err := row.Scan(&i.ID, &i.Balance)
 	return i, err
 }
+
+const updateEscrowStatus = `-- name: UpdateEscrowStatus :exec
+UPDATE app.escrows
+SET status = $2, updated_at = $3
+WHERE id = $1
+`
+
+type UpdateEscrowStatusParams struct {
+	ID        pgtype.UUID
+	Status    string
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) UpdateEscrowStatus(ctx context.Context, arg UpdateEscrowStatusParams) error {
+	_, err := q.db.Exec(ctx, updateEscrowStatus, arg.ID, arg.Status, arg.UpdatedAt)
+	return err
+}
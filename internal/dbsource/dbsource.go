@@ -0,0 +1,70 @@
+package dbsource
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+const (
+	Primary = "primary"
+	Replica = "replica"
+)
+
+// Consistency is what a caller requires from a read. Eventual reads may be
+// served by a replica that is within the lag budget; strong reads always go
+// to the primary.
+type Consistency string
+
+const (
+	Eventual Consistency = "eventual"
+	Strong   Consistency = "strong"
+)
+
+func ParseConsistency(s string) (Consistency, error) {
+	switch c := Consistency(s); c {
+	case "":
+		return Eventual, nil
+	case Eventual, Strong:
+		return c, nil
+	default:
+		return "", fmt.Errorf("consistency must be %s or %s, got %q", Eventual, Strong, s)
+	}
+}
+
+type consistencyKey struct{}
+
+type servedKey struct{}
+
+func WithConsistency(ctx context.Context, c Consistency) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, c)
+}
+
+func ConsistencyFrom(ctx context.Context) Consistency {
+	if c, ok := ctx.Value(consistencyKey{}).(Consistency); ok {
+		return c
+	}
+	return Eventual
+}
+
+// Track returns a context in which Record remembers the source of the last
+// read, so the caller can report it after the call returns.
+func Track(ctx context.Context) context.Context {
+	return context.WithValue(ctx, servedKey{}, new(atomic.Value))
+}
+
+func Record(ctx context.Context, source string) {
+	if served, ok := ctx.Value(servedKey{}).(*atomic.Value); ok {
+		served.Store(source)
+	}
+}
+
+// Served returns the source recorded under a Track context, or "".
+func Served(ctx context.Context) string {
+	if served, ok := ctx.Value(servedKey{}).(*atomic.Value); ok {
+		if source, ok := served.Load().(string); ok {
+			return source
+		}
+	}
+	return ""
+}
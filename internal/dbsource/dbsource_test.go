@@ -0,0 +1,37 @@
+package dbsource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConsistency_Values(t *testing.T) {
+	c, err := ParseConsistency("")
+	assert.NoError(t, err)
+	assert.Equal(t, Eventual, c)
+
+	c, err = ParseConsistency("strong")
+	assert.NoError(t, err)
+	assert.Equal(t, Strong, c)
+
+	_, err = ParseConsistency("linearizable")
+	assert.Error(t, err)
+}
+
+func TestRecord_TrackedContext_ReturnsSource(t *testing.T) {
+	ctx := Track(context.Background())
+
+	Record(ctx, Replica)
+
+	assert.Equal(t, Replica, Served(ctx))
+}
+
+func TestRecord_UntrackedContext_Ignored(t *testing.T) {
+	ctx := context.Background()
+
+	Record(ctx, Primary)
+
+	assert.Empty(t, Served(ctx))
+}
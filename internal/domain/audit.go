@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audited actions.
+const (
+	AuditDeposit            = "wallet.deposit"
+	AuditWithdraw           = "wallet.withdraw"
+	AuditFee                = "wallet.fee"
+	AuditInterest           = "wallet.interest"
+	AuditBonus              = "wallet.bonus"
+	AuditBonusExpire        = "wallet.bonus.expire"
+	AuditEscrowHold         = "escrow.hold"
+	AuditEscrowRelease      = "escrow.release"
+	AuditEscrowRefund       = "escrow.refund"
+	AuditSplit              = "wallet.split"
+	AuditSplitCredit        = "wallet.split.credit"
+	AuditMaintenanceEnable  = "admin.maintenance.enable"
+	AuditMaintenanceDisable = "admin.maintenance.disable"
+	AuditTierSet            = "admin.tier.set"
+	AuditInterestRateSet    = "admin.interest.set"
+)
+
+// AuditRecord is an entry of the append-only audit log: who did what, from
+// where and how it ended. Wallet fields are nil for actions that do not touch
+// a wallet, and the balances also when the wallet could not be read.
+type AuditRecord struct {
+	ID        int64
+	At        time.Time
+	Actor     string
+	SourceIP  string
+	RequestID string
+	Action    string
+	Outcome   string
+
+	WalletID      *uuid.UUID
+	BalanceBefore *int64
+	BalanceAfter  *int64
+}
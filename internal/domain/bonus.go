@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Spending orders of a withdrawal from a wallet with bonus.
+const (
+	// SpendBonusFirst spends bonus before cash; it is the default.
+	SpendBonusFirst = "bonus_first"
+	// SpendCashFirst spends bonus only once cash runs out.
+	SpendCashFirst = "cash_first"
+)
+
+var (
+	ErrBonusExpiry = errors.New("bonus must expire in the future")
+	// ErrBonusExceedsBalance means the stored buckets of a wallet add up to
+	// more than its balance.
+	ErrBonusExceedsBalance = errors.New("bonus exceeds wallet balance")
+)
+
+// Bonus is a bucket of promotional credit. It is spent like cash, in the
+// spending order of the wallet, and what is left of it is removed when it
+// expires.
+type Bonus struct {
+	ID        uuid.UUID
+	Amount    int64
+	ExpiresAt time.Time
+	GrantedAt time.Time
+}
+
+// SetBonuses loads the bonus buckets of the wallet, replacing any it had.
+// The rest of the balance is cash.
+func (w *Wallet) SetBonuses(bonuses []Bonus) error {
+	var total int64
+	for _, b := range bonuses {
+		if b.Amount <= 0 {
+			return fmt.Errorf("bonus %s: %w", b.ID, ErrNegativeAmount)
+		}
+		total += b.Amount
+		if total > w.balance || total < 0 {
+			return fmt.Errorf("wallet %s: %w", w.id, ErrBonusExceedsBalance)
+		}
+	}
+
+	w.bonuses = append(w.bonuses[:0], bonuses...)
+	sortBonuses(w.bonuses)
+	w.bonusesChanged = false
+	return nil
+}
+
+// Bonuses returns the unspent bonus buckets, soonest to expire first. The
+// slice must not be modified.
+func (w *Wallet) Bonuses() []Bonus {
+	return w.bonuses
+}
+
+// BonusesChanged reports whether the buckets changed since they were
+// loaded.
+func (w *Wallet) BonusesChanged() bool {
+	return w.bonusesChanged
+}
+
+// Bonus is the part of the balance held in bonus buckets.
+func (w *Wallet) Bonus() int64 {
+	var total int64
+	for _, b := range w.bonuses {
+		total += b.Amount
+	}
+	return total
+}
+
+// Cash is the part of the balance that is not bonus.
+func (w *Wallet) Cash() int64 {
+	return w.balance - w.Bonus()
+}
+
+// SetSpendingOrder sets the order Withdraw spends cash and bonus in; an
+// empty order is SpendBonusFirst.
+func (w *Wallet) SetSpendingOrder(order string) {
+	w.order = order
+}
+
+// GrantBonus adds a bucket of bonus to the balance.
+func (w *Wallet) GrantBonus(bonus Bonus) error {
+	if !bonus.ExpiresAt.After(bonus.GrantedAt) {
+		return ErrBonusExpiry
+	}
+	if err := w.Deposit(bonus.Amount); err != nil {
+		return err
+	}
+
+	w.bonuses = append(w.bonuses, bonus)
+	sortBonuses(w.bonuses)
+	w.bonusesChanged = true
+	return nil
+}
+
+// Expire removes what is left of the buckets expired by now and returns
+// the amount removed.
+func (w *Wallet) Expire(now time.Time) int64 {
+	var expired int64
+	w.bonuses = slices.DeleteFunc(w.bonuses, func(b Bonus) bool {
+		if b.ExpiresAt.After(now) {
+			return false
+		}
+		expired += b.Amount
+		return true
+	})
+	if expired > 0 {
+		w.balance -= expired
+		w.bonusesChanged = true
+	}
+	return expired
+}
+
+// spend takes the bonus part of a withdrawal of amount from the buckets,
+// soonest to expire first. The caller has checked the balance covers it.
+func (w *Wallet) spend(amount int64) {
+	if len(w.bonuses) == 0 {
+		return
+	}
+
+	fromBonus := amount
+	if w.order == SpendCashFirst {
+		fromBonus = max(amount-w.Cash(), 0)
+	}
+	if fromBonus == 0 {
+		return
+	}
+
+	for i := range w.bonuses {
+		take := min(fromBonus, w.bonuses[i].Amount)
+		w.bonuses[i].Amount -= take
+		if fromBonus -= take; fromBonus == 0 {
+			break
+		}
+	}
+	w.bonuses = slices.DeleteFunc(w.bonuses, func(b Bonus) bool { return b.Amount == 0 })
+	w.bonusesChanged = true
+}
+
+func sortBonuses(bonuses []Bonus) {
+	slices.SortFunc(bonuses, func(a, b Bonus) int {
+		if c := a.ExpiresAt.Compare(b.ExpiresAt); c != 0 {
+			return c
+		}
+		return slices.Compare(a.ID[:], b.ID[:])
+	})
+}
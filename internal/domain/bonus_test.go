@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var bonusNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+// newBonusWallet — 100 наличными и бонусы 30 (до завтра) и 20 (до послезавтра)
+func newBonusWallet(t *testing.T, order string) *Wallet {
+	t.Helper()
+	w, err := NewWallet(uuid.New(), 150)
+	require.NoError(t, err)
+	require.NoError(t, w.SetBonuses([]Bonus{
+		{ID: uuid.New(), Amount: 20, ExpiresAt: bonusNow.Add(48 * time.Hour)},
+		{ID: uuid.New(), Amount: 30, ExpiresAt: bonusNow.Add(24 * time.Hour)},
+	}))
+	w.SetSpendingOrder(order)
+	return w
+}
+
+func TestWithdraw_BonusFirst_SoonestExpiryFirst(t *testing.T) {
+	w := newBonusWallet(t, SpendBonusFirst)
+
+	require.NoError(t, w.Withdraw(40))
+
+	assert.Equal(t, int64(110), w.Balance())
+	assert.Equal(t, int64(100), w.Cash())
+	require.Len(t, w.Bonuses(), 1)
+	assert.Equal(t, int64(10), w.Bonuses()[0].Amount)
+	assert.True(t, w.BonusesChanged())
+}
+
+func TestWithdraw_CashFirst_BonusOnceCashRunsOut(t *testing.T) {
+	w := newBonusWallet(t, SpendCashFirst)
+
+	require.NoError(t, w.Withdraw(90))
+	assert.Equal(t, int64(10), w.Cash())
+	assert.Equal(t, int64(50), w.Bonus())
+	assert.False(t, w.BonusesChanged())
+
+	require.NoError(t, w.Withdraw(25))
+	assert.Zero(t, w.Cash())
+	assert.Equal(t, int64(35), w.Bonus())
+}
+
+func TestExpire_RemovesUnspentExpired(t *testing.T) {
+	w := newBonusWallet(t, SpendBonusFirst)
+	require.NoError(t, w.Withdraw(10))
+
+	expired := w.Expire(bonusNow.Add(24 * time.Hour))
+
+	assert.Equal(t, int64(20), expired)
+	assert.Equal(t, int64(120), w.Balance())
+	assert.Equal(t, int64(20), w.Bonus())
+	assert.Zero(t, w.Expire(bonusNow.Add(24*time.Hour)))
+}
+
+func TestGrantBonus(t *testing.T) {
+	w, err := NewWallet(uuid.New(), 0)
+	require.NoError(t, err)
+
+	err = w.GrantBonus(Bonus{ID: uuid.New(), Amount: 10, ExpiresAt: bonusNow, GrantedAt: bonusNow})
+	assert.ErrorIs(t, err, ErrBonusExpiry)
+	err = w.GrantBonus(Bonus{ID: uuid.New(), Amount: 0, ExpiresAt: bonusNow.Add(time.Hour), GrantedAt: bonusNow})
+	assert.ErrorIs(t, err, ErrZeroAmount)
+
+	require.NoError(t, w.GrantBonus(Bonus{ID: uuid.New(), Amount: 10, ExpiresAt: bonusNow.Add(time.Hour), GrantedAt: bonusNow}))
+	assert.Equal(t, int64(10), w.Balance())
+	assert.Zero(t, w.Cash())
+}
+
+func TestSetBonuses_AboveBalance_ReturnsError(t *testing.T) {
+	w, err := NewWallet(uuid.New(), 10)
+	require.NoError(t, err)
+
+	err = w.SetBonuses([]Bonus{{ID: uuid.New(), Amount: 11, ExpiresAt: bonusNow}})
+	assert.ErrorIs(t, err, ErrBonusExceedsBalance)
+}
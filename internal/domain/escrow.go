@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Escrow statuses. The funds are held until both sides confirm, in either
+// order, and are then released to the seller. A held escrow that is
+// cancelled or runs past its deadline is refunded to the buyer. Released,
+// cancelled and expired are final.
+const (
+	EscrowHeld            = "held"
+	EscrowBuyerConfirmed  = "buyer_confirmed"
+	EscrowSellerConfirmed = "seller_confirmed"
+	EscrowReleased        = "released"
+	EscrowCancelled       = "cancelled"
+	EscrowExpired         = "expired"
+)
+
+// Events that move an escrow from one status to another. EscrowCreate only
+// starts one.
+const (
+	EscrowCreate        = "create"
+	EscrowConfirmBuyer  = "confirm_buyer"
+	EscrowConfirmSeller = "confirm_seller"
+	EscrowCancel        = "cancel"
+	EscrowExpire        = "expire"
+)
+
+// Parties to an escrow.
+const (
+	EscrowBuyer  = "buyer"
+	EscrowSeller = "seller"
+)
+
+var (
+	ErrEscrowNotFound = errors.New("escrow not found")
+	// ErrEscrowTransition is returned for an event the status of the escrow
+	// does not allow.
+	ErrEscrowTransition = errors.New("escrow transition not allowed")
+	// ErrEscrowExpired is returned for a confirmation or cancellation after
+	// the deadline; the escrow is refunded by the expiry job instead.
+	ErrEscrowExpired  = errors.New("escrow has expired")
+	ErrEscrowParties  = errors.New("buyer and seller must be different wallets")
+	ErrEscrowDeadline = errors.New("escrow must expire in the future")
+	ErrEscrowParty    = errors.New("party must be buyer or seller")
+	// ErrEscrowSameActor is returned when the actor of the first
+	// confirmation confirms the other side too.
+	ErrEscrowSameActor = fmt.Errorf("%w: both sides confirmed by one actor", ErrEscrowTransition)
+)
+
+// escrowTransitions maps a status and an event to the next status.
+var escrowTransitions = map[string]map[string]string{
+	EscrowHeld: {
+		EscrowConfirmBuyer:  EscrowBuyerConfirmed,
+		EscrowConfirmSeller: EscrowSellerConfirmed,
+		EscrowCancel:        EscrowCancelled,
+		EscrowExpire:        EscrowExpired,
+	},
+	EscrowBuyerConfirmed: {
+		EscrowConfirmSeller: EscrowReleased,
+		EscrowCancel:        EscrowCancelled,
+		EscrowExpire:        EscrowExpired,
+	},
+	EscrowSellerConfirmed: {
+		EscrowConfirmBuyer: EscrowReleased,
+		EscrowCancel:       EscrowCancelled,
+		EscrowExpire:       EscrowExpired,
+	},
+}
+
+// Escrow holds Amount taken from the buyer until it is released to the
+// seller or refunded.
+type Escrow struct {
+	ID        uuid.UUID
+	BuyerID   uuid.UUID
+	SellerID  uuid.UUID
+	Amount    int64
+	Status    string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// EscrowTransition records an event applied to an escrow, by whom and when.
+// From is empty for EscrowCreate.
+type EscrowTransition struct {
+	EscrowID uuid.UUID
+	Event    string
+	From     string
+	To       string
+	Actor    string
+	At       time.Time
+}
+
+// NewEscrow returns a held escrow and the transition that created it.
+func NewEscrow(buyerID, sellerID uuid.UUID, amount int64, expiresAt time.Time, actor string, now time.Time) (*Escrow, EscrowTransition, error) {
+	switch {
+	case amount == 0:
+		return nil, EscrowTransition{}, ErrZeroAmount
+	case amount < 0:
+		return nil, EscrowTransition{}, ErrNegativeAmount
+	case buyerID == sellerID:
+		return nil, EscrowTransition{}, ErrEscrowParties
+	case !expiresAt.After(now):
+		return nil, EscrowTransition{}, ErrEscrowDeadline
+	}
+
+	e := &Escrow{
+		ID:        uuid.New(),
+		BuyerID:   buyerID,
+		SellerID:  sellerID,
+		Amount:    amount,
+		Status:    EscrowHeld,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return e, EscrowTransition{EscrowID: e.ID, Event: EscrowCreate, To: EscrowHeld, Actor: actor, At: now}, nil
+}
+
+// EscrowConfirmation returns the event of a confirmation by party.
+func EscrowConfirmation(party string) (string, error) {
+	switch party {
+	case EscrowBuyer:
+		return EscrowConfirmBuyer, nil
+	case EscrowSeller:
+		return EscrowConfirmSeller, nil
+	}
+	return "", fmt.Errorf("%w, got %q", ErrEscrowParty, party)
+}
+
+// CheckConfirmer returns ErrEscrowSameActor when actor made an earlier
+// confirmation among transitions, so that one caller cannot release the
+// funds alone.
+func CheckConfirmer(transitions []EscrowTransition, actor string) error {
+	for _, t := range transitions {
+		if (t.Event == EscrowConfirmBuyer || t.Event == EscrowConfirmSeller) && t.Actor == actor {
+			return ErrEscrowSameActor
+		}
+	}
+	return nil
+}
+
+// Apply moves the escrow on by event and returns the transition. Only
+// EscrowExpire is allowed once the deadline has passed, and only then.
+func (e *Escrow) Apply(event, actor string, now time.Time) (EscrowTransition, error) {
+	next, ok := escrowTransitions[e.Status][event]
+	if !ok {
+		return EscrowTransition{}, fmt.Errorf("%w: %s from %s", ErrEscrowTransition, event, e.Status)
+	}
+	due := !now.Before(e.ExpiresAt)
+	if event != EscrowExpire && due {
+		return EscrowTransition{}, ErrEscrowExpired
+	}
+	if event == EscrowExpire && !due {
+		return EscrowTransition{}, fmt.Errorf("%w: %s before %s", ErrEscrowTransition, event, e.ExpiresAt.Format(time.RFC3339))
+	}
+
+	t := EscrowTransition{EscrowID: e.ID, Event: event, From: e.Status, To: next, Actor: actor, At: now}
+	e.Status = next
+	e.UpdatedAt = now
+	return t, nil
+}
+
+// Final reports whether the escrow can no longer change.
+func (e *Escrow) Final() bool {
+	_, ok := escrowTransitions[e.Status]
+	return !ok
+}
+
+// Payee returns the wallet the funds go to on entering the current status,
+// or false when the funds stay held.
+func (e *Escrow) Payee() (uuid.UUID, bool) {
+	switch e.Status {
+	case EscrowReleased:
+		return e.SellerID, true
+	case EscrowCancelled, EscrowExpired:
+		return e.BuyerID, true
+	}
+	return uuid.Nil, false
+}
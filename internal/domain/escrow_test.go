@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var escrowNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newTestEscrow(t *testing.T) *Escrow {
+	t.Helper()
+	e, created, err := NewEscrow(uuid.New(), uuid.New(), 100, escrowNow.Add(time.Hour), "anonymous", escrowNow)
+	require.NoError(t, err)
+	assert.Equal(t, EscrowHeld, created.To)
+	assert.Empty(t, created.From)
+	return e
+}
+
+func TestEscrow_BothConfirm_ReleasedToSeller(t *testing.T) {
+	// Порядок подтверждений не важен
+	for _, events := range [][]string{
+		{EscrowConfirmBuyer, EscrowConfirmSeller},
+		{EscrowConfirmSeller, EscrowConfirmBuyer},
+	} {
+		e := newTestEscrow(t)
+
+		_, err := e.Apply(events[0], "anonymous", escrowNow)
+		require.NoError(t, err)
+		_, held := e.Payee()
+		assert.False(t, held)
+
+		tr, err := e.Apply(events[1], "anonymous", escrowNow)
+		require.NoError(t, err)
+		assert.Equal(t, EscrowReleased, tr.To)
+		payee, ok := e.Payee()
+		assert.True(t, ok)
+		assert.Equal(t, e.SellerID, payee)
+		assert.True(t, e.Final())
+	}
+}
+
+func TestEscrow_ConfirmTwice_NotAllowed(t *testing.T) {
+	e := newTestEscrow(t)
+	_, err := e.Apply(EscrowConfirmBuyer, "anonymous", escrowNow)
+	require.NoError(t, err)
+
+	_, err = e.Apply(EscrowConfirmBuyer, "anonymous", escrowNow)
+	assert.ErrorIs(t, err, ErrEscrowTransition)
+	assert.Equal(t, EscrowBuyerConfirmed, e.Status)
+}
+
+func TestCheckConfirmer(t *testing.T) {
+	e := newTestEscrow(t)
+	tr, err := e.Apply(EscrowConfirmBuyer, "alice", escrowNow)
+	require.NoError(t, err)
+	transitions := []EscrowTransition{tr}
+
+	assert.ErrorIs(t, CheckConfirmer(transitions, "alice"), ErrEscrowSameActor)
+	assert.ErrorIs(t, CheckConfirmer(transitions, "alice"), ErrEscrowTransition)
+	assert.NoError(t, CheckConfirmer(transitions, "bob"))
+}
+
+func TestEscrow_Cancel_RefundedToBuyer(t *testing.T) {
+	e := newTestEscrow(t)
+	_, err := e.Apply(EscrowConfirmSeller, "anonymous", escrowNow)
+	require.NoError(t, err)
+
+	tr, err := e.Apply(EscrowCancel, "anonymous", escrowNow)
+	require.NoError(t, err)
+	assert.Equal(t, EscrowSellerConfirmed, tr.From)
+	payee, ok := e.Payee()
+	assert.True(t, ok)
+	assert.Equal(t, e.BuyerID, payee)
+
+	// Из конечного состояния переходов нет
+	_, err = e.Apply(EscrowCancel, "anonymous", escrowNow)
+	assert.ErrorIs(t, err, ErrEscrowTransition)
+}
+
+func TestEscrow_Deadline(t *testing.T) {
+	e := newTestEscrow(t)
+	_, err := e.Apply(EscrowExpire, "expiry", escrowNow)
+	assert.ErrorIs(t, err, ErrEscrowTransition)
+
+	later := escrowNow.Add(time.Hour)
+	_, err = e.Apply(EscrowConfirmBuyer, "anonymous", later)
+	assert.ErrorIs(t, err, ErrEscrowExpired)
+
+	tr, err := e.Apply(EscrowExpire, "expiry", later)
+	require.NoError(t, err)
+	assert.Equal(t, EscrowExpired, tr.To)
+	assert.Equal(t, later, e.UpdatedAt)
+}
+
+func TestNewEscrow_Invalid(t *testing.T) {
+	id := uuid.New()
+	_, _, err := NewEscrow(id, id, 100, escrowNow.Add(time.Hour), "anonymous", escrowNow)
+	assert.ErrorIs(t, err, ErrEscrowParties)
+	_, _, err = NewEscrow(id, uuid.New(), 0, escrowNow.Add(time.Hour), "anonymous", escrowNow)
+	assert.ErrorIs(t, err, ErrZeroAmount)
+	_, _, err = NewEscrow(id, uuid.New(), 100, escrowNow, "anonymous", escrowNow)
+	assert.ErrorIs(t, err, ErrEscrowDeadline)
+}
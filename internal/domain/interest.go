@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"math/bits"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxInterestRate is the highest annual rate, 100%, in basis points.
+const MaxInterestRate = 10000
+
+// daysPerYear is the day count of the actual/365 fixed convention.
+const daysPerYear = 365
+
+var (
+	ErrInterestAccountNotFound = errors.New("interest account not found")
+	ErrInvalidInterestRate     = errors.New("interest rate must be between 0 and 10000 basis points")
+)
+
+// InterestAccount is a savings wallet earning interest. Interest accrues
+// daily on the end-of-day balance and is paid out monthly.
+type InterestAccount struct {
+	WalletID uuid.UUID
+	// Rate is the annual rate in basis points.
+	Rate int64
+	// Accrued is the interest accrued and not paid out yet.
+	Accrued int64
+	// NextAccrualAt is the start of the first day not accrued yet.
+	NextAccrualAt time.Time
+	// NextPayoutAt is the start of the first month not paid out yet.
+	NextPayoutAt time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// PayoutDue reports whether every day of the next month to pay out has been
+// accrued.
+func (a *InterestAccount) PayoutDue() bool {
+	return !a.NextAccrualAt.Before(a.NextPayoutAt.AddDate(0, 1, 0))
+}
+
+// InterestAccrual is the interest earned on one day.
+type InterestAccrual struct {
+	WalletID uuid.UUID
+	Day      time.Time
+	// Balance is the balance at the end of the day.
+	Balance int64
+	Rate    int64
+	Amount  int64
+}
+
+// InterestPayout is the interest of one month deposited to the wallet.
+type InterestPayout struct {
+	WalletID uuid.UUID
+	Period   time.Time
+	Amount   int64
+	PaidAt   time.Time
+}
+
+// DailyInterest is the interest on balance for one day at the annual rate
+// in basis points: balance*rate/(10000*365), rounded half to even to minor
+// units. Rates above MaxInterestRate count as MaxInterestRate.
+func DailyInterest(balance, rate int64) int64 {
+	if balance <= 0 || rate <= 0 {
+		return 0
+	}
+
+	const divisor = 10000 * daysPerYear
+	// The high word stays below divisor for rates up to MaxInterestRate.
+	hi, lo := bits.Mul64(uint64(balance), uint64(min(rate, MaxInterestRate)))
+	quo, rem := bits.Div64(hi, lo, divisor)
+	if 2*rem > divisor || (2*rem == divisor && quo%2 == 1) {
+		quo++
+	}
+	return int64(quo)
+}
+
+// StartOfDay is the start of the UTC day of t.
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// StartOfMonth is the start of the UTC month of t.
+func StartOfMonth(t time.Time) time.Time {
+	y, m, _ := t.UTC().Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyInterest_RoundsHalfToEven(t *testing.T) {
+	cases := []struct {
+		name           string
+		balance, rate  int64
+		expectedAmount int64
+	}{
+		{"exact", 3650000, 100, 100},
+		// 1825 * 1000 / 3650000 = 0.5
+		{"half_down_to_even", 1825, 1000, 0},
+		// 5475 * 1000 / 3650000 = 1.5
+		{"half_up_to_even", 5475, 1000, 2},
+		{"below_half", 1824, 1000, 0},
+		{"above_half", 1826, 1000, 1},
+		{"zero_rate", 1000000, 0, 0},
+		{"empty_wallet", 0, 500, 0},
+		{"max_balance", math.MaxInt64, MaxInterestRate, 25269512429739112},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedAmount, DailyInterest(tc.balance, tc.rate))
+		})
+	}
+}
+
+func TestInterestAccount_PayoutDue_AfterLastDayOfMonth(t *testing.T) {
+	october := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	account := InterestAccount{NextPayoutAt: october, NextAccrualAt: time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)}
+	assert.False(t, account.PayoutDue())
+
+	account.NextAccrualAt = time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	assert.True(t, account.PayoutDue())
+}
@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of wallet transactions.
+const (
+	TransactionDeposit  = "deposit"
+	TransactionWithdraw = "withdraw"
+	// TransactionFee is a fee paid by the wallet.
+	TransactionFee = "fee"
+	// TransactionFeeIncome is a fee collected by the fee wallet.
+	TransactionFeeIncome = "fee_income"
+	// TransactionInterest is a monthly interest payout.
+	TransactionInterest = "interest"
+	// TransactionBonus is a grant of bonus credit.
+	TransactionBonus = "bonus"
+	// TransactionBonusExpired removes the unspent part of an expired bonus.
+	TransactionBonusExpired = "bonus_expired"
+	// TransactionEscrowHold moves funds of the buyer into an escrow.
+	TransactionEscrowHold = "escrow_hold"
+	// TransactionEscrowRelease pays the funds of an escrow to the seller.
+	TransactionEscrowRelease = "escrow_release"
+	// TransactionEscrowRefund returns the funds of an escrow to the buyer.
+	TransactionEscrowRefund = "escrow_refund"
+	// TransactionSplitOut takes a split payment from the source wallet.
+	TransactionSplitOut = "split_out"
+	// TransactionSplitIn pays a share of a split payment to a recipient.
+	TransactionSplitIn = "split_in"
+)
+
+var ErrChainBroken = errors.New("transaction chain is broken")
+
+// Hash is a SHA-256 digest. The zero Hash is the previous hash of the first
+// transaction of a wallet.
+type Hash [sha256.Size]byte
+
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("invalid hash %q", s)
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	parsed, err := ParseHash(string(text))
+	if err != nil {
+		return err
+	}
+	*h = parsed
+	return nil
+}
+
+// Transaction is a stored balance change. Transactions of a wallet are
+// numbered from 1 and chained: each one carries the hash of its predecessor,
+// so editing, removing or reordering any of them breaks every later link.
+type Transaction struct {
+	WalletID     uuid.UUID
+	Seq          int64
+	Kind         string
+	Amount       int64
+	BalanceAfter int64
+	At           time.Time
+	PrevHash     Hash
+	Hash         Hash
+}
+
+// NewTransaction builds the transaction following prev, nil for the first
+// one of the wallet, and seals it with its hash. at is kept to microseconds,
+// the precision storages preserve.
+func NewTransaction(prev *Transaction, walletID uuid.UUID, kind string, amount, balanceAfter int64, at time.Time) *Transaction {
+	t := &Transaction{
+		WalletID:     walletID,
+		Seq:          1,
+		Kind:         kind,
+		Amount:       amount,
+		BalanceAfter: balanceAfter,
+		At:           at.UTC().Truncate(time.Microsecond),
+	}
+	if prev != nil {
+		t.Seq = prev.Seq + 1
+		t.PrevHash = prev.Hash
+	}
+	t.Hash = t.ComputeHash()
+	return t
+}
+
+// Delta is the signed change of the balance.
+func (t *Transaction) Delta() int64 {
+	if t.Kind == TransactionWithdraw || t.Kind == TransactionFee || t.Kind == TransactionBonusExpired || t.Kind == TransactionEscrowHold || t.Kind == TransactionSplitOut {
+		return -t.Amount
+	}
+	return t.Amount
+}
+
+// ComputeHash hashes the content of t together with PrevHash.
+func (t *Transaction) ComputeHash() Hash {
+	var b bytes.Buffer
+	b.WriteString("wallet-transaction v1\n")
+	for _, field := range []string{
+		t.WalletID.String(),
+		strconv.FormatInt(t.Seq, 10),
+		t.Kind,
+		strconv.FormatInt(t.Amount, 10),
+		strconv.FormatInt(t.BalanceAfter, 10),
+		strconv.FormatInt(t.At.UnixMicro(), 10),
+		t.PrevHash.String(),
+	} {
+		b.WriteString(field)
+		b.WriteByte('\n')
+	}
+	return sha256.Sum256(b.Bytes())
+}
+
+// Check verifies t as the successor of prev, nil when t should be the first
+// transaction of the wallet. It returns nil when the link holds.
+func (t *Transaction) Check(prev *Transaction) *ChainBreak {
+	broken := func(reason string) *ChainBreak {
+		return &ChainBreak{WalletID: t.WalletID, Seq: t.Seq, Reason: reason}
+	}
+
+	var (
+		seq      int64 = 1
+		prevHash Hash
+	)
+	if prev != nil {
+		seq, prevHash = prev.Seq+1, prev.Hash
+	}
+	switch {
+	case t.Seq != seq:
+		return broken(fmt.Sprintf("expected transaction %d", seq))
+	case t.PrevHash != prevHash:
+		return broken("previous hash does not match the previous transaction")
+	case t.Hash != t.ComputeHash():
+		return broken("hash does not match the content")
+	case prev != nil && t.BalanceAfter != prev.BalanceAfter+t.Delta():
+		return broken("balance does not follow from the previous transaction")
+	}
+	return nil
+}
+
+// ChainBreak is the first transaction of a wallet found not to fit the chain.
+type ChainBreak struct {
+	WalletID uuid.UUID
+	Seq      int64
+	Reason   string
+}
+
+func (b *ChainBreak) Error() string {
+	return fmt.Sprintf("wallet %s transaction %d: %s", b.WalletID, b.Seq, b.Reason)
+}
+
+func (b *ChainBreak) Unwrap() error {
+	return ErrChainBroken
+}
+
+// ChainReport sums up a walk over transaction chains. The walk stops at the
+// first break.
+type ChainReport struct {
+	Wallets      int
+	Transactions int64
+	Break        *ChainBreak
+}
+
+// ChainHead is the last transaction of a wallet at the time of a checkpoint.
+type ChainHead struct {
+	WalletID uuid.UUID
+	Seq      int64
+	Hash     Hash
+}
+
+// Checkpoint fixes the heads of all chains at a moment. A signed checkpoint
+// kept outside the database shows whether history up to it was later
+// rewritten, which the chain alone cannot tell once every hash is
+// recomputed.
+type Checkpoint struct {
+	At    time.Time
+	Heads []ChainHead
+	Root  Hash
+}
+
+// NewCheckpoint sorts heads by wallet and computes their root hash.
+func NewCheckpoint(at time.Time, heads []ChainHead) *Checkpoint {
+	heads = slices.Clone(heads)
+	slices.SortFunc(heads, func(a, b ChainHead) int {
+		return bytes.Compare(a.WalletID[:], b.WalletID[:])
+	})
+	return &Checkpoint{At: at.UTC(), Heads: heads, Root: CheckpointRoot(heads)}
+}
+
+// CheckpointRoot hashes heads in the order given.
+func CheckpointRoot(heads []ChainHead) Hash {
+	h := sha256.New()
+	for _, head := range heads {
+		fmt.Fprintf(h, "%s %d %s\n", head.WalletID, head.Seq, head.Hash)
+	}
+	var root Hash
+	h.Sum(root[:0])
+	return root
+}
+
+// SignedContent is what a checkpoint signature covers.
+func (c *Checkpoint) SignedContent() []byte {
+	return fmt.Appendf(nil, "wallet-checkpoint v1\n%s\n%d\n%s\n",
+		c.At.UTC().Format(time.RFC3339Nano), len(c.Heads), c.Root)
+}
+
+// BalanceSnapshot is the balance of a wallet right after transaction Seq,
+// made at At. Historical balances are replayed from the nearest snapshot
+// instead of the start of the chain.
+type BalanceSnapshot struct {
+	WalletID uuid.UUID
+	Seq      int64
+	Balance  int64
+	At       time.Time
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testChain(t *testing.T) []*Transaction {
+	t.Helper()
+
+	id := uuid.New()
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	first := NewTransaction(nil, id, TransactionDeposit, 100, 100, at)
+	second := NewTransaction(first, id, TransactionWithdraw, 30, 70, at.Add(time.Second))
+	third := NewTransaction(second, id, TransactionDeposit, 5, 75, at.Add(2*time.Second))
+	return []*Transaction{first, second, third}
+}
+
+func TestNewTransaction_ChainsToPrevious(t *testing.T) {
+	chain := testChain(t)
+
+	assert.Equal(t, int64(1), chain[0].Seq)
+	assert.Equal(t, Hash{}, chain[0].PrevHash)
+	assert.Equal(t, int64(2), chain[1].Seq)
+	assert.Equal(t, chain[0].Hash, chain[1].PrevHash)
+
+	var prev *Transaction
+	for _, tx := range chain {
+		assert.Nil(t, tx.Check(prev))
+		prev = tx
+	}
+}
+
+func TestTransactionCheck_EditedAmount_HashMismatch(t *testing.T) {
+	chain := testChain(t)
+	chain[1].Amount = 3
+
+	brk := chain[1].Check(chain[0])
+
+	require.NotNil(t, brk)
+	assert.Equal(t, int64(2), brk.Seq)
+	assert.Equal(t, "hash does not match the content", brk.Reason)
+	assert.ErrorIs(t, brk, ErrChainBroken)
+}
+
+func TestTransactionCheck_RehashedEdit_BreaksNextLink(t *testing.T) {
+	chain := testChain(t)
+	// Запись переписана вместе с хешем, но следующая ссылается на старый
+	chain[1].Amount = 3
+	chain[1].BalanceAfter = 97
+	chain[1].Hash = chain[1].ComputeHash()
+
+	assert.Nil(t, chain[1].Check(chain[0]))
+	brk := chain[2].Check(chain[1])
+
+	require.NotNil(t, brk)
+	assert.Equal(t, int64(3), brk.Seq)
+	assert.Equal(t, "previous hash does not match the previous transaction", brk.Reason)
+}
+
+func TestTransactionCheck_RemovedTransaction_SequenceGap(t *testing.T) {
+	chain := testChain(t)
+
+	brk := chain[2].Check(chain[0])
+
+	require.NotNil(t, brk)
+	assert.Equal(t, "expected transaction 2", brk.Reason)
+}
+
+func TestTransactionCheck_BalanceDoesNotFollow(t *testing.T) {
+	chain := testChain(t)
+	chain[2].BalanceAfter = 1000
+	chain[2].Hash = chain[2].ComputeHash()
+
+	brk := chain[2].Check(chain[1])
+
+	require.NotNil(t, brk)
+	assert.Equal(t, "balance does not follow from the previous transaction", brk.Reason)
+}
+
+func TestNewCheckpoint_RootIndependentOfHeadOrder(t *testing.T) {
+	at := time.Now()
+	heads := []ChainHead{
+		{WalletID: uuid.New(), Seq: 3, Hash: Hash{1}},
+		{WalletID: uuid.New(), Seq: 1, Hash: Hash{2}},
+	}
+
+	a := NewCheckpoint(at, heads)
+	b := NewCheckpoint(at, []ChainHead{heads[1], heads[0]})
+
+	assert.Equal(t, a.Root, b.Root)
+	assert.Equal(t, a.Heads, b.Heads)
+
+	heads[0].Seq = 4
+	assert.NotEqual(t, a.Root, NewCheckpoint(at, heads).Root)
+}
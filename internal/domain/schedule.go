@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operations a schedule can run.
+const (
+	ScheduleDeposit  = "DEPOSIT"
+	ScheduleWithdraw = "WITHDRAW"
+)
+
+// Schedule statuses. A schedule waits as pending, is running while a worker
+// holds it and is retrying after a failed attempt. A one-off schedule ends
+// completed or failed; a recurring one goes back to pending after every run
+// until it is cancelled.
+const (
+	SchedulePending   = "pending"
+	ScheduleRunning   = "running"
+	ScheduleRetrying  = "retrying"
+	ScheduleCompleted = "completed"
+	ScheduleFailed    = "failed"
+	ScheduleCancelled = "cancelled"
+)
+
+var (
+	ErrScheduleNotFound = errors.New("schedule not found")
+	// ErrScheduleNotCancellable is returned for a schedule that is running
+	// or has already ended.
+	ErrScheduleNotCancellable = errors.New("schedule is running or has ended")
+	// ErrScheduleLeaseLost means another worker took the schedule over after
+	// the lease of this one expired.
+	ErrScheduleLeaseLost = errors.New("schedule lease was lost")
+)
+
+// Schedule is a wallet operation run at NextRunAt, once or on a cron
+// schedule.
+type Schedule struct {
+	ID        uuid.UUID
+	WalletID  uuid.UUID
+	Operation string
+	Amount    int64
+	// Cron is empty for a one-off schedule.
+	Cron   string
+	Status string
+	// NextRunAt is when the schedule is due, or was due for a running one.
+	NextRunAt time.Time
+	// Attempts counts the attempts of the current run.
+	Attempts    int
+	MaxAttempts int
+	// Runs counts successful runs; Failures counts runs given up after the
+	// last attempt.
+	Runs      int64
+	Failures  int64
+	LastRunAt time.Time
+	LastError string
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// ClaimedAt identifies the claim of a running schedule; the worker
+	// holding it must finish before LeaseUntil.
+	ClaimedAt  time.Time
+	LeaseUntil time.Time
+}
+
+// NewSchedule returns a pending schedule first due at runAt.
+func NewSchedule(walletID uuid.UUID, operation string, amount int64, cron string, runAt time.Time, maxAttempts int, createdBy string, now time.Time) (*Schedule, error) {
+	switch {
+	case operation != ScheduleDeposit && operation != ScheduleWithdraw:
+		return nil, fmt.Errorf("unknown operation %q", operation)
+	case amount == 0:
+		return nil, ErrZeroAmount
+	case amount < 0:
+		return nil, ErrNegativeAmount
+	case maxAttempts < 1:
+		return nil, fmt.Errorf("max attempts must be positive, got %d", maxAttempts)
+	}
+
+	now = now.UTC().Truncate(time.Microsecond)
+	return &Schedule{
+		ID:          uuid.New(),
+		WalletID:    walletID,
+		Operation:   operation,
+		Amount:      amount,
+		Cron:        cron,
+		Status:      SchedulePending,
+		NextRunAt:   runAt.UTC().Truncate(time.Microsecond),
+		MaxAttempts: maxAttempts,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+func (s *Schedule) Recurring() bool {
+	return s.Cron != ""
+}
+
+// ScheduleClaim is a schedule a worker has claimed to run. Interrupted is
+// the previous claim of a schedule taken over after its lease expired: that
+// run may already have been applied.
+type ScheduleClaim struct {
+	Schedule    Schedule
+	Interrupted *time.Time
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Remainder rules of a split. Percentage shares are rounded down to minor
+// units, and the rule decides where the units left over go.
+const (
+	// SplitRemainderLargest adds the remainder to the largest percentage
+	// share, the first of equal ones. It is the rule when none is given.
+	SplitRemainderLargest = "largest"
+	// SplitRemainderFirst and SplitRemainderLast add it to the first or the
+	// last percentage share.
+	SplitRemainderFirst = "first"
+	SplitRemainderLast  = "last"
+	// SplitRemainderSource leaves it with the source, which is debited that
+	// much less.
+	SplitRemainderSource = "source"
+)
+
+// MaxSplitRecipients is the most recipients a split may have.
+const MaxSplitRecipients = 20
+
+// SplitWhole is 100% in basis points.
+const SplitWhole = 10000
+
+var (
+	ErrSplitRecipients = fmt.Errorf("split needs 1 to %d recipients", MaxSplitRecipients)
+	ErrSplitRecipient  = errors.New("recipients must be distinct wallets other than the source")
+	ErrSplitShare      = errors.New("share must be either a fixed amount or a percentage between 1 and 10000 basis points")
+	ErrSplitShares     = errors.New("fixed shares must not exceed the amount and percentage shares must add up to 10000 basis points of the rest")
+	ErrSplitRemainder  = errors.New("remainder must be largest, first, last or source")
+)
+
+// SplitShare is the part of a split paid to a wallet: either a fixed Amount
+// or a Percent, in basis points, of what the fixed shares leave.
+type SplitShare struct {
+	WalletID uuid.UUID
+	Amount   int64
+	Percent  int64
+}
+
+// SplitCredit is the amount a recipient of a split is paid. It is zero for a
+// percentage share too small to round to a minor unit.
+type SplitCredit struct {
+	WalletID uuid.UUID
+	Amount   int64
+}
+
+// Split is a payment from SourceID divided among recipients. Credits follow
+// the order of the shares and add up to Debit.
+type Split struct {
+	SourceID uuid.UUID
+	Debit    int64
+	// Remainder is what rounding left over; it is part of a credit unless the
+	// rule is SplitRemainderSource.
+	Remainder int64
+	Credits   []SplitCredit
+}
+
+// SplitReceipt is the outcome of a split: the source wallet after it and the
+// amounts moved.
+type SplitReceipt struct {
+	Wallet *Wallet
+	Split  *Split
+}
+
+// NewSplit divides amount among shares. Fixed shares are paid first and the
+// percentage shares divide the rest; without percentage shares the fixed
+// ones must add up to amount.
+func NewSplit(sourceID uuid.UUID, amount int64, shares []SplitShare, remainder string) (*Split, error) {
+	switch {
+	case amount == 0:
+		return nil, ErrZeroAmount
+	case amount < 0:
+		return nil, ErrNegativeAmount
+	case len(shares) == 0 || len(shares) > MaxSplitRecipients:
+		return nil, ErrSplitRecipients
+	}
+	switch remainder {
+	case "":
+		remainder = SplitRemainderLargest
+	case SplitRemainderLargest, SplitRemainderFirst, SplitRemainderLast, SplitRemainderSource:
+	default:
+		return nil, fmt.Errorf("%w, got %q", ErrSplitRemainder, remainder)
+	}
+
+	seen := map[uuid.UUID]bool{sourceID: true}
+	var fixed, percent int64
+	var percentages []int
+	for i, share := range shares {
+		if seen[share.WalletID] {
+			return nil, ErrSplitRecipient
+		}
+		seen[share.WalletID] = true
+
+		switch {
+		case share.Amount > 0 && share.Percent == 0:
+			if share.Amount > amount-fixed {
+				return nil, ErrSplitShares
+			}
+			fixed += share.Amount
+		case share.Amount == 0 && share.Percent > 0 && share.Percent <= SplitWhole:
+			percent += share.Percent
+			percentages = append(percentages, i)
+		default:
+			return nil, ErrSplitShare
+		}
+	}
+	rest := amount - fixed
+	if len(percentages) == 0 && rest != 0 || len(percentages) > 0 && (percent != SplitWhole || rest == 0) {
+		return nil, ErrSplitShares
+	}
+
+	s := &Split{SourceID: sourceID, Debit: amount, Remainder: rest, Credits: make([]SplitCredit, len(shares))}
+	for i, share := range shares {
+		credit := share.Amount
+		if share.Percent > 0 {
+			credit = percentOf(rest, share.Percent)
+			s.Remainder -= credit
+		}
+		s.Credits[i] = SplitCredit{WalletID: share.WalletID, Amount: credit}
+	}
+	if s.Remainder == 0 {
+		return s, nil
+	}
+
+	var to int
+	switch remainder {
+	case SplitRemainderSource:
+		s.Debit -= s.Remainder
+		if s.Debit == 0 {
+			// Every share rounded down to nothing: there is no payment.
+			return nil, ErrSplitShares
+		}
+		return s, nil
+	case SplitRemainderFirst:
+		to = percentages[0]
+	case SplitRemainderLast:
+		to = percentages[len(percentages)-1]
+	case SplitRemainderLargest:
+		to = percentages[0]
+		for _, i := range percentages[1:] {
+			if shares[i].Percent > shares[to].Percent {
+				to = i
+			}
+		}
+	}
+	s.Credits[to].Amount += s.Remainder
+	return s, nil
+}
+
+// LockOrder returns the wallets the split changes, ordered by ID so that
+// splits over the same wallets always lock them in the same order. A caller
+// that locks some wallet last everywhere, like a fee collector, moves it to
+// the end.
+func (s *Split) LockOrder() []uuid.UUID {
+	ids := []uuid.UUID{s.SourceID}
+	for _, c := range s.Credits {
+		if c.Amount > 0 {
+			ids = append(ids, c.WalletID)
+		}
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	return ids
+}
+
+// Credit returns the amount the split pays to id.
+func (s *Split) Credit(id uuid.UUID) int64 {
+	for _, c := range s.Credits {
+		if c.WalletID == id {
+			return c.Amount
+		}
+	}
+	return 0
+}
+
+// percentOf is amount*percent/SplitWhole rounded down.
+func percentOf(amount, percent int64) int64 {
+	// The high word stays below SplitWhole for percent up to SplitWhole.
+	hi, lo := bits.Mul64(uint64(amount), uint64(percent))
+	quo, _ := bits.Div64(hi, lo, SplitWhole)
+	return int64(quo)
+}
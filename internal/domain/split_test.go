@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func credits(s *Split) []int64 {
+	amounts := make([]int64, len(s.Credits))
+	for i, c := range s.Credits {
+		amounts[i] = c.Amount
+	}
+	return amounts
+}
+
+func TestNewSplit_FixedThenPercentages(t *testing.T) {
+	source, merchant, platform, partner := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	// Фиксированная доля платформы берётся первой, остаток делится в процентах
+	s, err := NewSplit(source, 1000, []SplitShare{
+		{WalletID: merchant, Percent: 8000},
+		{WalletID: platform, Amount: 100},
+		{WalletID: partner, Percent: 2000},
+	}, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1000), s.Debit)
+	assert.Equal(t, []int64{720, 100, 180}, credits(s))
+	assert.Zero(t, s.Remainder)
+	assert.Equal(t, int64(100), s.Credit(platform))
+}
+
+func TestNewSplit_RemainderRules(t *testing.T) {
+	source := uuid.New()
+	shares := []SplitShare{
+		{WalletID: uuid.New(), Percent: 3000},
+		{WalletID: uuid.New(), Percent: 4000},
+		{WalletID: uuid.New(), Percent: 3000},
+	}
+
+	// 101 * 30% = 30.3, 101 * 40% = 40.4: после округления вниз остаётся 1
+	for remainder, want := range map[string][]int64{
+		"":                    {30, 41, 30},
+		SplitRemainderLargest: {30, 41, 30},
+		SplitRemainderFirst:   {31, 40, 30},
+		SplitRemainderLast:    {30, 40, 31},
+		SplitRemainderSource:  {30, 40, 30},
+	} {
+		s, err := NewSplit(source, 101, shares, remainder)
+		require.NoError(t, err, remainder)
+		assert.Equal(t, want, credits(s), remainder)
+		assert.Equal(t, int64(1), s.Remainder, remainder)
+
+		var total int64
+		for _, c := range want {
+			total += c
+		}
+		assert.Equal(t, total, s.Debit, remainder)
+	}
+}
+
+func TestNewSplit_LargeAmount_NoOverflow(t *testing.T) {
+	s, err := NewSplit(uuid.New(), math.MaxInt64, []SplitShare{
+		{WalletID: uuid.New(), Percent: 5000},
+		{WalletID: uuid.New(), Percent: 5000},
+	}, SplitRemainderFirst)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{math.MaxInt64/2 + 1, math.MaxInt64 / 2}, credits(s))
+}
+
+func TestNewSplit_Invalid(t *testing.T) {
+	source, a, b := uuid.New(), uuid.New(), uuid.New()
+
+	for name, tc := range map[string]struct {
+		amount    int64
+		shares    []SplitShare
+		remainder string
+		err       error
+	}{
+		"no recipients":       {100, nil, "", ErrSplitRecipients},
+		"source as recipient": {100, []SplitShare{{WalletID: source, Amount: 100}}, "", ErrSplitRecipient},
+		"duplicate recipient": {100, []SplitShare{{WalletID: a, Amount: 50}, {WalletID: a, Amount: 50}}, "", ErrSplitRecipient},
+		"amount and percent":  {100, []SplitShare{{WalletID: a, Amount: 50, Percent: 5000}}, "", ErrSplitShare},
+		"percent above 100%":  {100, []SplitShare{{WalletID: a, Percent: 10001}}, "", ErrSplitShare},
+		"fixed below amount":  {100, []SplitShare{{WalletID: a, Amount: 50}, {WalletID: b, Amount: 40}}, "", ErrSplitShares},
+		"fixed above amount":  {100, []SplitShare{{WalletID: a, Amount: math.MaxInt64}, {WalletID: b, Amount: math.MaxInt64}}, "", ErrSplitShares},
+		"percent below 100%":  {100, []SplitShare{{WalletID: a, Percent: 5000}, {WalletID: b, Percent: 4000}}, "", ErrSplitShares},
+		"nothing to percent":  {100, []SplitShare{{WalletID: a, Amount: 100}, {WalletID: b, Percent: 10000}}, "", ErrSplitShares},
+		"nothing debited":     {1, []SplitShare{{WalletID: a, Percent: 5000}, {WalletID: b, Percent: 5000}}, SplitRemainderSource, ErrSplitShares},
+		"unknown remainder":   {100, []SplitShare{{WalletID: a, Amount: 100}}, "random", ErrSplitRemainder},
+		"zero amount":         {0, []SplitShare{{WalletID: a, Amount: 100}}, "", ErrZeroAmount},
+	} {
+		_, err := NewSplit(source, tc.amount, tc.shares, tc.remainder)
+		assert.ErrorIs(t, err, tc.err, name)
+	}
+}
+
+func TestSplit_LockOrder_SortedWithoutZeroCredits(t *testing.T) {
+	source := uuid.New()
+	recipients := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+
+	// Третьей доле при сумме 3 не достаётся ни одной единицы
+	s, err := NewSplit(source, 3, []SplitShare{
+		{WalletID: recipients[0], Percent: 4950},
+		{WalletID: recipients[1], Percent: 4950},
+		{WalletID: recipients[2], Percent: 100},
+	}, SplitRemainderFirst)
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 1, 0}, credits(s))
+
+	order := s.LockOrder()
+	assert.ElementsMatch(t, []uuid.UUID{source, recipients[0], recipients[1]}, order)
+	for i := 1; i < len(order); i++ {
+		assert.Negative(t, bytes.Compare(order[i-1][:], order[i][:]))
+	}
+}
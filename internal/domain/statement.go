@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of statement lines. A statement is an opening line, a movement line
+// per transaction in the period and a closing line.
+const (
+	StatementOpening  = "opening"
+	StatementMovement = "movement"
+	StatementClosing  = "closing"
+)
+
+// StatementLine is one line of a wallet statement. Balance is the balance
+// at At: before the period for the opening line, after the transaction for
+// a movement and at the end of the period for the closing line.
+type StatementLine struct {
+	Type     string
+	WalletID uuid.UUID
+	At       time.Time
+	Balance  int64
+	// Movement is set on movement lines only.
+	Movement *Transaction
+}
@@ -13,9 +13,22 @@ var walletPool = sync.Pool{
 	},
 }
 
+// Wallet holds a balance of cash and bonus. The balance is the total of
+// both; the bonus part is split into buckets that expire.
 type Wallet struct {
 	id      uuid.UUID
 	balance int64
+
+	bonuses        []Bonus
+	bonusesChanged bool
+	order          string
+}
+
+// Receipt is the outcome of a deposit or withdrawal: the wallet after it and
+// the fee charged on top of the amount.
+type Receipt struct {
+	Wallet *Wallet
+	Fee    int64
 }
 
 func NewWallet(id uuid.UUID, balance int64) (*Wallet, error) {
@@ -31,6 +44,10 @@ func NewWallet(id uuid.UUID, balance int64) (*Wallet, error) {
 func (w *Wallet) Release() {
 	w.id = uuid.Nil
 	w.balance = 0
+	// Bonuses may still be referenced by the caller.
+	w.bonuses = nil
+	w.bonusesChanged = false
+	w.order = ""
 	walletPool.Put(w)
 }
 
@@ -69,6 +86,7 @@ func (w *Wallet) Withdraw(amount int64) error {
 		return ErrInsufficientBalance
 	}
 
+	w.spend(amount)
 	w.balance -= amount
 
 	return nil
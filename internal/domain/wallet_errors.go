@@ -8,4 +8,8 @@ var (
 	ErrInsufficientBalance = errors.New("insufficient balance")
 	ErrOverflow            = errors.New("balance overflow")
 	ErrWalletNotFound      = errors.New("wallet not found")
+	ErrWalletExists        = errors.New("wallet already exists")
+	ErrWalletBusy          = errors.New("wallet is locked by another operation")
+	ErrTimeout             = errors.New("operation timed out")
+	ErrUnavailable         = errors.New("storage is unavailable")
 )
@@ -0,0 +1,143 @@
+// Package fees computes the fees charged on wallet operations.
+//
+// A fee schedule is a JSON document with a list of rules. A rule applies to
+// one operation type and either to one wallet tier or, with an empty tier,
+// to every wallet without a rule of its own:
+//
+//	{"rules": [
+//	  {"operation": "WITHDRAW", "fixed": 10, "basisPoints": 50, "min": 20, "max": 1000},
+//	  {"operation": "WITHDRAW", "tier": "gold", "basisPoints": 25}
+//	]}
+//
+// The fee is fixed plus basisPoints hundredths of a percent of the amount,
+// rounded up, then raised to min and, when max is set, lowered to max.
+package fees
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+)
+
+// Operations a rule may apply to.
+const (
+	OperationDeposit  = "DEPOSIT"
+	OperationWithdraw = "WITHDRAW"
+)
+
+// maxBasisPoints is 100%.
+const maxBasisPoints = 10000
+
+var ErrInvalidSchedule = errors.New("invalid fee schedule")
+
+type Rule struct {
+	Operation string `json:"operation"`
+	// Tier is empty for the default rule of the operation.
+	Tier        string `json:"tier,omitempty"`
+	Fixed       int64  `json:"fixed,omitempty"`
+	BasisPoints int64  `json:"basisPoints,omitempty"`
+	Min         int64  `json:"min,omitempty"`
+	// Max is zero for no upper bound.
+	Max int64 `json:"max,omitempty"`
+}
+
+// Schedule is a validated set of rules. A nil Schedule charges nothing.
+type Schedule struct {
+	rules []Rule
+}
+
+// New validates rules: every rule names a known operation, has non-negative
+// amounts, at most 100% and max not below min, and no two rules share an
+// operation and a tier.
+func New(rules []Rule) (*Schedule, error) {
+	var errs []error
+	for i, r := range rules {
+		invalid := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("rule %d: "+format, append([]any{i}, args...)...))
+		}
+		switch {
+		case r.Operation != OperationDeposit && r.Operation != OperationWithdraw:
+			invalid("unknown operation %q", r.Operation)
+		case r.Fixed < 0, r.BasisPoints < 0, r.Min < 0, r.Max < 0:
+			invalid("amounts must not be negative")
+		case r.BasisPoints > maxBasisPoints:
+			invalid("basisPoints must not exceed %d", maxBasisPoints)
+		case r.Max != 0 && r.Max < r.Min:
+			invalid("max must not be below min")
+		case slices.ContainsFunc(rules[:i], func(o Rule) bool { return o.Operation == r.Operation && o.Tier == r.Tier }):
+			invalid("duplicates the rule for %s and tier %q", r.Operation, r.Tier)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchedule, errors.Join(errs...))
+	}
+	return &Schedule{rules: slices.Clone(rules)}, nil
+}
+
+// Load reads a schedule from a JSON file.
+func Load(path string) (*Schedule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fee schedule: %w", err)
+	}
+
+	var doc struct {
+		Rules []Rule `json:"rules"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidSchedule, path, err)
+	}
+	return New(doc.Rules)
+}
+
+// Fee returns the fee for amount on a wallet of tier. Without a rule for
+// the operation the fee is zero.
+func (s *Schedule) Fee(operation, tier string, amount int64) int64 {
+	rule, ok := s.rule(operation, tier)
+	if !ok || amount <= 0 {
+		return 0
+	}
+
+	// Split to keep amount*basisPoints from overflowing.
+	percent := amount/maxBasisPoints*rule.BasisPoints +
+		(amount%maxBasisPoints*rule.BasisPoints+maxBasisPoints-1)/maxBasisPoints
+	fee := rule.Fixed + percent
+	if fee < rule.Fixed {
+		fee = math.MaxInt64
+	}
+	fee = max(fee, rule.Min)
+	if rule.Max != 0 {
+		fee = min(fee, rule.Max)
+	}
+	return fee
+}
+
+// rule picks the rule of the tier, falling back to the default one.
+func (s *Schedule) rule(operation, tier string) (Rule, bool) {
+	if s == nil {
+		return Rule{}, false
+	}
+
+	var (
+		fallback Rule
+		found    bool
+	)
+	for _, r := range s.rules {
+		if r.Operation != operation {
+			continue
+		}
+		if r.Tier == tier {
+			return r, true
+		}
+		if r.Tier == "" {
+			fallback, found = r, true
+		}
+	}
+	return fallback, found
+}
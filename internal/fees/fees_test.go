@@ -0,0 +1,66 @@
+package fees
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFee_Rules(t *testing.T) {
+	schedule, err := New([]Rule{
+		{Operation: OperationWithdraw, Fixed: 10, BasisPoints: 50, Min: 20, Max: 1000},
+		{Operation: OperationWithdraw, Tier: "gold", BasisPoints: 25},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		operation, tier string
+		amount, want    int64
+	}{
+		"fixed plus percent":       {OperationWithdraw, "", 10000, 60},
+		"percent rounded up":       {OperationWithdraw, "", 10001, 61},
+		"raised to min":            {OperationWithdraw, "", 100, 20},
+		"lowered to max":           {OperationWithdraw, "", 1_000_000, 1000},
+		"tier rule":                {OperationWithdraw, "gold", 10000, 25},
+		"unknown tier, default":    {OperationWithdraw, "silver", 10000, 60},
+		"no rule for operation":    {OperationDeposit, "", 10000, 0},
+		"percent of a huge amount": {OperationWithdraw, "gold", math.MaxInt64, math.MaxInt64/10000*25 + 15},
+	} {
+		assert.Equal(t, tc.want, schedule.Fee(tc.operation, tc.tier, tc.amount), name)
+	}
+}
+
+func TestFee_NilSchedule_Free(t *testing.T) {
+	var schedule *Schedule
+	assert.Zero(t, schedule.Fee(OperationWithdraw, "", 100))
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for name, rules := range map[string][]Rule{
+		"unknown operation": {{Operation: "TRANSFER"}},
+		"negative fixed":    {{Operation: OperationWithdraw, Fixed: -1}},
+		"over 100%":         {{Operation: OperationWithdraw, BasisPoints: 10001}},
+		"max below min":     {{Operation: OperationWithdraw, Min: 10, Max: 5}},
+		"duplicate":         {{Operation: OperationWithdraw, Tier: "gold"}, {Operation: OperationWithdraw, Tier: "gold", Fixed: 1}},
+	} {
+		_, err := New(rules)
+		assert.ErrorIs(t, err, ErrInvalidSchedule, name)
+	}
+}
+
+func TestLoad_UnknownField_Rejected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fees.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [{"operation": "WITHDRAW", "percent": 1}]}`), 0o600))
+
+	_, err := Load(path)
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules": [{"operation": "WITHDRAW", "fixed": 5}]}`), 0o600))
+	schedule, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), schedule.Fee(OperationWithdraw, "", 1))
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// adminAuthMiddleware lets through requests bearing the token of one of
+// actors and records which one made the request.
+func (h *Handler) adminAuthMiddleware(actors ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		actor := ""
+		if ok {
+			actor = h.tokenActor(token, actors)
+		}
+		if actor == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			_ = c.Error(ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{
+			ID:       actor,
+			SourceIP: c.ClientIP(),
+		}))
+		c.Next()
+	}
+}
+
+func (h *Handler) tokenActor(token string, actors []string) string {
+	for _, actor := range actors {
+		expected := h.adminToken
+		if actor == audit.Auditor {
+			expected = h.auditorToken
+		}
+		if expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			return actor
+		}
+	}
+	return ""
+}
+
+func (h *Handler) GetMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, h.maintenanceResponse())
+}
+
+func (h *Handler) SetMaintenance(c *gin.Context) {
+	var in SetMaintenanceRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(newBindError(err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	action := domain.AuditMaintenanceDisable
+	if *in.Enabled {
+		action = domain.AuditMaintenanceEnable
+	}
+
+	outcome := "unchanged"
+	if h.maintenance.Set(*in.Enabled) {
+		outcome = "success"
+		h.log.InfoContext(ctx, "maintenance mode changed",
+			slog.Bool("enabled", *in.Enabled),
+			slog.String("source", "api"),
+		)
+	}
+	h.auditAdminAction(ctx, action, outcome)
+
+	c.JSON(http.StatusOK, h.maintenanceResponse())
+}
+
+// SetTier moves a wallet to the fee tier given in the body.
+func (h *Handler) SetTier(c *gin.Context) {
+	if h.services.Tier == nil {
+		_ = c.Error(ErrTiersUnsupported)
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	var in SetTierRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(newBindError(err))
+		return
+	}
+
+	if err := h.services.Tier.SetTier(c.Request.Context(), walletID, in.Tier); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &TierResponse{WalletID: walletID.String(), Tier: in.Tier})
+}
+
+func (h *Handler) maintenanceResponse() *MaintenanceResponse {
+	state := h.maintenance.State()
+	return &MaintenanceResponse{
+		Enabled:           state.Enabled,
+		Since:             state.Since,
+		RetryAfterSeconds: retryAfterSeconds(h.maintenance.RetryAfter()),
+	}
+}
+
+// auditAdminAction records an action that has already taken effect, so a
+// failure to record it is only reported.
+func (h *Handler) auditAdminAction(ctx context.Context, action, outcome string) {
+	if h.services.Audit == nil {
+		return
+	}
+	if err := h.services.Audit.Record(ctx, action, outcome); err != nil {
+		metrics.AuditWriteFailures.Inc()
+		h.log.ErrorContext(ctx, "failed to audit admin action", slog.String("action", action), logger.Err(err))
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import "time"
+
+type SetMaintenanceRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+type MaintenanceResponse struct {
+	Enabled           bool      `json:"enabled"`
+	Since             time.Time `json:"since"`
+	RetryAfterSeconds int       `json:"retryAfterSeconds"`
+}
+
+type SetTierRequest struct {
+	Tier string `json:"tier" binding:"required,max=64"`
+}
+
+type TierResponse struct {
+	WalletID string `json:"walletId"`
+	Tier     string `json:"tier"`
+}
+
+type AuditRecordResponse struct {
+	ID            int64     `json:"id"`
+	At            time.Time `json:"at"`
+	Actor         string    `json:"actor"`
+	SourceIP      string    `json:"sourceIp"`
+	RequestID     string    `json:"requestId"`
+	Action        string    `json:"action"`
+	Outcome       string    `json:"outcome"`
+	WalletID      *string   `json:"walletId,omitempty"`
+	BalanceBefore *int64    `json:"balanceBefore,omitempty"`
+	BalanceAfter  *int64    `json:"balanceAfter,omitempty"`
+}
+
+type AuditLogResponse struct {
+	Records []AuditRecordResponse `json:"records"`
+	// NextBefore is passed as before to fetch the next page; it is absent
+	// when the page was not full.
+	NextBefore *int64 `json:"nextBefore,omitempty"`
+}
+
+type ChainReportResponse struct {
+	Wallets      int                 `json:"wallets"`
+	Transactions int64               `json:"transactions"`
+	Intact       bool                `json:"intact"`
+	Broken       *ChainBreakResponse `json:"broken,omitempty"`
+}
+
+type ChainBreakResponse struct {
+	WalletID string `json:"walletId"`
+	Seq      int64  `json:"seq"`
+	Reason   string `json:"reason"`
+}
@@ -0,0 +1,9 @@
+package handler
+
+import "errors"
+
+var (
+	ErrUnauthorized = errors.New("missing or invalid admin token")
+	// ErrTiersUnsupported is returned on a storage without fee tiers.
+	ErrTiersUnsupported = errors.New("fee tiers are not supported by this storage")
+)
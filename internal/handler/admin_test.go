@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/maintenance"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const testAdminToken = "secret"
+
+func TestGetMaintenance_NoToken_401(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/maintenance", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, CodeUnauthorized, problem.Code)
+}
+
+func TestGetMaintenance_AdminTokenNotConfigured_401(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/maintenance", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSetMaintenance_Enable_MutationsRejectedReadsServed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	wallet, err := domain.NewWallet(id, 100)
+	assert.NoError(t, err)
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Get(gomock.Any(), id).
+		Return(wallet, nil)
+
+	mode := maintenance.New(false, 30*time.Second)
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger,
+		WithMaintenance(mode),
+		WithAdminToken(testAdminToken),
+	)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/maintenance", getBodyReader(t, map[string]interface{}{
+		"enabled": true,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var resp MaintenanceResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, resp.Enabled)
+	assert.Equal(t, 30, resp.RetryAfterSeconds)
+	assert.True(t, mode.Enabled())
+
+	// изменения баланса отклоняются, сервис не вызывается
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
+		"walletId":      id.String(),
+		"operationType": "DEPOSIT",
+		"amount":        10,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var errResp ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, maintenance.ErrReadOnly.Error(), errResp.Message)
+
+	// чтение продолжает работать
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String(), nil)
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSetMaintenance_MissingEnabled_400(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/maintenance", getBodyReader(t, map[string]interface{}{}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSetTier_UnknownWallet_404(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	mockTier := mock_service.NewMockTier(ctrl)
+	mockTier.
+		EXPECT().
+		SetTier(gomock.Any(), id, "gold").
+		Return(domain.ErrWalletNotFound)
+
+	h := NewHandler(&service.Service{Tier: mockTier}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/wallets/"+id.String()+"/tier", getBodyReader(t, map[string]interface{}{"tier": "gold"}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, CodeWalletNotFound, problem.Code)
+}
+
+func TestSetTier_Set_200(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	mockTier := mock_service.NewMockTier(ctrl)
+	mockTier.
+		EXPECT().
+		SetTier(gomock.Any(), id, "gold").
+		Return(nil)
+
+	h := NewHandler(&service.Service{Tier: mockTier}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/wallets/"+id.String()+"/tier", getBodyReader(t, map[string]interface{}{"tier": "gold"}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var resp TierResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, TierResponse{WalletID: id.String(), Tier: "gold"}, resp)
+}
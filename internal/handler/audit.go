@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAuditFilter = errors.New("invalid audit log filter")
+	// ErrAuditUnsupported is returned on a storage without an audit log.
+	ErrAuditUnsupported = errors.New("audit log is not supported by this storage")
+)
+
+func (h *Handler) ListAudit(c *gin.Context) {
+	if h.services.Audit == nil {
+		_ = c.Error(ErrAuditUnsupported)
+		return
+	}
+
+	filter, fields := parseAuditFilter(c)
+	if len(fields) > 0 {
+		_ = c.Error(&APIError{
+			Status: http.StatusBadRequest,
+			Code:   CodeValidationFailed,
+			Title:  "Validation failed",
+			Detail: ErrInvalidAuditFilter.Error(),
+			Fields: fields,
+			Err:    ErrInvalidAuditFilter,
+		})
+		return
+	}
+
+	records, err := h.services.Audit.List(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp := &AuditLogResponse{Records: make([]AuditRecordResponse, 0, len(records))}
+	for _, record := range records {
+		item := AuditRecordResponse{
+			ID:            record.ID,
+			At:            record.At,
+			Actor:         record.Actor,
+			SourceIP:      record.SourceIP,
+			RequestID:     record.RequestID,
+			Action:        record.Action,
+			Outcome:       record.Outcome,
+			BalanceBefore: record.BalanceBefore,
+			BalanceAfter:  record.BalanceAfter,
+		}
+		if record.WalletID != nil {
+			id := record.WalletID.String()
+			item.WalletID = &id
+		}
+		resp.Records = append(resp.Records, item)
+	}
+	if len(records) > 0 && len(records) == filter.Limit {
+		next := records[len(records)-1].ID
+		resp.NextBefore = &next
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseAuditFilter reads the query of GET /admin/audit and reports every
+// invalid parameter at once.
+func parseAuditFilter(c *gin.Context) (repository.AuditFilter, []FieldError) {
+	filter := repository.AuditFilter{
+		Actor:   c.Query("actor"),
+		Action:  c.Query("action"),
+		Outcome: c.Query("outcome"),
+		Limit:   service.DefaultAuditLimit,
+	}
+	var fields []FieldError
+
+	if s := c.Query("walletId"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			fields = append(fields, FieldError{Field: "walletId", Reason: "must be a UUID"})
+		} else {
+			filter.WalletID = &id
+		}
+	}
+	for _, param := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		s := c.Query(param.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			fields = append(fields, FieldError{Field: param.name, Reason: "must be an RFC 3339 time"})
+			continue
+		}
+		*param.dst = t
+	}
+	if s := c.Query("before"); s != "" {
+		before, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || before <= 0 {
+			fields = append(fields, FieldError{Field: "before", Reason: "must be a positive record id"})
+		} else {
+			filter.BeforeID = before
+		}
+	}
+	if s := c.Query("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > service.MaxAuditLimit {
+			fields = append(fields, FieldError{Field: "limit", Reason: "must be between 1 and " + strconv.Itoa(service.MaxAuditLimit)})
+		} else {
+			filter.Limit = limit
+		}
+	}
+
+	return filter, fields
+}
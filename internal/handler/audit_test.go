@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testAuditorToken = "auditor-secret"
+
+func TestListAudit_AuditorToken_FilteredRecords(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	before, after := int64(100), int64(70)
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	mockAudit := mock_service.NewMockAudit(ctrl)
+	mockAudit.
+		EXPECT().
+		List(gomock.Any(), repository.AuditFilter{
+			WalletID: &walletID,
+			Outcome:  "success",
+			From:     at.Add(-time.Hour),
+			BeforeID: 50,
+			Limit:    1,
+		}).
+		Return([]domain.AuditRecord{{
+			ID:            42,
+			At:            at,
+			Actor:         "alice",
+			SourceIP:      "203.0.113.7",
+			RequestID:     "req-1",
+			Action:        domain.AuditWithdraw,
+			Outcome:       "success",
+			WalletID:      &walletID,
+			BalanceBefore: &before,
+			BalanceAfter:  &after,
+		}}, nil)
+
+	h := NewHandler(&service.Service{Audit: mockAudit}, testHealth, testLogger,
+		WithAdminToken(testAdminToken),
+		WithAuditorToken(testAuditorToken),
+	)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit?walletId="+walletID.String()+
+		"&outcome=success&from=2026-10-19T11:00:00Z&before=50&limit=1", nil)
+	req.Header.Set("Authorization", "Bearer "+testAuditorToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var resp AuditLogResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, resp.Records, 1)
+	assert.Equal(t, "alice", resp.Records[0].Actor)
+	assert.Equal(t, walletID.String(), *resp.Records[0].WalletID)
+	assert.Equal(t, int64(70), *resp.Records[0].BalanceAfter)
+	// Страница заполнена, значит может быть следующая
+	require.NotNil(t, resp.NextBefore)
+	assert.Equal(t, int64(42), *resp.NextBefore)
+}
+
+func TestListAudit_InvalidFilter_400(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewHandler(&service.Service{Audit: mock_service.NewMockAudit(ctrl)}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit?walletId=nope&from=yesterday&limit=0", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CodeValidationFailed, problem.Code)
+
+	var fields []string
+	for _, f := range problem.Errors {
+		fields = append(fields, f.Field)
+	}
+	assert.ElementsMatch(t, []string{"walletId", "from", "limit"}, fields)
+}
+
+func TestListAudit_Unsupported_501(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.Equal(t, CodeNotImplemented, problem.Code)
+}
+
+func TestSetMaintenance_AuditorToken_401(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger,
+		WithAdminToken(testAdminToken),
+		WithAuditorToken(testAuditorToken),
+	)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/maintenance", getBodyReader(t, map[string]interface{}{
+		"enabled": true,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAuditorToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSetMaintenance_Enable_AuditedAsAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAudit := mock_service.NewMockAudit(ctrl)
+	mockAudit.
+		EXPECT().
+		Record(gomock.Any(), domain.AuditMaintenanceEnable, "success").
+		DoAndReturn(func(ctx context.Context, _, _ string) error {
+			assert.Equal(t, audit.Admin, audit.ActorFrom(ctx).ID)
+			return nil
+		})
+
+	h := NewHandler(&service.Service{Audit: mockAudit}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/maintenance", getBodyReader(t, map[string]interface{}{
+		"enabled": true,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateWallet_ActorHeader_PassedToService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	wallet, err := domain.NewWallet(uuid.New(), 10)
+	assert.NoError(t, err)
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Deposit(gomock.Any(), wallet.ID(), int64(10)).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID, _ int64) (*domain.Receipt, error) {
+			actor := audit.ActorFrom(ctx)
+			assert.Equal(t, "billing-gateway", actor.ID)
+			assert.Equal(t, "192.0.2.10", actor.SourceIP)
+			return &domain.Receipt{Wallet: wallet}, nil
+		})
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
+		"walletId":      wallet.ID().String(),
+		"operationType": "DEPOSIT",
+		"amount":        10,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerActor, "billing-gateway")
+	req.RemoteAddr = "192.0.2.10:51234"
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateWallet_ForwardedFor_TrustedOnlyFromProxies(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts     []Option
+		sourceIP string
+	}{
+		"untrusted peer": {sourceIP: "192.0.2.10"},
+		"trusted proxy":  {opts: []Option{WithTrustedProxies([]string{"192.0.2.0/24"})}, sourceIP: "198.51.100.7"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			wallet, err := domain.NewWallet(uuid.New(), 10)
+			assert.NoError(t, err)
+
+			mockWallet := mock_service.NewMockWallet(ctrl)
+			mockWallet.
+				EXPECT().
+				Deposit(gomock.Any(), wallet.ID(), int64(10)).
+				DoAndReturn(func(ctx context.Context, _ uuid.UUID, _ int64) (*domain.Receipt, error) {
+					assert.Equal(t, tc.sourceIP, audit.ActorFrom(ctx).SourceIP)
+					return &domain.Receipt{Wallet: wallet}, nil
+				})
+
+			h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger, tc.opts...)
+			router := setupRouter(h)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
+				"walletId":      wallet.ID().String(),
+				"operationType": "DEPOSIT",
+				"amount":        10,
+			}))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Forwarded-For", "198.51.100.7")
+			req.RemoteAddr = "192.0.2.10:51234"
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
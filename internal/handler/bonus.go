@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"wallet-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ErrBonusesUnsupported is returned on a storage without bonus balances.
+var ErrBonusesUnsupported = errors.New("bonuses are not supported by this storage")
+
+// GrantBonus deposits bonus credit that expires to a wallet.
+func (h *Handler) GrantBonus(c *gin.Context) {
+	if h.services.Bonus == nil {
+		_ = c.Error(ErrBonusesUnsupported)
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	var in GrantBonusRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(newBindError(err))
+		return
+	}
+
+	receipt, err := h.services.Bonus.Grant(c.Request.Context(), walletID, in.Amount, in.ExpiresAt)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toGetWalletResponse(receipt.Wallet))
+
+	receipt.Wallet.Release()
+}
+
+// toGetWalletResponse reports the current balance of wallet with its
+// breakdown into cash and bonus.
+func toGetWalletResponse(wallet *domain.Wallet) *GetWalletResponse {
+	cash, bonus := wallet.Cash(), wallet.Bonus()
+	out := &GetWalletResponse{
+		WalletID: wallet.ID().String(),
+		Balance:  wallet.Balance(),
+		Cash:     &cash,
+		Bonus:    &bonus,
+	}
+	for _, b := range wallet.Bonuses() {
+		out.Bonuses = append(out.Bonuses, BonusResponse{Amount: b.Amount, ExpiresAt: b.ExpiresAt})
+	}
+	return out
+}
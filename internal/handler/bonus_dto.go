@@ -0,0 +1,13 @@
+package handler
+
+import "time"
+
+type GrantBonusRequest struct {
+	Amount    int64     `json:"amount" binding:"required,gt=0"`
+	ExpiresAt time.Time `json:"expiresAt" binding:"required"`
+}
+
+type BonusResponse struct {
+	Amount    int64     `json:"amount"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
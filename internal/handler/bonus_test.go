@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/maintenance"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetWallet_BonusBreakdown_200(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	expiresAt := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	wallet, err := domain.NewWallet(id, 1000)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SetBonuses([]domain.Bonus{{ID: uuid.New(), Amount: 300, ExpiresAt: expiresAt}}))
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Get(gomock.Any(), id).
+		Return(wallet, nil)
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+id.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp GetWalletResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(1000), resp.Balance)
+	require.NotNil(t, resp.Cash)
+	require.NotNil(t, resp.Bonus)
+	assert.Equal(t, int64(700), *resp.Cash)
+	assert.Equal(t, int64(300), *resp.Bonus)
+	assert.Equal(t, []BonusResponse{{Amount: 300, ExpiresAt: expiresAt}}, resp.Bonuses)
+}
+
+func TestGrantBonus_200(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	expiresAt := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	wallet, err := domain.NewWallet(id, 500)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SetBonuses([]domain.Bonus{{ID: uuid.New(), Amount: 500, ExpiresAt: expiresAt}}))
+	mockBonus := mock_service.NewMockBonus(ctrl)
+	mockBonus.
+		EXPECT().
+		Grant(gomock.Any(), id, int64(500), expiresAt).
+		Return(&domain.Receipt{Wallet: wallet}, nil)
+
+	h := NewHandler(&service.Service{Bonus: mockBonus}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/wallets/"+id.String()+"/bonuses", getBodyReader(t, map[string]interface{}{"amount": 500, "expiresAt": expiresAt}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp GetWalletResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Bonus)
+	assert.Equal(t, int64(500), *resp.Bonus)
+}
+
+func TestGrantBonus_PastExpiry_400(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	mockBonus := mock_service.NewMockBonus(ctrl)
+	mockBonus.
+		EXPECT().
+		Grant(gomock.Any(), id, int64(500), gomock.Any()).
+		Return(nil, domain.ErrBonusExpiry)
+
+	h := NewHandler(&service.Service{Bonus: mockBonus}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/wallets/"+id.String()+"/bonuses", getBodyReader(t, map[string]interface{}{"amount": 500, "expiresAt": "2020-01-01T00:00:00Z"}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeValidationFailed, problem.Code)
+}
+
+func TestGrantBonus_Maintenance_503(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBonus := mock_service.NewMockBonus(ctrl)
+	h := NewHandler(&service.Service{Bonus: mockBonus}, testHealth, testLogger,
+		WithMaintenance(maintenance.New(true, 30*time.Second)),
+		WithAdminToken(testAdminToken),
+	)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/wallets/"+uuid.NewString()+"/bonuses", getBodyReader(t, map[string]interface{}{"amount": 500, "expiresAt": "2030-01-01T00:00:00Z"}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+}
+
+func TestGrantBonus_Unsupported_501(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/wallets/"+uuid.NewString()+"/bonuses", getBodyReader(t, map[string]interface{}{"amount": 500, "expiresAt": "2030-01-01T00:00:00Z"}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
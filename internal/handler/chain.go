@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidChainFilter = errors.New("invalid chain verification filter")
+	// ErrLedgerUnsupported is returned on a storage without a transaction
+	// ledger.
+	ErrLedgerUnsupported = errors.New("transaction ledger is not supported by this storage")
+)
+
+func (h *Handler) VerifyChain(c *gin.Context) {
+	if h.services.Chain == nil {
+		_ = c.Error(ErrLedgerUnsupported)
+		return
+	}
+
+	var walletID *uuid.UUID
+	if s := c.Query("walletId"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			_ = c.Error(&APIError{
+				Status: http.StatusBadRequest,
+				Code:   CodeValidationFailed,
+				Title:  "Validation failed",
+				Detail: ErrInvalidChainFilter.Error(),
+				Fields: []FieldError{{Field: "walletId", Reason: "must be a UUID"}},
+				Err:    ErrInvalidChainFilter,
+			})
+			return
+		}
+		walletID = &id
+	}
+
+	report, err := h.services.Chain.Verify(c.Request.Context(), walletID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp := &ChainReportResponse{
+		Wallets:      report.Wallets,
+		Transactions: report.Transactions,
+		Intact:       report.Break == nil,
+	}
+	if report.Break != nil {
+		resp.Broken = &ChainBreakResponse{
+			WalletID: report.Break.WalletID.String(),
+			Seq:      report.Break.Seq,
+			Reason:   report.Break.Reason,
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestVerifyChain_AuditorToken_ReportsBreak(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	mockChain := mock_service.NewMockChain(ctrl)
+	mockChain.
+		EXPECT().
+		Verify(gomock.Any(), &walletID).
+		Return(&domain.ChainReport{
+			Wallets:      1,
+			Transactions: 4,
+			Break:        &domain.ChainBreak{WalletID: walletID, Seq: 5, Reason: "hash does not match the content"},
+		}, nil)
+
+	h := NewHandler(&service.Service{Chain: mockChain}, testHealth, testLogger, WithAuditorToken(testAuditorToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/chain/verify?walletId="+walletID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+testAuditorToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var resp ChainReportResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, resp.Intact)
+	assert.Equal(t, int64(4), resp.Transactions)
+	require.NotNil(t, resp.Broken)
+	assert.Equal(t, walletID.String(), resp.Broken.WalletID)
+	assert.Equal(t, int64(5), resp.Broken.Seq)
+}
+
+func TestVerifyChain_InvalidWalletID_400(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewHandler(&service.Service{Chain: mock_service.NewMockChain(ctrl)}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/chain/verify?walletId=nope", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "walletId", problem.Errors[0].Field)
+}
+
+func TestVerifyChain_NoToken_401(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/chain/verify", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestVerifyChain_Unsupported_501(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/chain/verify", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.Equal(t, CodeNotImplemented, problem.Code)
+}
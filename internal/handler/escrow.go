@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrEscrowsUnsupported is returned on a storage without escrows.
+var ErrEscrowsUnsupported = errors.New("escrows are not supported by this storage")
+
+// CreateEscrow takes funds from the buyer wallet into a new escrow.
+func (h *Handler) CreateEscrow(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.CreateEscrow")
+	defer span.End()
+
+	if h.services.Escrow == nil {
+		_ = c.Error(ErrEscrowsUnsupported)
+		return
+	}
+
+	var in CreateEscrowRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(newBindError(err))
+		return
+	}
+
+	buyerID, err := parseWalletField("buyerWalletId", in.BuyerWalletID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	sellerID, err := parseWalletField("sellerWalletId", in.SellerWalletID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	escrow, err := h.services.Escrow.Create(ctx, buyerID, sellerID, in.Amount, in.ExpiresAt)
+	if err != nil {
+		tracing.Fail(span, err)
+		_ = c.Error(err)
+		return
+	}
+	span.SetAttributes(attribute.String("escrow.id", escrow.ID.String()))
+
+	c.JSON(http.StatusCreated, toEscrowResponse(escrow, nil))
+}
+
+// GetEscrow returns an escrow with its transitions.
+func (h *Handler) GetEscrow(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.GetEscrow")
+	defer span.End()
+
+	if h.services.Escrow == nil {
+		_ = c.Error(ErrEscrowsUnsupported)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	escrow, err := h.services.Escrow.Get(ctx, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	transitions, err := h.services.Escrow.Transitions(ctx, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toEscrowResponse(escrow, transitions))
+}
+
+// ConfirmEscrow records the confirmation of one side; the second one, from
+// another X-Actor-ID, releases the funds to the seller. The party is taken
+// from the body as is: the gateway must check that it belongs to the caller.
+func (h *Handler) ConfirmEscrow(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.ConfirmEscrow")
+	defer span.End()
+
+	if h.services.Escrow == nil {
+		_ = c.Error(ErrEscrowsUnsupported)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	var in ConfirmEscrowRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(newBindError(err))
+		return
+	}
+
+	escrow, err := h.services.Escrow.Confirm(ctx, id, in.Party)
+	if err != nil {
+		tracing.Fail(span, err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toEscrowResponse(escrow, nil))
+}
+
+// CancelEscrow refunds the funds to the buyer.
+func (h *Handler) CancelEscrow(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.CancelEscrow")
+	defer span.End()
+
+	if h.services.Escrow == nil {
+		_ = c.Error(ErrEscrowsUnsupported)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	escrow, err := h.services.Escrow.Cancel(ctx, id)
+	if err != nil {
+		tracing.Fail(span, err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toEscrowResponse(escrow, nil))
+}
+
+func toEscrowResponse(escrow *domain.Escrow, transitions []domain.EscrowTransition) *EscrowResponse {
+	resp := &EscrowResponse{
+		ID:             escrow.ID.String(),
+		BuyerWalletID:  escrow.BuyerID.String(),
+		SellerWalletID: escrow.SellerID.String(),
+		Amount:         escrow.Amount,
+		Status:         escrow.Status,
+		ExpiresAt:      escrow.ExpiresAt,
+		CreatedAt:      escrow.CreatedAt,
+		UpdatedAt:      escrow.UpdatedAt,
+	}
+	for _, t := range transitions {
+		resp.Transitions = append(resp.Transitions, EscrowTransitionResponse{
+			Event: t.Event,
+			From:  t.From,
+			To:    t.To,
+			Actor: t.Actor,
+			At:    t.At,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,36 @@
+package handler
+
+import "time"
+
+type CreateEscrowRequest struct {
+	BuyerWalletID  string    `json:"buyerWalletId" binding:"required"`
+	SellerWalletID string    `json:"sellerWalletId" binding:"required"`
+	Amount         int64     `json:"amount" binding:"required,gt=0"`
+	ExpiresAt      time.Time `json:"expiresAt" binding:"required"`
+}
+
+type ConfirmEscrowRequest struct {
+	Party string `json:"party" binding:"required,oneof=buyer seller"`
+}
+
+type EscrowResponse struct {
+	ID             string    `json:"id"`
+	BuyerWalletID  string    `json:"buyerWalletId"`
+	SellerWalletID string    `json:"sellerWalletId"`
+	Amount         int64     `json:"amount"`
+	Status         string    `json:"status"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	// Transitions are only listed by GetEscrow.
+	Transitions []EscrowTransitionResponse `json:"transitions,omitempty"`
+}
+
+type EscrowTransitionResponse struct {
+	Event string `json:"event"`
+	// From is empty for the transition that created the escrow.
+	From  string    `json:"from,omitempty"`
+	To    string    `json:"to"`
+	Actor string    `json:"actor"`
+	At    time.Time `json:"at"`
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateEscrow_201(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	buyer, seller := uuid.New(), uuid.New()
+	expiresAt := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	escrow := &domain.Escrow{ID: uuid.New(), BuyerID: buyer, SellerID: seller, Amount: 500, Status: domain.EscrowHeld, ExpiresAt: expiresAt}
+	mockEscrow := mock_service.NewMockEscrow(ctrl)
+	mockEscrow.
+		EXPECT().
+		Create(gomock.Any(), buyer, seller, int64(500), expiresAt).
+		Return(escrow, nil)
+
+	h := NewHandler(&service.Service{Escrow: mockEscrow}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/escrows", getBodyReader(t, map[string]interface{}{
+		"buyerWalletId": buyer, "sellerWalletId": seller, "amount": 500, "expiresAt": expiresAt,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp EscrowResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, escrow.ID.String(), resp.ID)
+	assert.Equal(t, domain.EscrowHeld, resp.Status)
+}
+
+func TestGetEscrow_Transitions_200(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	at := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	mockEscrow := mock_service.NewMockEscrow(ctrl)
+	mockEscrow.
+		EXPECT().
+		Get(gomock.Any(), id).
+		Return(&domain.Escrow{ID: id, Amount: 500, Status: domain.EscrowBuyerConfirmed}, nil)
+	mockEscrow.
+		EXPECT().
+		Transitions(gomock.Any(), id).
+		Return([]domain.EscrowTransition{
+			{EscrowID: id, Event: domain.EscrowCreate, To: domain.EscrowHeld, Actor: "system", At: at},
+			{EscrowID: id, Event: domain.EscrowConfirmBuyer, From: domain.EscrowHeld, To: domain.EscrowBuyerConfirmed, Actor: "system", At: at},
+		}, nil)
+
+	h := NewHandler(&service.Service{Escrow: mockEscrow}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/escrows/"+id.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp EscrowResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Transitions, 2)
+	assert.Empty(t, resp.Transitions[0].From)
+	assert.Equal(t, domain.EscrowBuyerConfirmed, resp.Transitions[1].To)
+}
+
+func TestConfirmEscrow_NotAllowed_409(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	mockEscrow := mock_service.NewMockEscrow(ctrl)
+	mockEscrow.
+		EXPECT().
+		Confirm(gomock.Any(), id, domain.EscrowSeller).
+		Return(nil, domain.ErrEscrowTransition)
+
+	h := NewHandler(&service.Service{Escrow: mockEscrow}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/escrows/"+id.String()+"/confirm", getBodyReader(t, map[string]interface{}{"party": "seller"}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeEscrowNotAllowed, problem.Code)
+}
+
+func TestConfirmEscrow_InvalidParty_400(t *testing.T) {
+	h := NewHandler(&service.Service{Escrow: mock_service.NewMockEscrow(gomock.NewController(t))}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/escrows/"+uuid.NewString()+"/confirm", getBodyReader(t, map[string]interface{}{"party": "broker"}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCancelEscrow_NotFound_404(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	mockEscrow := mock_service.NewMockEscrow(ctrl)
+	mockEscrow.
+		EXPECT().
+		Cancel(gomock.Any(), id).
+		Return(nil, domain.ErrEscrowNotFound)
+
+	h := NewHandler(&service.Service{Escrow: mockEscrow}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/escrows/"+id.String()+"/cancel", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCreateEscrow_Unsupported_501(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/escrows", getBodyReader(t, map[string]interface{}{
+		"buyerWalletId": uuid.New(), "sellerWalletId": uuid.New(), "amount": 500, "expiresAt": "2030-01-01T00:00:00Z",
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
@@ -1,39 +1,233 @@
 package handler
 
 import (
+	"log/slog"
+	"time"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/health"
+	"wallet-service/internal/maintenance"
 	"wallet-service/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const serviceName = "wallet-service"
+
 type Handler struct {
-	services *service.Service
+	services    *service.Service
+	health      *health.Health
+	maintenance *maintenance.Mode
+	log         *slog.Logger
+
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+	tracer         trace.Tracer
+
+	validateRequests bool
+	requestTimeout   time.Duration
+	adminToken       string
+	auditorToken     string
+	trustedProxies   []string
+}
+
+type Option func(h *Handler)
+
+// WithRequestValidation validates every documented request against the
+// OpenAPI specification before it reaches a handler.
+func WithRequestValidation(enabled bool) Option {
+	return func(h *Handler) {
+		h.validateRequests = enabled
+	}
+}
+
+// WithRequestTimeout sets the deadline for every /api request. Zero disables
+// it.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.requestTimeout = timeout
+	}
+}
+
+// WithTracing makes the handler trace with provider and read incoming trace
+// context with propagator instead of the global ones.
+func WithTracing(provider trace.TracerProvider, propagator propagation.TextMapPropagator) Option {
+	return func(h *Handler) {
+		h.tracerProvider = provider
+		h.propagator = propagator
+	}
+}
+
+// WithTrustedProxies believes X-Forwarded-For only from proxies, given as
+// addresses or CIDRs. Without it the client address is the peer address, so
+// a client cannot choose the source IP recorded in the audit log.
+func WithTrustedProxies(proxies []string) Option {
+	return func(h *Handler) {
+		h.trustedProxies = proxies
+	}
 }
 
-func NewHandler(service *service.Service) *Handler {
-	return &Handler{
-		services: service,
+// WithMaintenance shares the read-only switch with the rest of the process.
+// Without it the handler keeps its own, initially disabled, switch.
+func WithMaintenance(mode *maintenance.Mode) Option {
+	return func(h *Handler) {
+		h.maintenance = mode
 	}
 }
 
+// WithAdminToken enables the /admin routes for requests bearing token.
+func WithAdminToken(token string) Option {
+	return func(h *Handler) {
+		h.adminToken = token
+	}
+}
+
+// WithAuditorToken lets requests bearing token read the audit log and nothing
+// else under /admin.
+func WithAuditorToken(token string) Option {
+	return func(h *Handler) {
+		h.auditorToken = token
+	}
+}
+
+func NewHandler(service *service.Service, health *health.Health, log *slog.Logger, opts ...Option) *Handler {
+	h := &Handler{
+		services:    service,
+		health:      health,
+		maintenance: maintenance.New(false, time.Minute),
+		log:         log,
+
+		tracerProvider: otel.GetTracerProvider(),
+		propagator:     otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.tracer = h.tracerProvider.Tracer("wallet-service/internal/handler")
+	return h
+}
+
 func (h *Handler) GetRouter() *gin.Engine {
-	r := gin.Default()
+	router, err := h.Router()
+	if err != nil {
+		panic(err)
+	}
+	return router
+}
 
-	api := r.Group("/api")
-	{
-		v1 := api.Group("v1")
-		{
-			wallet := v1.Group("/wallet")
-			{
-				wallet.POST("", h.UpdateWallet)
-			}
-
-			wallets := v1.Group("/wallets")
-			{
-				wallets.GET("/:id", h.GetWallet)
-			}
+func (h *Handler) Router() (*gin.Engine, error) {
+	useJSONFieldNames()
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(h.trustedProxies); err != nil {
+		return nil, err
+	}
+	r.Use(
+		requestIDMiddleware(),
+		h.recoveryMiddleware(),
+		otelgin.Middleware(serviceName,
+			otelgin.WithTracerProvider(h.tracerProvider),
+			otelgin.WithPropagators(h.propagator),
+			otelgin.WithGinFilter(func(c *gin.Context) bool {
+				return !isProbeRoute(c.FullPath())
+			}),
+		),
+		metricsMiddleware(),
+		h.accessLogMiddleware(),
+		h.errorMiddleware(),
+	)
+
+	if h.validateRequests {
+		doc, err := loadOpenAPI()
+		if err != nil {
+			return nil, err
 		}
+		validator, err := requestValidationMiddleware(doc)
+		if err != nil {
+			return nil, err
+		}
+		r.Use(validator)
+	}
+
+	r.NoRoute(func(c *gin.Context) {
+		_ = c.Error(ErrRouteNotFound)
+	})
+
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET("/healthz", h.Liveness)
+	r.GET("/readyz", h.Readiness)
+
+	admin := r.Group("/admin")
+	{
+		admin.GET("/maintenance", h.adminAuthMiddleware(audit.Admin), h.GetMaintenance)
+		admin.PUT("/maintenance", h.adminAuthMiddleware(audit.Admin), h.SetMaintenance)
+		admin.GET("/audit", h.adminAuthMiddleware(audit.Admin, audit.Auditor), h.ListAudit)
+		admin.GET("/chain/verify", h.adminAuthMiddleware(audit.Admin, audit.Auditor), h.VerifyChain)
 	}
 
-	return r
+	// Admin writes to wallets obey maintenance like the public API does;
+	// PUT /admin/maintenance stays outside so read-only mode can be lifted.
+	adminWallets := admin.Group("/wallets", h.adminAuthMiddleware(audit.Admin), h.maintenanceMiddleware())
+	{
+		adminWallets.PUT("/:id/tier", h.SetTier)
+		adminWallets.PUT("/:id/interest", h.SetInterestRate)
+		adminWallets.POST("/:id/bonuses", h.GrantBonus)
+	}
+
+	api := r.Group("/api", actorMiddleware(), h.maintenanceMiddleware(), timeoutMiddleware(h.requestTimeout))
+	{
+		api.GET("/openapi.json", h.OpenAPISpec)
+		api.GET("/docs", h.Docs)
+
+		// v1 and v2 serve the same operations; v2 reports errors as
+		// application/problem+json while v1 keeps {"message": ...}.
+		h.registerWalletRoutes(api.Group("v1"))
+		h.registerWalletRoutes(api.Group("v2"))
+	}
+
+	return r, nil
+}
+
+func (h *Handler) registerWalletRoutes(version *gin.RouterGroup) {
+	wallet := version.Group("/wallet")
+	{
+		wallet.POST("", h.UpdateWallet)
+	}
+
+	wallets := version.Group("/wallets")
+	{
+		wallets.GET("/:id", h.GetWallet)
+		wallets.GET("/:id/statement", h.GetStatement)
+		wallets.GET("/:id/schedules", h.ListSchedules)
+		wallets.GET("/:id/interest", h.GetInterest)
+		wallets.POST("/:id/split", h.SplitPayment)
+	}
+
+	schedules := version.Group("/schedules")
+	{
+		schedules.POST("", h.CreateSchedule)
+		schedules.GET("/:id", h.GetSchedule)
+		schedules.DELETE("/:id", h.CancelSchedule)
+	}
+
+	escrows := version.Group("/escrows")
+	{
+		escrows.POST("", h.CreateEscrow)
+		escrows.GET("/:id", h.GetEscrow)
+		escrows.POST("/:id/confirm", h.ConfirmEscrow)
+		escrows.POST("/:id/cancel", h.CancelEscrow)
+	}
+}
+
+func isProbeRoute(route string) bool {
+	switch route {
+	case "/metrics", "/healthz", "/readyz":
+		return true
+	default:
+		return false
+	}
 }
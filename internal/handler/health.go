@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	statusOK       = "ok"
+	statusReady    = "ready"
+	statusNotReady = "not ready"
+)
+
+func (h *Handler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, &HealthResponse{Status: statusOK})
+}
+
+func (h *Handler) Readiness(c *gin.Context) {
+	report := h.health.Ready(c.Request.Context())
+
+	resp := &ReadinessResponse{
+		Status: statusReady,
+		Checks: make(map[string]string, len(report.Checks)),
+	}
+	for name, err := range report.Checks {
+		if err != nil {
+			resp.Checks[name] = err.Error()
+			continue
+		}
+		resp.Checks[name] = statusOK
+	}
+
+	if !report.Ready {
+		resp.Status = statusNotReady
+		c.JSON(http.StatusServiceUnavailable, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
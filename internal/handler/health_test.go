@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet-service/internal/health"
+	"wallet-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveness_Always_200(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadiness_ChecksPass_200(t *testing.T) {
+	hc := health.New(time.Second)
+	hc.Register("database", func(context.Context) error { return nil })
+
+	h := NewHandler(&service.Service{}, hc, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var resp ReadinessResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, statusOK, resp.Checks["database"])
+}
+
+func TestReadiness_CheckFails_503(t *testing.T) {
+	hc := health.New(time.Second)
+	hc.Register("database", func(context.Context) error { return errors.New("connection refused") })
+
+	h := NewHandler(&service.Service{}, hc, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var resp ReadinessResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "connection refused", resp.Checks["database"])
+}
+
+func TestReadiness_Draining_503(t *testing.T) {
+	hc := health.New(time.Second)
+
+	h := NewHandler(&service.Service{}, hc, testLogger)
+	router := setupRouter(h)
+
+	hc.StartDraining()
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"wallet-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrInterestUnsupported is returned on a storage without savings wallets.
+var ErrInterestUnsupported = errors.New("interest is not supported by this storage")
+
+func (h *Handler) GetInterest(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.GetInterest")
+	defer span.End()
+
+	if h.services.Interest == nil {
+		_ = c.Error(ErrInterestUnsupported)
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+
+	account, err := h.services.Interest.Get(ctx, walletID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toInterestResponse(account))
+}
+
+// SetInterestRate sets the annual interest rate of a wallet, making it a
+// savings wallet.
+func (h *Handler) SetInterestRate(c *gin.Context) {
+	if h.services.Interest == nil {
+		_ = c.Error(ErrInterestUnsupported)
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	var in SetInterestRateRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(newBindError(err))
+		return
+	}
+
+	account, err := h.services.Interest.SetRate(c.Request.Context(), walletID, *in.Rate)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toInterestResponse(account))
+}
+
+func toInterestResponse(account *domain.InterestAccount) *InterestResponse {
+	return &InterestResponse{
+		WalletID:      account.WalletID.String(),
+		Rate:          account.Rate,
+		Accrued:       account.Accrued,
+		NextAccrualAt: account.NextAccrualAt,
+		NextPayoutAt:  account.NextPayoutAt,
+	}
+}
@@ -0,0 +1,17 @@
+package handler
+
+import "time"
+
+type SetInterestRateRequest struct {
+	// Rate is the annual rate in basis points.
+	Rate *int64 `json:"rate" binding:"required,min=0,max=10000"`
+}
+
+type InterestResponse struct {
+	WalletID string `json:"walletId"`
+	Rate     int64  `json:"rate"`
+	// Accrued is the interest accrued and not paid out yet.
+	Accrued       int64     `json:"accrued"`
+	NextAccrualAt time.Time `json:"nextAccrualAt"`
+	NextPayoutAt  time.Time `json:"nextPayoutAt"`
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetInterest_200(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	mockInterest := mock_service.NewMockInterest(ctrl)
+	mockInterest.
+		EXPECT().
+		Get(gomock.Any(), id).
+		Return(&domain.InterestAccount{WalletID: id, Rate: 350, Accrued: 42, NextAccrualAt: day, NextPayoutAt: day.AddDate(0, 0, -18)}, nil)
+
+	h := NewHandler(&service.Service{Interest: mockInterest}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+id.String()+"/interest", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp InterestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(350), resp.Rate)
+	assert.Equal(t, int64(42), resp.Accrued)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), resp.NextPayoutAt)
+}
+
+func TestGetInterest_NoAccount_404(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	mockInterest := mock_service.NewMockInterest(ctrl)
+	mockInterest.
+		EXPECT().
+		Get(gomock.Any(), id).
+		Return(nil, domain.ErrInterestAccountNotFound)
+
+	h := NewHandler(&service.Service{Interest: mockInterest}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+id.String()+"/interest", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeInterestAccountNotFound, problem.Code)
+}
+
+func TestSetInterestRate_AboveMax_400(t *testing.T) {
+	// До сервиса запрос не доходит
+	h := NewHandler(&service.Service{Interest: mock_service.NewMockInterest(gomock.NewController(t))}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/wallets/"+uuid.NewString()+"/interest", getBodyReader(t, map[string]interface{}{"rate": 10001}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "rate", problem.Errors[0].Field)
+}
+
+func TestSetInterestRate_Unsupported_501(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger, WithAdminToken(testAdminToken))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/wallets/"+uuid.NewString()+"/interest", getBodyReader(t, map[string]interface{}{"rate": 350}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
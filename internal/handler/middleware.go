@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/maintenance"
+	"wallet-service/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	headerRequestID    = "X-Request-ID"
+	maxRequestIDLength = 128
+
+	// headerActor identifies the caller for the audit log. The gateway in
+	// front of the service is expected to set it after authentication.
+	headerActor    = "X-Actor-ID"
+	maxActorLength = 128
+)
+
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(headerRequestID)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+
+		c.Header(headerRequestID, id)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
+
+func actorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := c.GetHeader(headerActor)
+		if actor == "" || len(actor) > maxActorLength {
+			actor = audit.Anonymous
+		}
+
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{
+			ID:       actor,
+			SourceIP: c.ClientIP(),
+		}))
+
+		c.Next()
+	}
+}
+
+func (h *Handler) recoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		h.log.ErrorContext(c.Request.Context(), "panic recovered",
+			slog.Any("panic", recovered),
+			slog.String("stack", string(debug.Stack())),
+		)
+		h.renderError(c, toAPIError(fmt.Errorf("panic: %v", recovered)))
+	})
+}
+
+func (h *Handler) accessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		level := slog.LevelInfo
+		if isProbeRoute(c.FullPath()) {
+			level = slog.LevelDebug
+		}
+
+		h.log.Log(c.Request.Context(), level, "request completed",
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// timeoutMiddleware bounds the time a request may spend in the service and
+// database layers. Handlers observe the deadline through the request context.
+func timeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// maintenanceMiddleware refuses every mutating request while the service is
+// read-only; reads pass through.
+func (h *Handler) maintenanceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if h.maintenance.Enabled() {
+			apiErr := toAPIError(maintenance.ErrReadOnly)
+			apiErr.RetryAfter = h.maintenance.RetryAfter()
+			_ = c.Error(apiErr)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// errorMiddleware renders the last error attached with c.Error, so handlers
+// only need to report what went wrong and return.
+func (h *Handler) errorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		apiErr := toAPIError(c.Errors.Last().Err)
+		h.logFailure(c, apiErr)
+		h.renderError(c, apiErr)
+	}
+}
+
+func (h *Handler) renderError(c *gin.Context, apiErr *APIError) {
+	if apiErr.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(apiErr.RetryAfter)))
+	}
+	if wantsProblem(c) {
+		c.Header("Content-Type", contentTypeProblem)
+		c.AbortWithStatusJSON(apiErr.Status, apiErr.problem(c.Request.URL.Path, logger.RequestID(c.Request.Context())))
+		return
+	}
+
+	c.AbortWithStatusJSON(apiErr.Status, &ErrorResponse{Message: apiErr.Detail})
+}
+
+// wantsProblem reports whether the client gets RFC 7807 responses: all v2
+// routes do, v1 keeps the legacy {"message": ...} shape unless asked.
+func wantsProblem(c *gin.Context) bool {
+	if strings.HasPrefix(c.Request.URL.Path, "/api/v2/") || strings.HasPrefix(c.Request.URL.Path, "/admin/") {
+		return true
+	}
+	return strings.Contains(c.GetHeader("Accept"), contentTypeProblem)
+}
+
+// logFailure logs unexpected failures as errors and expected rejections,
+// such as insufficient balance, at debug level only.
+func (h *Handler) logFailure(c *gin.Context, apiErr *APIError) {
+	if apiErr.Status >= http.StatusInternalServerError {
+		h.log.ErrorContext(c.Request.Context(), "request failed", slog.String("code", string(apiErr.Code)), logger.Err(apiErr.Err))
+		return
+	}
+	h.log.DebugContext(c.Request.Context(), "request rejected",
+		slog.Int("status", apiErr.Status),
+		slog.String("code", string(apiErr.Code)),
+		logger.Err(apiErr),
+	)
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMetrics_AfterRequest_ExposesRouteAndStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Get(gomock.Any(), id).
+		Return(nil, domain.ErrWalletNotFound)
+
+	srv := service.Service{
+		Wallet: mockWallet,
+	}
+	h := NewHandler(&srv, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String(), nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `wallet_http_requests_total{method="GET",route="/api/v1/wallets/:id",status="404"}`)
+}
+
+func TestRequestID_IncomingHeader_EchoedInResponse(t *testing.T) {
+	srv := service.Service{}
+	h := NewHandler(&srv, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set(headerRequestID, "req-42")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "req-42", w.Header().Get(headerRequestID))
+}
+
+func TestRequestID_NoHeader_Generated(t *testing.T) {
+	srv := service.Service{}
+	h := NewHandler(&srv, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	_, err := uuid.Parse(w.Header().Get(headerRequestID))
+	assert.NoError(t, err)
+}
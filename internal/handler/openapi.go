@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"wallet-service/api"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) OpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", api.Spec)
+}
+
+func (h *Handler) Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", api.DocsHTML)
+}
+
+func loadOpenAPI() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(api.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// requestValidationMiddleware rejects requests that do not match the
+// OpenAPI document before they reach the handlers. Routes absent from the
+// document are passed through unchanged.
+func requestValidationMiddleware(doc *openapi3.T) (gin.HandlerFunc, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			if errors.Is(err, routers.ErrPathNotFound) || errors.Is(err, routers.ErrMethodNotAllowed) {
+				c.Next()
+				return
+			}
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		err = openapi3filter.ValidateRequest(c.Request.Context(), &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError: true,
+				// Admin tokens are checked by adminAuthMiddleware.
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		})
+		if err != nil {
+			_ = c.Error(newSpecValidationError(err))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}, nil
+}
+
+func newSpecValidationError(err error) *APIError {
+	apiErr := &APIError{
+		Status: http.StatusBadRequest,
+		Code:   CodeValidationFailed,
+		Title:  "Validation failed",
+		Detail: "request does not match the API specification",
+		Err:    err,
+	}
+
+	var multi openapi3.MultiError
+	if !errors.As(err, &multi) {
+		multi = openapi3.MultiError{err}
+	}
+
+	for _, e := range multi {
+		var reqErr *openapi3filter.RequestError
+		if !errors.As(e, &reqErr) {
+			continue
+		}
+
+		field := "body"
+		if reqErr.Parameter != nil {
+			field = reqErr.Parameter.Name
+		}
+
+		var schemaErrs openapi3.MultiError
+		if errors.As(reqErr.Err, &schemaErrs) {
+			for _, se := range schemaErrs {
+				apiErr.Fields = append(apiErr.Fields, schemaFieldError(field, se))
+			}
+			continue
+		}
+		apiErr.Fields = append(apiErr.Fields, schemaFieldError(field, reqErr.Err))
+	}
+
+	return apiErr
+}
+
+func schemaFieldError(field string, err error) FieldError {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+			field = pointer[len(pointer)-1]
+		}
+		return FieldError{Field: field, Reason: schemaErr.Reason}
+	}
+	if err == nil {
+		return FieldError{Field: field, Reason: "is invalid"}
+	}
+	return FieldError{Field: field, Reason: err.Error()}
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+	"wallet-service/internal/statement"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var ginParam = regexp.MustCompile(`:(\w+)`)
+
+func TestOpenAPI_EmbeddedSpec_IsValid(t *testing.T) {
+	_, err := loadOpenAPI()
+
+	assert.NoError(t, err)
+}
+
+func TestOpenAPI_RouterAndSpec_DescribeSameRoutes(t *testing.T) {
+	doc, err := loadOpenAPI()
+	assert.NoError(t, err)
+
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	var inRouter []string
+	for _, route := range router.Routes() {
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		inRouter = append(inRouter, route.Method+" "+path)
+	}
+
+	var inSpec []string
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			inSpec = append(inSpec, method+" "+path)
+		}
+	}
+
+	sort.Strings(inRouter)
+	sort.Strings(inSpec)
+	assert.Equal(t, inRouter, inSpec, "router and api/openapi.json have drifted apart")
+}
+
+func TestOpenAPI_DTOs_MatchSchemas(t *testing.T) {
+	doc, err := loadOpenAPI()
+	assert.NoError(t, err)
+
+	dtos := map[string]any{
+		"UpdateWalletRequest":      UpdateWalletRequest{},
+		"UpdateWalletResponse":     UpdateWalletResponse{},
+		"GetWalletResponse":        GetWalletResponse{},
+		"ErrorResponse":            ErrorResponse{},
+		"Problem":                  Problem{},
+		"FieldError":               FieldError{},
+		"HealthResponse":           HealthResponse{},
+		"ReadinessResponse":        ReadinessResponse{},
+		"SetMaintenanceRequest":    SetMaintenanceRequest{},
+		"MaintenanceResponse":      MaintenanceResponse{},
+		"SetTierRequest":           SetTierRequest{},
+		"TierResponse":             TierResponse{},
+		"SetInterestRateRequest":   SetInterestRateRequest{},
+		"InterestResponse":         InterestResponse{},
+		"GrantBonusRequest":        GrantBonusRequest{},
+		"BonusResponse":            BonusResponse{},
+		"AuditRecordResponse":      AuditRecordResponse{},
+		"AuditLogResponse":         AuditLogResponse{},
+		"ChainReportResponse":      ChainReportResponse{},
+		"ChainBreakResponse":       ChainBreakResponse{},
+		"StatementLine":            statement.Line{},
+		"CreateScheduleRequest":    CreateScheduleRequest{},
+		"ScheduleResponse":         ScheduleResponse{},
+		"ScheduleListResponse":     ScheduleListResponse{},
+		"CreateEscrowRequest":      CreateEscrowRequest{},
+		"ConfirmEscrowRequest":     ConfirmEscrowRequest{},
+		"EscrowResponse":           EscrowResponse{},
+		"EscrowTransitionResponse": EscrowTransitionResponse{},
+		"SplitPaymentRequest":      SplitPaymentRequest{},
+		"SplitRecipientRequest":    SplitRecipientRequest{},
+		"SplitPaymentResponse":     SplitPaymentResponse{},
+		"SplitCreditResponse":      SplitCreditResponse{},
+	}
+
+	for name, dto := range dtos {
+		schema, ok := doc.Components.Schemas[name]
+		if !assert.True(t, ok, "schema %s is missing", name) {
+			continue
+		}
+
+		var specFields []string
+		for field := range schema.Value.Properties {
+			specFields = append(specFields, field)
+		}
+
+		var dtoFields []string
+		typ := reflect.TypeOf(dto)
+		for i := 0; i < typ.NumField(); i++ {
+			field, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			dtoFields = append(dtoFields, field)
+		}
+
+		assert.ElementsMatch(t, dtoFields, specFields, "schema %s differs from its DTO", name)
+	}
+}
+
+func TestRequestValidation_AmountBelowMinimum_400(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger, WithRequestValidation(true))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/wallet", getBodyReader(t, map[string]interface{}{
+		"walletId":      uuid.New().String(),
+		"operationType": "DEPOSIT",
+		"amount":        0,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), string(CodeValidationFailed))
+	assert.Contains(t, w.Body.String(), `"field":"amount"`)
+}
+
+func TestRequestValidation_ValidRequest_ReachesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	wallet, err := domain.NewWallet(id, 10)
+	assert.NoError(t, err)
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Get(gomock.Any(), id).
+		Return(wallet, nil)
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger, WithRequestValidation(true))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestOpenAPISpec_Served_200(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"openapi": "3.0.3"`)
+}
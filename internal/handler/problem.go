@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/maintenance"
+)
+
+const (
+	contentTypeProblem = "application/problem+json"
+	problemTypePrefix  = "urn:wallet-service:problem:"
+
+	// retryAfterUnavailable is suggested to clients on every 503: lock and
+	// statement timeouts are short-lived by nature.
+	retryAfterUnavailable = time.Second
+)
+
+type ErrorCode string
+
+const (
+	CodeWalletNotFound    ErrorCode = "WALLET_NOT_FOUND"
+	CodeInsufficientFunds ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeInvalidAmount     ErrorCode = "INVALID_AMOUNT"
+	CodeBalanceOverflow   ErrorCode = "BALANCE_OVERFLOW"
+	CodeInvalidWalletID   ErrorCode = "INVALID_WALLET_ID"
+	CodeValidationFailed  ErrorCode = "VALIDATION_FAILED"
+	CodeMalformedRequest  ErrorCode = "MALFORMED_REQUEST"
+	CodeRouteNotFound     ErrorCode = "ROUTE_NOT_FOUND"
+	CodeWalletBusy        ErrorCode = "WALLET_BUSY"
+	CodeTimeout           ErrorCode = "TIMEOUT"
+	CodeUnavailable       ErrorCode = "UNAVAILABLE"
+	CodeMaintenance       ErrorCode = "MAINTENANCE"
+	CodeUnauthorized      ErrorCode = "UNAUTHORIZED"
+	CodeInternal          ErrorCode = "INTERNAL_ERROR"
+
+	CodeScheduleNotFound       ErrorCode = "SCHEDULE_NOT_FOUND"
+	CodeScheduleNotCancellable ErrorCode = "SCHEDULE_NOT_CANCELLABLE"
+	CodeNotImplemented         ErrorCode = "NOT_IMPLEMENTED"
+
+	CodeInterestAccountNotFound ErrorCode = "INTEREST_ACCOUNT_NOT_FOUND"
+
+	CodeEscrowNotFound   ErrorCode = "ESCROW_NOT_FOUND"
+	CodeEscrowNotAllowed ErrorCode = "ESCROW_TRANSITION_NOT_ALLOWED"
+	CodeEscrowExpired    ErrorCode = "ESCROW_EXPIRED"
+)
+
+// Problem is an RFC 7807 problem details document extended with a stable
+// machine-readable code.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      ErrorCode    `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// APIError carries everything needed to render an error response in either
+// the problem or the legacy format.
+type APIError struct {
+	Status     int
+	Code       ErrorCode
+	Title      string
+	Detail     string
+	Fields     []FieldError
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Detail
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+type errorSpec struct {
+	err    error
+	status int
+	code   ErrorCode
+	title  string
+}
+
+var errorCatalogue = []errorSpec{
+	{domain.ErrWalletNotFound, http.StatusNotFound, CodeWalletNotFound, "Wallet not found"},
+	{domain.ErrInsufficientBalance, http.StatusConflict, CodeInsufficientFunds, "Insufficient funds"},
+	{domain.ErrZeroAmount, http.StatusBadRequest, CodeInvalidAmount, "Invalid amount"},
+	{domain.ErrNegativeAmount, http.StatusBadRequest, CodeInvalidAmount, "Invalid amount"},
+	{domain.ErrOverflow, http.StatusUnprocessableEntity, CodeBalanceOverflow, "Balance overflow"},
+	{ErrInvalidFormatID, http.StatusBadRequest, CodeInvalidWalletID, "Invalid wallet id"},
+	{ErrPathParameterID, http.StatusBadRequest, CodeInvalidWalletID, "Invalid wallet id"},
+	{ErrRouteNotFound, http.StatusNotFound, CodeRouteNotFound, "Route not found"},
+	{domain.ErrWalletBusy, http.StatusServiceUnavailable, CodeWalletBusy, "Wallet busy"},
+	{domain.ErrTimeout, http.StatusServiceUnavailable, CodeTimeout, "Operation timed out"},
+	{context.DeadlineExceeded, http.StatusServiceUnavailable, CodeTimeout, "Operation timed out"},
+	{domain.ErrUnavailable, http.StatusServiceUnavailable, CodeUnavailable, "Storage unavailable"},
+	{maintenance.ErrReadOnly, http.StatusServiceUnavailable, CodeMaintenance, "Maintenance"},
+	{ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized, "Unauthorized"},
+	{domain.ErrScheduleNotFound, http.StatusNotFound, CodeScheduleNotFound, "Schedule not found"},
+	{domain.ErrScheduleNotCancellable, http.StatusConflict, CodeScheduleNotCancellable, "Schedule not cancellable"},
+	{ErrSchedulesUnsupported, http.StatusNotImplemented, CodeNotImplemented, "Not implemented"},
+	{ErrAuditUnsupported, http.StatusNotImplemented, CodeNotImplemented, "Not implemented"},
+	{ErrLedgerUnsupported, http.StatusNotImplemented, CodeNotImplemented, "Not implemented"},
+	{ErrTiersUnsupported, http.StatusNotImplemented, CodeNotImplemented, "Not implemented"},
+	{domain.ErrInterestAccountNotFound, http.StatusNotFound, CodeInterestAccountNotFound, "Interest account not found"},
+	{domain.ErrInvalidInterestRate, http.StatusBadRequest, CodeValidationFailed, "Validation failed"},
+	{ErrInterestUnsupported, http.StatusNotImplemented, CodeNotImplemented, "Not implemented"},
+	{domain.ErrBonusExpiry, http.StatusBadRequest, CodeValidationFailed, "Validation failed"},
+	{ErrBonusesUnsupported, http.StatusNotImplemented, CodeNotImplemented, "Not implemented"},
+	{domain.ErrEscrowNotFound, http.StatusNotFound, CodeEscrowNotFound, "Escrow not found"},
+	{domain.ErrEscrowTransition, http.StatusConflict, CodeEscrowNotAllowed, "Escrow transition not allowed"},
+	{domain.ErrEscrowExpired, http.StatusConflict, CodeEscrowExpired, "Escrow expired"},
+	{domain.ErrEscrowParties, http.StatusBadRequest, CodeValidationFailed, "Validation failed"},
+	{domain.ErrEscrowDeadline, http.StatusBadRequest, CodeValidationFailed, "Validation failed"},
+	{domain.ErrEscrowParty, http.StatusBadRequest, CodeValidationFailed, "Validation failed"},
+	{ErrEscrowsUnsupported, http.StatusNotImplemented, CodeNotImplemented, "Not implemented"},
+	{domain.ErrSplitRecipients, http.StatusBadRequest, CodeValidationFailed, "Validation failed"},
+	{domain.ErrSplitRecipient, http.StatusBadRequest, CodeValidationFailed, "Validation failed"},
+	{domain.ErrSplitShare, http.StatusBadRequest, CodeValidationFailed, "Validation failed"},
+	{domain.ErrSplitShares, http.StatusBadRequest, CodeValidationFailed, "Validation failed"},
+	{domain.ErrSplitRemainder, http.StatusBadRequest, CodeValidationFailed, "Validation failed"},
+}
+
+// toAPIError maps any error returned by a handler onto the catalogue.
+// Unknown errors become an internal error whose detail is not exposed.
+func toAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	for _, spec := range errorCatalogue {
+		if errors.Is(err, spec.err) {
+			apiErr := &APIError{
+				Status: spec.status,
+				Code:   spec.code,
+				Title:  spec.title,
+				Detail: spec.err.Error(),
+				Err:    err,
+			}
+			if spec.status == http.StatusServiceUnavailable {
+				apiErr.RetryAfter = retryAfterUnavailable
+			}
+			return apiErr
+		}
+	}
+
+	return &APIError{
+		Status: http.StatusInternalServerError,
+		Code:   CodeInternal,
+		Title:  "Internal server error",
+		Detail: "internal server error",
+		Err:    err,
+	}
+}
+
+func (e *APIError) problem(instance, requestID string) *Problem {
+	return &Problem{
+		Type:      problemTypePrefix + strings.ToLower(strings.ReplaceAll(string(e.Code), "_", "-")),
+		Title:     e.Title,
+		Status:    e.Status,
+		Detail:    e.Detail,
+		Instance:  instance,
+		Code:      e.Code,
+		RequestID: requestID,
+		Errors:    e.Fields,
+	}
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetWallet_V2NonExistentWallet_ProblemResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Get(gomock.Any(), id).
+		Return(nil, domain.ErrWalletNotFound)
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+id.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, contentTypeProblem, w.Header().Get("Content-Type"))
+	assert.Equal(t, CodeWalletNotFound, problem.Code)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "/api/v2/wallets/"+id.String(), problem.Instance)
+	assert.NotEmpty(t, problem.RequestID)
+}
+
+func TestGetWallet_V1NonExistentWallet_LegacyResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Get(gomock.Any(), id).
+		Return(nil, domain.ErrWalletNotFound)
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var resp map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, map[string]any{"message": domain.ErrWalletNotFound.Error()}, resp)
+}
+
+func TestGetWallet_V1AcceptProblem_ProblemResponse(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/12345", nil)
+	req.Header.Set("Accept", contentTypeProblem)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CodeInvalidWalletID, problem.Code)
+}
+
+func TestUpdateWallet_V2InvalidFields_ValidationFailedWithFields(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/wallet", getBodyReader(t, map[string]interface{}{
+		"walletId":      uuid.New().String(),
+		"operationType": "ADD",
+		"amount":        -1,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CodeValidationFailed, problem.Code)
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "operationType", Reason: "must be one of DEPOSIT WITHDRAW"},
+		{Field: "amount", Reason: "must be greater than or equal to 0"},
+	}, problem.Errors)
+}
+
+func TestUpdateWallet_V2MalformedJSON_MalformedRequest(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/wallet", nil)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CodeMalformedRequest, problem.Code)
+}
+
+func TestUpdateWallet_V1ServiceError_LegacyBodyWithoutDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var amount int64 = 10
+	id := uuid.New()
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Deposit(gomock.Any(), id, amount).
+		Return(nil, errors.New("connection refused"))
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
+		"walletId":      id.String(),
+		"operationType": "DEPOSIT",
+		"amount":        amount,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var resp ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "internal server error", resp.Message)
+}
+
+func TestUnknownRoute_V2_ProblemResponse(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/unknown", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, CodeRouteNotFound, problem.Code)
+}
+
+func TestUpdateWallet_V2WalletBusy_503WithRetryAfter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var amount int64 = 10
+	id := uuid.New()
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Withdraw(gomock.Any(), id, amount).
+		Return(nil, fmt.Errorf("lock wallet %s: %w", id, domain.ErrWalletBusy))
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/wallet", getBodyReader(t, map[string]interface{}{
+		"walletId":      id.String(),
+		"operationType": "WITHDRAW",
+		"amount":        amount,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, CodeWalletBusy, problem.Code)
+}
+
+func TestUpdateWallet_V2StorageUnavailable_503(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var amount int64 = 10
+	id := uuid.New()
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Deposit(gomock.Any(), id, amount).
+		Return(nil, fmt.Errorf("%w after 5 attempts: %w", domain.ErrUnavailable, io.ErrUnexpectedEOF))
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/wallet", getBodyReader(t, map[string]interface{}{
+		"walletId":      id.String(),
+		"operationType": "DEPOSIT",
+		"amount":        amount,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, CodeUnavailable, problem.Code)
+	// Текст исходной ошибки наружу не попадает
+	assert.Equal(t, domain.ErrUnavailable.Error(), problem.Detail)
+}
+
+func TestGetWallet_RequestTimeout_503(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Get(gomock.Any(), id).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID) (*domain.Wallet, error) {
+			// сервис ждёт, пока не истечёт дедлайн запроса
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger, WithRequestTimeout(10*time.Millisecond))
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+id.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, CodeTimeout, problem.Code)
+}
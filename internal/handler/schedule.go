@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	"wallet-service/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	// ErrSchedulesUnsupported is returned on a storage without scheduled
+	// operations.
+	ErrSchedulesUnsupported  = errors.New("scheduled operations are not supported by this storage")
+	ErrInvalidScheduleStatus = errors.New("invalid schedule status")
+)
+
+var scheduleStatuses = []string{
+	domain.SchedulePending,
+	domain.ScheduleRunning,
+	domain.ScheduleRetrying,
+	domain.ScheduleCompleted,
+	domain.ScheduleFailed,
+	domain.ScheduleCancelled,
+}
+
+func (h *Handler) CreateSchedule(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.CreateSchedule")
+	defer span.End()
+
+	if h.services.Schedule == nil {
+		_ = c.Error(ErrSchedulesUnsupported)
+		return
+	}
+
+	var in CreateScheduleRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(newBindError(err))
+		return
+	}
+
+	walletID, err := uuid.Parse(in.WalletID)
+	if err != nil {
+		_ = c.Error(&APIError{
+			Status: http.StatusBadRequest,
+			Code:   CodeInvalidWalletID,
+			Title:  "Invalid wallet id",
+			Detail: ErrInvalidFormatID.Error(),
+			Fields: []FieldError{{Field: "walletId", Reason: "must be a UUID"}},
+			Err:    err,
+		})
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("wallet.id", walletID.String()),
+		attribute.String("schedule.operation", in.OperationType),
+	)
+
+	var runAt time.Time
+	if in.RunAt != nil {
+		runAt = *in.RunAt
+	}
+	schedule, err := h.services.Schedule.Create(ctx, walletID, in.OperationType, in.Amount, runAt, in.Cron)
+	if err != nil {
+		tracing.Fail(span, err)
+		invalid := func(field, reason string) {
+			_ = c.Error(&APIError{
+				Status: http.StatusBadRequest,
+				Code:   CodeValidationFailed,
+				Title:  "Validation failed",
+				Detail: err.Error(),
+				Fields: []FieldError{{Field: field, Reason: reason}},
+				Err:    err,
+			})
+		}
+		switch {
+		case errors.Is(err, service.ErrScheduleTiming):
+			invalid("runAt", "exactly one of runAt and cron is required")
+		case errors.Is(err, service.ErrInvalidCron):
+			invalid("cron", "must be a cron expression")
+		default:
+			_ = c.Error(err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, toScheduleResponse(schedule))
+}
+
+func (h *Handler) GetSchedule(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.GetSchedule")
+	defer span.End()
+
+	if h.services.Schedule == nil {
+		_ = c.Error(ErrSchedulesUnsupported)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	schedule, err := h.services.Schedule.Get(ctx, id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toScheduleResponse(schedule))
+}
+
+// CancelSchedule stops a schedule that is not running at the moment.
+func (h *Handler) CancelSchedule(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.CancelSchedule")
+	defer span.End()
+
+	if h.services.Schedule == nil {
+		_ = c.Error(ErrSchedulesUnsupported)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	schedule, err := h.services.Schedule.Cancel(ctx, id)
+	if err != nil {
+		tracing.Fail(span, err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toScheduleResponse(schedule))
+}
+
+func (h *Handler) ListSchedules(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.ListSchedules")
+	defer span.End()
+
+	if h.services.Schedule == nil {
+		_ = c.Error(ErrSchedulesUnsupported)
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && !slices.Contains(scheduleStatuses, status) {
+		_ = c.Error(&APIError{
+			Status: http.StatusBadRequest,
+			Code:   CodeValidationFailed,
+			Title:  "Validation failed",
+			Detail: ErrInvalidScheduleStatus.Error(),
+			Fields: []FieldError{{Field: "status", Reason: "must be one of " + strings.Join(scheduleStatuses, " ")}},
+			Err:    ErrInvalidScheduleStatus,
+		})
+		return
+	}
+
+	schedules, err := h.services.Schedule.List(ctx, walletID, status)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	resp := &ScheduleListResponse{Schedules: make([]ScheduleResponse, 0, len(schedules))}
+	for i := range schedules {
+		resp.Schedules = append(resp.Schedules, *toScheduleResponse(&schedules[i]))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func toScheduleResponse(schedule *domain.Schedule) *ScheduleResponse {
+	resp := &ScheduleResponse{
+		ID:            schedule.ID.String(),
+		WalletID:      schedule.WalletID.String(),
+		OperationType: schedule.Operation,
+		Amount:        schedule.Amount,
+		Cron:          schedule.Cron,
+		Status:        schedule.Status,
+		NextRunAt:     schedule.NextRunAt,
+		Attempts:      schedule.Attempts,
+		MaxAttempts:   schedule.MaxAttempts,
+		Runs:          schedule.Runs,
+		Failures:      schedule.Failures,
+		LastError:     schedule.LastError,
+		CreatedBy:     schedule.CreatedBy,
+		CreatedAt:     schedule.CreatedAt,
+		UpdatedAt:     schedule.UpdatedAt,
+	}
+	if !schedule.LastRunAt.IsZero() {
+		lastRunAt := schedule.LastRunAt
+		resp.LastRunAt = &lastRunAt
+	}
+	return resp
+}
@@ -0,0 +1,37 @@
+package handler
+
+import "time"
+
+type CreateScheduleRequest struct {
+	WalletID      string `json:"walletId" binding:"required"`
+	OperationType string `json:"operationType" binding:"required,oneof=DEPOSIT WITHDRAW"`
+	Amount        int64  `json:"amount" binding:"required,gte=0"`
+	// Exactly one of RunAt and Cron is set: a one-off operation runs at
+	// RunAt, a recurring one on every occurrence of Cron, in UTC.
+	RunAt *time.Time `json:"runAt"`
+	Cron  string     `json:"cron"`
+}
+
+type ScheduleResponse struct {
+	ID            string    `json:"id"`
+	WalletID      string    `json:"walletId"`
+	OperationType string    `json:"operationType"`
+	Amount        int64     `json:"amount"`
+	Cron          string    `json:"cron,omitempty"`
+	Status        string    `json:"status"`
+	NextRunAt     time.Time `json:"nextRunAt"`
+	Attempts      int       `json:"attempts"`
+	MaxAttempts   int       `json:"maxAttempts"`
+	Runs          int64     `json:"runs"`
+	Failures      int64     `json:"failures"`
+	// LastRunAt and LastError describe the latest attempt.
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+	CreatedBy string     `json:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+type ScheduleListResponse struct {
+	Schedules []ScheduleResponse `json:"schedules"`
+}
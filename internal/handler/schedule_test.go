@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateSchedule_Cron_201(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	schedule, err := domain.NewSchedule(walletID, domain.ScheduleDeposit, 50, "0 9 * * *", now.Add(21*time.Hour), 5, "anonymous", now)
+	require.NoError(t, err)
+
+	mockSchedule := mock_service.NewMockSchedule(ctrl)
+	mockSchedule.
+		EXPECT().
+		Create(gomock.Any(), walletID, domain.ScheduleDeposit, int64(50), time.Time{}, "0 9 * * *").
+		Return(schedule, nil)
+
+	h := NewHandler(&service.Service{Schedule: mockSchedule}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	body := fmt.Sprintf(`{"walletId":%q,"operationType":"DEPOSIT","amount":50,"cron":"0 9 * * *"}`, walletID)
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/schedules", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp ScheduleResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, schedule.ID.String(), resp.ID)
+	assert.Equal(t, domain.SchedulePending, resp.Status)
+	assert.Equal(t, "0 9 * * *", resp.Cron)
+	assert.Equal(t, time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC), resp.NextRunAt)
+	assert.Nil(t, resp.LastRunAt)
+}
+
+func TestCreateSchedule_InvalidCron_400(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSchedule := mock_service.NewMockSchedule(ctrl)
+	mockSchedule.
+		EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "every day").
+		Return(nil, fmt.Errorf("%w: bad field", service.ErrInvalidCron))
+
+	h := NewHandler(&service.Service{Schedule: mockSchedule}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	body := fmt.Sprintf(`{"walletId":%q,"operationType":"WITHDRAW","amount":5,"cron":"every day"}`, uuid.New())
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/schedules", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeValidationFailed, problem.Code)
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "cron", problem.Errors[0].Field)
+}
+
+func TestCancelSchedule_Running_409(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	mockSchedule := mock_service.NewMockSchedule(ctrl)
+	mockSchedule.
+		EXPECT().
+		Cancel(gomock.Any(), id).
+		Return(nil, domain.ErrScheduleNotCancellable)
+
+	h := NewHandler(&service.Service{Schedule: mockSchedule}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v2/schedules/"+id.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeScheduleNotCancellable, problem.Code)
+}
+
+func TestListSchedules_InvalidStatus_400(t *testing.T) {
+	h := NewHandler(&service.Service{Schedule: mock_service.NewMockSchedule(gomock.NewController(t))}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+uuid.NewString()+"/schedules?status=done", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "status", problem.Errors[0].Field)
+}
+
+func TestGetSchedule_Unsupported_501(t *testing.T) {
+	// Хранилище без расписаний, например YDB
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/schedules/"+uuid.NewString(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeNotImplemented, problem.Code)
+}
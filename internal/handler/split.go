@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SplitPayment takes an amount from the wallet and divides it among the
+// recipients in one transaction.
+func (h *Handler) SplitPayment(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.SplitPayment")
+	defer span.End()
+
+	sourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	var in SplitPaymentRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(newBindError(err))
+		return
+	}
+
+	shares := make([]domain.SplitShare, len(in.Recipients))
+	for i, r := range in.Recipients {
+		id, err := parseWalletField(fmt.Sprintf("recipients[%d].walletId", i), r.WalletID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		shares[i] = domain.SplitShare{WalletID: id, Amount: r.Amount, Percent: r.Percent}
+	}
+
+	span.SetAttributes(
+		attribute.String("wallet.id", sourceID.String()),
+		attribute.Int("split.recipients", len(shares)),
+	)
+
+	receipt, err := h.services.Split(ctx, sourceID, in.Amount, shares, in.Remainder)
+	if err != nil {
+		tracing.Fail(span, err)
+		_ = c.Error(err)
+		return
+	}
+
+	wallet := receipt.Wallet
+	resp := &SplitPaymentResponse{
+		WalletID:   wallet.ID().String(),
+		NewBalance: wallet.Balance(),
+		Debited:    receipt.Split.Debit,
+		Remainder:  receipt.Split.Remainder,
+		Credits:    make([]SplitCreditResponse, len(receipt.Split.Credits)),
+	}
+	for i, credit := range receipt.Split.Credits {
+		resp.Credits[i] = SplitCreditResponse{WalletID: credit.WalletID.String(), Amount: credit.Amount}
+	}
+	c.JSON(http.StatusOK, resp)
+
+	wallet.Release()
+}
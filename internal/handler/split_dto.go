@@ -0,0 +1,32 @@
+package handler
+
+type SplitPaymentRequest struct {
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+	// Remainder is where rounding leftovers of percentage shares go,
+	// largest when empty.
+	Remainder  string                  `json:"remainder" binding:"omitempty,oneof=largest first last source"`
+	Recipients []SplitRecipientRequest `json:"recipients" binding:"required,min=1,max=20,dive"`
+}
+
+// SplitRecipientRequest sets either a fixed Amount or a Percent, in basis
+// points, of what the fixed shares leave.
+type SplitRecipientRequest struct {
+	WalletID string `json:"walletId" binding:"required"`
+	Amount   int64  `json:"amount" binding:"gte=0"`
+	Percent  int64  `json:"percent" binding:"gte=0,lte=10000"`
+}
+
+type SplitPaymentResponse struct {
+	WalletID   string `json:"walletId"`
+	NewBalance int64  `json:"newBalance"`
+	Debited    int64  `json:"debited"`
+	// Remainder is what rounding left over, included in Debited unless the
+	// rule is source.
+	Remainder int64                 `json:"remainder"`
+	Credits   []SplitCreditResponse `json:"credits"`
+}
+
+type SplitCreditResponse struct {
+	WalletID string `json:"walletId"`
+	Amount   int64  `json:"amount"`
+}
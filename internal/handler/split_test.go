@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSplitPayment_200(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	source, merchant, platform := uuid.New(), uuid.New(), uuid.New()
+	shares := []domain.SplitShare{
+		{WalletID: merchant, Percent: 10000},
+		{WalletID: platform, Amount: 50},
+	}
+	split, err := domain.NewSplit(source, 1000, shares, domain.SplitRemainderFirst)
+	require.NoError(t, err)
+	wallet, err := domain.NewWallet(source, 9000)
+	require.NoError(t, err)
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Split(gomock.Any(), source, int64(1000), shares, domain.SplitRemainderFirst).
+		Return(&domain.SplitReceipt{Wallet: wallet, Split: split}, nil)
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallets/"+source.String()+"/split", getBodyReader(t, map[string]interface{}{
+		"amount":    1000,
+		"remainder": "first",
+		"recipients": []map[string]interface{}{
+			{"walletId": merchant, "percent": 10000},
+			{"walletId": platform, "amount": 50},
+		},
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp SplitPaymentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(9000), resp.NewBalance)
+	assert.Equal(t, int64(1000), resp.Debited)
+	assert.Equal(t, []SplitCreditResponse{
+		{WalletID: merchant.String(), Amount: 950},
+		{WalletID: platform.String(), Amount: 50},
+	}, resp.Credits)
+}
+
+func TestSplitPayment_SharesMismatch_400(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	source := uuid.New()
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Split(gomock.Any(), source, int64(1000), gomock.Any(), "").
+		Return(nil, domain.ErrSplitShares)
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/wallets/"+source.String()+"/split", getBodyReader(t, map[string]interface{}{
+		"amount":     1000,
+		"recipients": []map[string]interface{}{{"walletId": uuid.New(), "percent": 5000}},
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeValidationFailed, problem.Code)
+}
+
+func TestSplitPayment_InvalidRecipientID_400(t *testing.T) {
+	h := NewHandler(&service.Service{Wallet: mock_service.NewMockWallet(gomock.NewController(t))}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/wallets/"+uuid.NewString()+"/split", getBodyReader(t, map[string]interface{}{
+		"amount":     1000,
+		"recipients": []map[string]interface{}{{"walletId": "not-a-uuid", "amount": 1000}},
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, CodeInvalidWalletID, problem.Code)
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "recipients[0].walletId", problem.Errors[0].Field)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/statement"
+	"wallet-service/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidStatementQuery = errors.New("invalid statement query")
+
+// GetStatement streams the statement of a wallet. Headers are sent with the
+// first line, so errors found before it are rendered as usual; a failure
+// later can only cut the body short.
+func (h *Handler) GetStatement(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.GetStatement")
+	defer span.End()
+
+	if h.services.Statement == nil {
+		_ = c.Error(ErrLedgerUnsupported)
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(ErrInvalidFormatID)
+		return
+	}
+
+	from, to, format, fields := parseStatementQuery(c, time.Now())
+	if len(fields) > 0 {
+		_ = c.Error(&APIError{
+			Status: http.StatusBadRequest,
+			Code:   CodeValidationFailed,
+			Title:  "Validation failed",
+			Detail: ErrInvalidStatementQuery.Error(),
+			Fields: fields,
+			Err:    ErrInvalidStatementQuery,
+		})
+		return
+	}
+
+	var enc statement.Encoder
+	err = h.services.Statement.Statement(ctx, walletID, from, to, func(line *domain.StatementLine) error {
+		if enc == nil {
+			c.Header("Content-Type", statement.ContentType(format))
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%s.%s"`, walletID, format))
+			c.Status(http.StatusOK)
+			enc, _ = statement.NewEncoder(c.Writer, format)
+		}
+		return enc.Encode(line)
+	})
+	if enc != nil {
+		if flushErr := enc.Flush(); err == nil {
+			err = flushErr
+		}
+	}
+	if err == nil {
+		return
+	}
+
+	tracing.Fail(span, err)
+	if enc == nil {
+		_ = c.Error(err)
+		return
+	}
+	h.log.ErrorContext(ctx, "statement cut short", logger.Err(err))
+}
+
+// parseStatementQuery reads from, to and format. A missing from starts the
+// statement at the first transaction, a missing to ends it at now.
+func parseStatementQuery(c *gin.Context, now time.Time) (from, to time.Time, format string, fields []FieldError) {
+	to = now
+	if s := c.Query("from"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			fields = append(fields, FieldError{Field: "from", Reason: "must be an RFC 3339 time"})
+		}
+		from = t
+	}
+	if s := c.Query("to"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			fields = append(fields, FieldError{Field: "to", Reason: "must be an RFC 3339 time"})
+		}
+		to = t
+	}
+	if len(fields) == 0 && !from.Before(to) {
+		fields = append(fields, FieldError{Field: "to", Reason: "must be after from"})
+	}
+
+	format = c.DefaultQuery("format", statement.FormatCSV)
+	if !slices.Contains(statement.Formats, format) {
+		fields = append(fields, FieldError{Field: "format", Reason: "must be one of " + strings.Join(statement.Formats, " ")})
+	}
+	return from, to, format, fields
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+	"wallet-service/internal/statement"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetStatement_JSONL_StreamsLines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	tx := domain.NewTransaction(nil, walletID, domain.TransactionWithdraw, 30, 70, from.Add(time.Hour))
+
+	mockStatement := mock_service.NewMockStatement(ctrl)
+	mockStatement.
+		EXPECT().
+		Statement(gomock.Any(), walletID, from, to, gomock.Any()).
+		DoAndReturn(func(_, _, _, _ any, emit func(*domain.StatementLine) error) error {
+			for _, line := range []*domain.StatementLine{
+				{Type: domain.StatementOpening, WalletID: walletID, At: from, Balance: 100},
+				{Type: domain.StatementMovement, WalletID: walletID, At: tx.At, Balance: 70, Movement: tx},
+				{Type: domain.StatementClosing, WalletID: walletID, At: to, Balance: 70},
+			} {
+				if err := emit(line); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	h := NewHandler(&service.Service{Statement: mockStatement}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+walletID.String()+
+		"/statement?format=jsonl&from=2026-10-01T00:00:00Z&to=2026-11-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/jsonl", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var movement statement.Line
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &movement))
+	assert.Equal(t, domain.StatementMovement, movement.Type)
+	require.NotNil(t, movement.Amount)
+	assert.Equal(t, int64(-30), *movement.Amount)
+	assert.Equal(t, int64(70), movement.Balance)
+}
+
+func TestGetStatement_MissingWallet_404(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStatement := mock_service.NewMockStatement(ctrl)
+	mockStatement.
+		EXPECT().
+		Statement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(domain.ErrWalletNotFound)
+
+	h := NewHandler(&service.Service{Statement: mockStatement}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+uuid.NewString()+"/statement", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	// Заголовки ещё не отправлены, поэтому ошибка отдаётся как обычно
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+}
+
+func TestGetStatement_InvalidQuery_400(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewHandler(&service.Service{Statement: mock_service.NewMockStatement(ctrl)}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+uuid.NewString()+
+		"/statement?format=xml&from=2026-11-01T00:00:00Z&to=2026-10-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var fields []string
+	for _, f := range problem.Errors {
+		fields = append(fields, f.Field)
+	}
+	assert.ElementsMatch(t, []string{"to", "format"}, fields)
+}
+
+func TestGetStatement_Unsupported_501(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+uuid.NewString()+"/statement?format=xml", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.Equal(t, CodeNotImplemented, problem.Code)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	mock_service "wallet-service/internal/service/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+)
+
+// withTestTracing traces into the returned recorder and reads the W3C
+// traceparent header, leaving the global provider and propagator alone.
+func withTestTracing() (Option, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return WithTracing(provider, propagation.TraceContext{}), recorder
+}
+
+func TestUpdateWallet_TraceparentHeader_ContinuesTrace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tracingOpt, _ := withTestTracing()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	var amount int64 = 10
+	id := uuid.New()
+	wallet, err := domain.NewWallet(id, amount)
+	assert.NoError(t, err)
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Deposit(gomock.Any(), id, amount).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID, _ int64) (*domain.Receipt, error) {
+			assert.Equal(t, traceID, trace.SpanContextFromContext(ctx).TraceID().String())
+			return &domain.Receipt{Wallet: wallet}, nil
+		})
+
+	srv := service.Service{
+		Wallet: mockWallet,
+	}
+	h := NewHandler(&srv, testHealth, testLogger, tracingOpt)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
+		"walletId":      id.String(),
+		"operationType": "DEPOSIT",
+		"amount":        amount,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateWallet_ServiceError_SpanFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tracingOpt, recorder := withTestTracing()
+
+	id := uuid.New()
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Withdraw(gomock.Any(), id, int64(10)).
+		Return(nil, domain.ErrInsufficientBalance)
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger, tracingOpt)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
+		"walletId":      id.String(),
+		"operationType": "WITHDRAW",
+		"amount":        10,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var found bool
+	for _, span := range recorder.Ended() {
+		if span.Name() == "handler.UpdateWallet" {
+			found = true
+			assert.Equal(t, codes.Error, span.Status().Code)
+		}
+	}
+	assert.True(t, found)
+}
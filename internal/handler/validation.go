@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var registerTagNameOnce sync.Once
+
+// useJSONFieldNames makes validation errors report JSON field names
+// ("walletId") instead of Go struct field names ("WalletID").
+func useJSONFieldNames() {
+	registerTagNameOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	})
+}
+
+func newBindError(err error) *APIError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:  fe.Field(),
+				Reason: validationReason(fe),
+			})
+		}
+		return &APIError{
+			Status: http.StatusBadRequest,
+			Code:   CodeValidationFailed,
+			Title:  "Validation failed",
+			Detail: "request body has invalid fields",
+			Fields: fields,
+			Err:    err,
+		}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &APIError{
+			Status: http.StatusBadRequest,
+			Code:   CodeValidationFailed,
+			Title:  "Validation failed",
+			Detail: "request body has invalid fields",
+			Fields: []FieldError{{Field: typeErr.Field, Reason: "must be " + typeErr.Type.String()}},
+			Err:    err,
+		}
+	}
+
+	return &APIError{
+		Status: http.StatusBadRequest,
+		Code:   CodeMalformedRequest,
+		Title:  "Malformed request",
+		Detail: "request body is not valid JSON",
+		Err:    err,
+	}
+}
+
+func validationReason(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of " + fe.Param()
+	case "gte":
+		return "must be greater than or equal to " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	default:
+		return fmt.Sprintf("failed %q validation", fe.Tag())
+	}
+}
@@ -4,29 +4,46 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
+	"wallet-service/internal/dbsource"
 	"wallet-service/internal/domain"
+	"wallet-service/internal/service"
+	"wallet-service/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/ydb-platform/ydb-go-sdk/v3/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// headerReadSource tells the client whether a read was served by the
+// primary or by a replica.
+const headerReadSource = "X-Read-Source"
+
 func (h *Handler) UpdateWallet(c *gin.Context) {
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.UpdateWallet")
+	defer span.End()
+
 	var in UpdateWalletRequest
 
-	if err := c.BindJSON(&in); err != nil {
-		log.Error(err)
+	if err := c.ShouldBindJSON(&in); err != nil {
+		_ = c.Error(newBindError(err))
 		return
 	}
 
 	parseID, err := uuid.Parse(in.WalletID)
 	if err != nil {
-		log.Error(err)
-		c.AbortWithStatusJSON(http.StatusBadRequest, &ErrorResponse{Message: ErrInvalidFormatID.Error()})
+		_ = c.Error(&APIError{
+			Status: http.StatusBadRequest,
+			Code:   CodeInvalidWalletID,
+			Title:  "Invalid wallet id",
+			Detail: ErrInvalidFormatID.Error(),
+			Fields: []FieldError{{Field: "walletId", Reason: "must be a UUID"}},
+			Err:    err,
+		})
 		return
 	}
 
-	var serviceCall func(ctx context.Context, id uuid.UUID, amount int64) (*domain.Wallet, error)
+	var serviceCall func(ctx context.Context, id uuid.UUID, amount int64) (*domain.Receipt, error)
 
 	switch in.OperationType {
 	case "DEPOSIT":
@@ -35,26 +52,23 @@ func (h *Handler) UpdateWallet(c *gin.Context) {
 		serviceCall = h.services.Withdraw
 	}
 
-	wallet, err := serviceCall(c, parseID, in.Amount)
+	span.SetAttributes(
+		attribute.String("wallet.id", parseID.String()),
+		attribute.String("wallet.operation", in.OperationType),
+	)
+
+	receipt, err := serviceCall(ctx, parseID, in.Amount)
 	if err != nil {
-		log.Error(err)
-
-		switch {
-		case errors.Is(err, domain.ErrWalletNotFound):
-			c.AbortWithStatusJSON(http.StatusNotFound, &ErrorResponse{Message: domain.ErrWalletNotFound.Error()})
-			return
-		case errors.Is(err, domain.ErrInsufficientBalance):
-			c.AbortWithStatusJSON(http.StatusConflict, &ErrorResponse{Message: domain.ErrInsufficientBalance.Error()})
-			return
-		default:
-			c.AbortWithStatus(http.StatusInternalServerError)
-			return
-		}
+		tracing.Fail(span, err)
+		_ = c.Error(err)
+		return
 	}
 
+	wallet := receipt.Wallet
 	c.JSON(http.StatusOK, &UpdateWalletResponse{
 		WalletID:   wallet.ID().String(),
 		NewBalance: wallet.Balance(),
+		Fee:        receipt.Fee,
 	})
 
 	wallet.Release()
@@ -63,33 +77,107 @@ func (h *Handler) UpdateWallet(c *gin.Context) {
 func (h *Handler) GetWallet(c *gin.Context) {
 	walletID := c.Param("id")
 	if walletID == "" {
-		log.Error(ErrPathParameterID)
-		c.AbortWithStatusJSON(http.StatusBadRequest, &ErrorResponse{Message: ErrPathParameterID.Error()})
+		_ = c.Error(ErrPathParameterID)
 		return
 	}
 
 	parseID, err := uuid.Parse(walletID)
 	if err != nil {
-		log.Error(err)
-		c.AbortWithStatusJSON(http.StatusBadRequest, &ErrorResponse{Message: ErrInvalidFormatID.Error()})
+		_ = c.Error(ErrInvalidFormatID)
 		return
 	}
 
-	wallet, err := h.services.Wallet.Get(c, parseID)
+	consistency, err := dbsource.ParseConsistency(c.Query("consistency"))
 	if err != nil {
-		log.Error(err)
-		if errors.Is(err, domain.ErrWalletNotFound) {
-			c.AbortWithStatusJSON(http.StatusNotFound, &ErrorResponse{Message: domain.ErrWalletNotFound.Error()})
-			return
-		}
-		c.AbortWithStatus(http.StatusInternalServerError)
+		_ = c.Error(&APIError{
+			Status: http.StatusBadRequest,
+			Code:   CodeValidationFailed,
+			Title:  "Validation failed",
+			Detail: err.Error(),
+			Fields: []FieldError{{Field: "consistency", Reason: "must be one of eventual strong"}},
+			Err:    err,
+		})
+		return
+	}
+
+	ctx := dbsource.Track(dbsource.WithConsistency(c.Request.Context(), consistency))
+
+	if at := c.Query("at"); at != "" {
+		h.getBalanceAt(ctx, c, parseID, at)
+		return
+	}
+
+	wallet, err := h.services.Wallet.Get(ctx, parseID)
+	if source := dbsource.Served(ctx); source != "" {
+		c.Header(headerReadSource, source)
+	}
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toGetWalletResponse(wallet))
+
+	wallet.Release()
+}
+
+// getBalanceAt answers GetWallet for a past moment.
+func (h *Handler) getBalanceAt(ctx context.Context, c *gin.Context, walletID uuid.UUID, at string) {
+	if h.services.History == nil {
+		_ = c.Error(ErrLedgerUnsupported)
+		return
+	}
+
+	invalidAt := func(reason string, err error) {
+		_ = c.Error(&APIError{
+			Status: http.StatusBadRequest,
+			Code:   CodeValidationFailed,
+			Title:  "Validation failed",
+			Detail: err.Error(),
+			Fields: []FieldError{{Field: "at", Reason: reason}},
+			Err:    err,
+		})
+	}
+
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		invalidAt("must be an RFC 3339 time", err)
+		return
+	}
+	t = t.UTC()
+
+	balance, err := h.services.History.BalanceAt(ctx, walletID, t)
+	if source := dbsource.Served(ctx); source != "" {
+		c.Header(headerReadSource, source)
+	}
+	if errors.Is(err, service.ErrFutureBalance) {
+		invalidAt("must not be in the future", err)
+		return
+	}
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, &GetWalletResponse{
-		WalletID: wallet.ID().String(),
-		Balance:  wallet.Balance(),
+		WalletID: walletID.String(),
+		Balance:  balance,
+		At:       &t,
 	})
+}
 
-	wallet.Release()
+// parseWalletField parses the wallet id in the body field named field.
+func parseWalletField(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, &APIError{
+			Status: http.StatusBadRequest,
+			Code:   CodeInvalidWalletID,
+			Title:  "Invalid wallet id",
+			Detail: ErrInvalidFormatID.Error(),
+			Fields: []FieldError{{Field: field, Reason: "must be a UUID"}},
+			Err:    err,
+		}
+	}
+	return id, nil
 }
@@ -1,5 +1,7 @@
 package handler
 
+import "time"
+
 type UpdateWalletRequest struct {
 	WalletID      string `json:"walletId" binding:"required"`
 	OperationType string `json:"operationType" binding:"required,oneof=DEPOSIT WITHDRAW"`
@@ -9,9 +11,18 @@ type UpdateWalletRequest struct {
 type UpdateWalletResponse struct {
 	WalletID   string `json:"walletId"`
 	NewBalance int64  `json:"newBalance"`
+	// Fee is charged on top of the amount and included in NewBalance.
+	Fee int64 `json:"fee,omitempty"`
 }
 
 type GetWalletResponse struct {
 	WalletID string `json:"walletId"`
 	Balance  int64  `json:"balance"`
+	// Cash, Bonus and Bonuses break the current balance down; they are left
+	// out of a historical one.
+	Cash    *int64          `json:"cash,omitempty"`
+	Bonus   *int64          `json:"bonus,omitempty"`
+	Bonuses []BonusResponse `json:"bonuses,omitempty"`
+	// At is set when the balance is a historical one.
+	At *time.Time `json:"at,omitempty"`
 }
@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrInvalidFormatID = errors.New("invalid id format: not uuid")
 	ErrPathParameterID = errors.New("path parameters: id not found")
+	ErrRouteNotFound   = errors.New("route not found")
 )
@@ -2,21 +2,32 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+	"wallet-service/internal/dbsource"
 	"wallet-service/internal/domain"
+	"wallet-service/internal/health"
 	"wallet-service/internal/service"
 	mock_service "wallet-service/internal/service/mocks"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
+var (
+	testLogger = slog.New(slog.DiscardHandler)
+	testHealth = health.New(time.Second)
+)
+
 func setupRouter(h *Handler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return h.GetRouter()
@@ -41,12 +52,12 @@ func TestUpdateWallet_CorrectDeposit_200(t *testing.T) {
 	mockWallet.
 		EXPECT().
 		Deposit(gomock.Any(), id, amount).
-		Return(wallet, nil)
+		Return(&domain.Receipt{Wallet: wallet}, nil)
 
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
@@ -75,12 +86,12 @@ func TestUpdateWallet_CorrectWithdraw_200(t *testing.T) {
 	mockWallet.
 		EXPECT().
 		Withdraw(gomock.Any(), id, amount).
-		Return(wallet, nil)
+		Return(&domain.Receipt{Wallet: wallet}, nil)
 
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
@@ -105,7 +116,7 @@ func TestUpdateWallet_InvalidWalletId_400(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
@@ -130,7 +141,7 @@ func TestUpdateWallet_InvalidOperation_400(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
@@ -155,7 +166,7 @@ func TestUpdateWallet_ZeroAmount_400(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
@@ -180,7 +191,7 @@ func TestUpdateWallet_NegativeAmount_400(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
@@ -212,7 +223,7 @@ func TestUpdateWallet_ServiceError_500(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
@@ -244,7 +255,7 @@ func TestUpdateWallet_NonExistentWallet_404(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
@@ -276,7 +287,7 @@ func TestUpdateWallet_AmountExceedsBalance_409(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", getBodyReader(t, map[string]interface{}{
@@ -309,7 +320,7 @@ func TestGetWallet_CorrectID_200(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String(), nil)
@@ -328,7 +339,7 @@ func TestGetWallet_InvalidUUID_400(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/12345", nil)
@@ -354,7 +365,7 @@ func TestGetWallet_NonExistentWallet_404(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String(), nil)
@@ -380,7 +391,7 @@ func TestGetWallet_ServiceError_500(t *testing.T) {
 	srv := service.Service{
 		Wallet: mockWallet,
 	}
-	h := NewHandler(&srv)
+	h := NewHandler(&srv, testHealth, testLogger)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String(), nil)
@@ -390,3 +401,201 @@ func TestGetWallet_ServiceError_500(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestGetWallet_StrongConsistency_PassedToServiceAndSourceReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	wallet, err := domain.NewWallet(id, 100)
+	assert.NoError(t, err)
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Get(gomock.Any(), id).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID) (*domain.Wallet, error) {
+			assert.Equal(t, dbsource.Strong, dbsource.ConsistencyFrom(ctx))
+			dbsource.Record(ctx, dbsource.Primary)
+			return wallet, nil
+		})
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String()+"?consistency=strong", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, dbsource.Primary, w.Header().Get(headerReadSource))
+}
+
+func TestGetWallet_ReplicaRead_SourceHeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	wallet, err := domain.NewWallet(id, 100)
+	assert.NoError(t, err)
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Get(gomock.Any(), id).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID) (*domain.Wallet, error) {
+			assert.Equal(t, dbsource.Eventual, dbsource.ConsistencyFrom(ctx))
+			dbsource.Record(ctx, dbsource.Replica)
+			return wallet, nil
+		})
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String(), nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, dbsource.Replica, w.Header().Get(headerReadSource))
+}
+
+func TestGetWallet_UnknownConsistency_400(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+uuid.NewString()+"?consistency=linearizable", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CodeValidationFailed, problem.Code)
+	assert.Equal(t, "consistency", problem.Errors[0].Field)
+}
+
+func TestGetWallet_At_HistoricalBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	at := time.Date(2026, 3, 3, 14, 0, 0, 0, time.UTC)
+
+	mockHistory := mock_service.NewMockHistory(ctrl)
+	mockHistory.
+		EXPECT().
+		BalanceAt(gomock.Any(), id, at).
+		Return(int64(420), nil)
+
+	// Текущий баланс не запрашивается
+	h := NewHandler(&service.Service{Wallet: mock_service.NewMockWallet(ctrl), History: mockHistory}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id.String()+"?at=2026-03-03T17:00:00%2B03:00", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var resp GetWalletResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(420), resp.Balance)
+	if assert.NotNil(t, resp.At) {
+		assert.Equal(t, at, *resp.At)
+	}
+}
+
+func TestGetWallet_AtInFuture_400(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHistory := mock_service.NewMockHistory(ctrl)
+	mockHistory.
+		EXPECT().
+		BalanceAt(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(int64(0), service.ErrFutureBalance)
+
+	h := NewHandler(&service.Service{History: mockHistory}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+uuid.NewString()+"?at=2999-01-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "at", problem.Errors[0].Field)
+}
+
+func TestGetWallet_InvalidAt_400(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewHandler(&service.Service{History: mock_service.NewMockHistory(ctrl)}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+uuid.NewString()+"?at=yesterday", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "at", problem.Errors[0].Field)
+}
+
+func TestGetWallet_AtUnsupported_501(t *testing.T) {
+	h := NewHandler(&service.Service{}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/wallets/"+uuid.NewString()+"?at=2026-10-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.Equal(t, CodeNotImplemented, problem.Code)
+}
+
+func TestUpdateWallet_WithdrawWithFee_ReportsFee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	wallet, err := domain.NewWallet(id, 880)
+	require.NoError(t, err)
+
+	mockWallet := mock_service.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		Withdraw(gomock.Any(), id, int64(100)).
+		Return(&domain.Receipt{Wallet: wallet, Fee: 20}, nil)
+
+	h := NewHandler(&service.Service{Wallet: mockWallet}, testHealth, testLogger)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/wallet", getBodyReader(t, map[string]interface{}{
+		"walletId":      id.String(),
+		"operationType": "WITHDRAW",
+		"amount":        100,
+	}))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp UpdateWalletResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(880), resp.NewBalance)
+	assert.Equal(t, int64(20), resp.Fee)
+}
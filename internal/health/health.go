@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrShuttingDown = errors.New("server is shutting down")
+
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Health tracks process readiness: registered dependency checks plus a
+// draining flag that is raised as soon as shutdown begins.
+type Health struct {
+	mu       sync.RWMutex
+	checks   []namedCheck
+	draining atomic.Bool
+	timeout  time.Duration
+}
+
+type Report struct {
+	Ready  bool
+	Checks map[string]error
+}
+
+func New(timeout time.Duration) *Health {
+	return &Health{
+		timeout: timeout,
+	}
+}
+
+func (h *Health) Register(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+func (h *Health) StartDraining() {
+	h.draining.Store(true)
+}
+
+func (h *Health) Draining() bool {
+	return h.draining.Load()
+}
+
+func (h *Health) Ready(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	report := Report{
+		Ready:  true,
+		Checks: make(map[string]error, len(checks)+1),
+	}
+
+	if h.Draining() {
+		report.Ready = false
+		report.Checks["shutdown"] = ErrShuttingDown
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = err
+			if err != nil {
+				report.Ready = false
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+func PingCheck(p Pinger) Check {
+	return p.Ping
+}
+
+type RowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const migrationVersionQuery = `SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied`
+
+// MigrationCheck fails while the database schema is behind the version
+// the binary was built with.
+func MigrationCheck(q RowQuerier, expected int64) Check {
+	return func(ctx context.Context) error {
+		var current int64
+		if err := q.QueryRow(ctx, migrationVersionQuery).Scan(&current); err != nil {
+			return err
+		}
+		if current < expected {
+			return fmt.Errorf("schema version %d is behind expected %d", current, expected)
+		}
+		return nil
+	}
+}
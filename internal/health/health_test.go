@@ -0,0 +1,54 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReady_AllChecksPass_Ready(t *testing.T) {
+	h := New(time.Second)
+	h.Register("database", func(context.Context) error { return nil })
+
+	report := h.Ready(t.Context())
+
+	assert.True(t, report.Ready)
+	assert.NoError(t, report.Checks["database"])
+}
+
+func TestReady_CheckFails_NotReady(t *testing.T) {
+	h := New(time.Second)
+	h.Register("database", func(context.Context) error { return errors.New("connection refused") })
+
+	report := h.Ready(t.Context())
+
+	assert.False(t, report.Ready)
+	assert.Error(t, report.Checks["database"])
+}
+
+func TestReady_Draining_NotReady(t *testing.T) {
+	h := New(time.Second)
+	h.Register("database", func(context.Context) error { return nil })
+
+	h.StartDraining()
+	report := h.Ready(t.Context())
+
+	assert.False(t, report.Ready)
+	assert.ErrorIs(t, report.Checks["shutdown"], ErrShuttingDown)
+}
+
+func TestReady_SlowCheck_TimesOut(t *testing.T) {
+	h := New(10 * time.Millisecond)
+	h.Register("database", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := h.Ready(t.Context())
+
+	assert.False(t, report.Ready)
+	assert.ErrorIs(t, report.Checks["database"], context.DeadlineExceeded)
+}
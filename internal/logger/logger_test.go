@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"wallet-service/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_ContextWithRequestID_AddsAttribute(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(&buf, config.LogConfig{Level: "info", Format: FormatJSON})
+	assert.NoError(t, err)
+
+	log.InfoContext(WithRequestID(context.Background(), "req-1"), "hello")
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "hello", record["msg"])
+}
+
+func TestNew_LevelWarn_DropsInfo(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(&buf, config.LogConfig{Level: "warn"})
+	assert.NoError(t, err)
+
+	log.Info("hidden")
+
+	assert.Empty(t, buf.String())
+}
+
+func TestParseLevel_Unknown_ReturnsError(t *testing.T) {
+	_, err := ParseLevel("loud")
+
+	assert.Error(t, err)
+}
+
+func TestParseLevel_Empty_ReturnsInfo(t *testing.T) {
+	level, err := ParseLevel("")
+
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelInfo, level)
+}
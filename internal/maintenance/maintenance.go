@@ -0,0 +1,81 @@
+package maintenance
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"wallet-service/internal/metrics"
+)
+
+var ErrReadOnly = errors.New("service is in read-only maintenance mode")
+
+// Mode is the process-wide read-only switch. While it is enabled mutations
+// are refused and reads may be served from a replica.
+type Mode struct {
+	mu         sync.RWMutex
+	enabled    bool
+	since      time.Time
+	retryAfter time.Duration
+}
+
+type State struct {
+	Enabled bool
+	Since   time.Time
+}
+
+func New(enabled bool, retryAfter time.Duration) *Mode {
+	m := &Mode{retryAfter: retryAfter}
+	m.Set(enabled)
+	return m
+}
+
+func (m *Mode) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.enabled
+}
+
+func (m *Mode) State() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return State{Enabled: m.enabled, Since: m.since}
+}
+
+// Set switches the mode and reports whether it changed.
+func (m *Mode) Set(enabled bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.set(enabled)
+}
+
+// Toggle flips the mode and returns the new value.
+func (m *Mode) Toggle() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(!m.enabled)
+	return m.enabled
+}
+
+func (m *Mode) set(enabled bool) bool {
+	if m.enabled == enabled && !m.since.IsZero() {
+		return false
+	}
+
+	m.enabled = enabled
+	m.since = time.Now()
+	if enabled {
+		metrics.MaintenanceMode.Set(1)
+	} else {
+		metrics.MaintenanceMode.Set(0)
+	}
+	return true
+}
+
+// RetryAfter is the delay suggested to clients whose mutation was refused.
+func (m *Mode) RetryAfter() time.Duration {
+	return m.retryAfter
+}
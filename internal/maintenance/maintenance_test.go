@@ -0,0 +1,26 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet_SameValue_NotChanged(t *testing.T) {
+	m := New(false, time.Minute)
+	since := m.State().Since
+
+	assert.False(t, m.Set(false))
+	assert.Equal(t, since, m.State().Since)
+	assert.True(t, m.Set(true))
+	assert.True(t, m.Enabled())
+}
+
+func TestToggle_Twice_BackToInitial(t *testing.T) {
+	m := New(true, time.Minute)
+
+	assert.False(t, m.Toggle())
+	assert.True(t, m.Toggle())
+	assert.True(t, m.Enabled())
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "wallet"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	WalletOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "service",
+		Name:      "operations_total",
+		Help:      "Number of wallet operations by type and outcome.",
+	}, []string{"operation", "outcome"})
+
+	TxDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "repository",
+		Name:      "tx_duration_seconds",
+		Help:      "Database transaction duration from begin to commit or rollback.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	LockWaitDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "repository",
+		Name:      "lock_wait_seconds",
+		Help:      "Time spent acquiring a wallet row lock.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	})
+
+	TxRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "repository",
+		Name:      "tx_retries_total",
+		Help:      "Number of transactions repeated after a transient failure, by reason.",
+	}, []string{"reason"})
+
+	TxAttempts = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "repository",
+		Name:      "tx_attempts",
+		Help:      "Attempts a unit of work needed, by final outcome.",
+		Buckets:   []float64{1, 2, 3, 4, 5, 7, 10},
+	}, []string{"outcome"})
+
+	ReplicaLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "repository",
+		Name:      "replica_lag_seconds",
+		Help:      "Replication lag of the read replica as last measured.",
+	})
+
+	Reads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "repository",
+		Name:      "reads_total",
+		Help:      "Number of reads outside transactions by serving source.",
+	}, []string{"source"})
+
+	MaintenanceMode = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "maintenance_mode",
+		Help:      "1 while the service is in read-only maintenance mode.",
+	})
+
+	AuditWriteFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "service",
+		Name:      "audit_write_failures_total",
+		Help:      "Audit records of failed operations that could not be written.",
+	})
+
+	ChainVerifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ledger",
+		Name:      "chain_verifications_total",
+		Help:      "Walks over transaction chains by result, intact or broken.",
+	}, []string{"result"})
+
+	Checkpoints = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ledger",
+		Name:      "checkpoints_total",
+		Help:      "Signed checkpoints exported, by outcome.",
+	}, []string{"outcome"})
+
+	LastCheckpoint = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "ledger",
+		Name:      "last_checkpoint_timestamp_seconds",
+		Help:      "Unix time of the last exported checkpoint.",
+	})
+
+	Snapshots = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ledger",
+		Name:      "snapshot_runs_total",
+		Help:      "Balance snapshot runs, by outcome.",
+	}, []string{"outcome"})
+
+	ScheduledRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "runs_total",
+		Help:      "Attempts of scheduled operations, by outcome.",
+	}, []string{"outcome"})
+
+	FeesCollected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "service",
+		Name:      "fees_collected_total",
+		Help:      "Sum of fees charged, by operation.",
+	}, []string{"operation"})
+
+	InterestPaid = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "service",
+		Name:      "interest_paid_total",
+		Help:      "Sum of interest paid out to savings wallets.",
+	})
+
+	BonusExpired = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "service",
+		Name:      "bonus_expired_total",
+		Help:      "Sum of unspent bonus removed on expiry.",
+	})
+
+	EscrowTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "service",
+		Name:      "escrow_transitions_total",
+		Help:      "Escrow transitions made, by event.",
+	}, []string{"event"})
+)
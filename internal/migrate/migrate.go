@@ -0,0 +1,136 @@
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+const (
+	dir         = "migrations"
+	testDataDir = "migrations/test"
+)
+
+// Sources returns the migrations embedded under root. With test data the
+// fixtures from migrations/test are merged in, so both sets share one
+// version sequence.
+func Sources(root fs.FS, withTestData bool) (fs.FS, error) {
+	dirs := []string{dir}
+	if withTestData {
+		dirs = append(dirs, testDataDir)
+	}
+
+	union := make(unionFS, 0, len(dirs))
+	for _, d := range dirs {
+		sub, err := fs.Sub(root, d)
+		if err != nil {
+			return nil, err
+		}
+		union = append(union, sub)
+	}
+	return union, nil
+}
+
+// NewProvider builds a goose provider over the embedded migrations. A
+// Postgres advisory lock keeps concurrent migration jobs from interleaving.
+func NewProvider(db *sql.DB, root fs.FS, withTestData bool, log *slog.Logger) (*goose.Provider, error) {
+	sources, err := Sources(root, withTestData)
+	if err != nil {
+		return nil, err
+	}
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+
+	return goose.NewProvider(goose.DialectPostgres, db, sources,
+		goose.WithSessionLocker(locker),
+		goose.WithSlog(log),
+		// Test data may be enabled on a database migrated without it.
+		goose.WithAllowOutofOrder(withTestData),
+	)
+}
+
+// LatestVersion returns the highest migration version embedded in the
+// binary, which readiness compares against the database.
+func LatestVersion(root fs.FS, withTestData bool) (int64, error) {
+	sources, err := Sources(root, withTestData)
+	if err != nil {
+		return 0, err
+	}
+
+	files, err := fs.Glob(sources, "*.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, file := range files {
+		version, err := goose.NumericComponent(file)
+		if err != nil {
+			return 0, err
+		}
+		latest = max(latest, version)
+	}
+	if latest == 0 {
+		return 0, goose.ErrNoMigrations
+	}
+
+	return latest, nil
+}
+
+// unionFS overlays several directories; the first one wins on name clashes.
+type unionFS []fs.FS
+
+func (u unionFS) Open(name string) (fs.File, error) {
+	for _, fsys := range u {
+		f, err := fsys.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (u unionFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var (
+		entries []fs.DirEntry
+		seen    = make(map[string]bool)
+		found   bool
+	)
+	for _, fsys := range u {
+		dirEntries, err := fs.ReadDir(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, entry := range dirEntries {
+			if !seen[entry.Name()] {
+				seen[entry.Name()] = true
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(path.Base(a.Name()), path.Base(b.Name()))
+	})
+	return entries, nil
+}
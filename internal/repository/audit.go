@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"wallet-service/internal/db"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Audit is the append-only audit log. Append called with a transaction
+// context is committed or rolled back together with the transaction.
+type Audit interface {
+	Append(ctx context.Context, record *domain.AuditRecord) error
+	List(ctx context.Context, filter AuditFilter) ([]domain.AuditRecord, error)
+}
+
+// AuditFilter selects audit records; zero fields match everything. Records
+// are listed newest first.
+type AuditFilter struct {
+	WalletID *uuid.UUID
+	Actor    string
+	Action   string
+	Outcome  string
+	// From is inclusive, To is exclusive.
+	From time.Time
+	To   time.Time
+	// BeforeID continues a listing after the last record of a previous page.
+	BeforeID int64
+	// Limit is the maximum number of records returned and must be positive.
+	Limit int
+}
+
+// Matches reports whether record passes the filter, ignoring Limit.
+func (f AuditFilter) Matches(record *domain.AuditRecord) bool {
+	switch {
+	case f.WalletID != nil && (record.WalletID == nil || *record.WalletID != *f.WalletID):
+		return false
+	case f.Actor != "" && record.Actor != f.Actor,
+		f.Action != "" && record.Action != f.Action,
+		f.Outcome != "" && record.Outcome != f.Outcome:
+		return false
+	case !f.From.IsZero() && record.At.Before(f.From),
+		!f.To.IsZero() && !record.At.Before(f.To):
+		return false
+	case f.BeforeID > 0 && record.ID >= f.BeforeID:
+		return false
+	default:
+		return true
+	}
+}
+
+// AuditRepository writes app.audit_log. Triggers reject UPDATE, DELETE and
+// TRUNCATE on the table, so records can only be appended.
+type AuditRepository struct {
+	TxRepositoryImpl
+}
+
+func NewAuditRepository(pool *pgxpool.Pool, queries *db.Queries) *AuditRepository {
+	return &AuditRepository{
+		TxRepositoryImpl: TxRepositoryImpl{
+			db: pool,
+			q:  queries,
+		},
+	}
+}
+
+func (r *AuditRepository) Append(ctx context.Context, record *domain.AuditRecord) (err error) {
+	ctx, span := tracer.Start(ctx, "AuditRepository.Append")
+	span.SetAttributes(attribute.String("audit.action", record.Action))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).AppendAudit(ctx, db.AppendAuditParams{
+		Actor:         record.Actor,
+		SourceIp:      record.SourceIP,
+		RequestID:     record.RequestID,
+		Action:        record.Action,
+		WalletID:      nullableUUID(record.WalletID),
+		BalanceBefore: nullableInt8(record.BalanceBefore),
+		BalanceAfter:  nullableInt8(record.BalanceAfter),
+		Outcome:       record.Outcome,
+	})
+	if err != nil {
+		return fmt.Errorf("append audit record: %w", mapPgError(err))
+	}
+
+	record.ID = row.ID
+	record.At = row.At.Time
+	return nil
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter AuditFilter) (_ []domain.AuditRecord, err error) {
+	ctx, span := tracer.Start(ctx, "AuditRepository.List")
+	defer func() { tracing.End(span, err) }()
+
+	params := db.ListAuditParams{
+		WalletID: nullableUUID(filter.WalletID),
+		Actor:    nullableText(filter.Actor),
+		Action:   nullableText(filter.Action),
+		Outcome:  nullableText(filter.Outcome),
+		FromAt:   nullableTime(filter.From),
+		ToAt:     nullableTime(filter.To),
+		RowLimit: int32(filter.Limit),
+	}
+	if filter.BeforeID > 0 {
+		params.BeforeID = pgtype.Int8{Int64: filter.BeforeID, Valid: true}
+	}
+
+	rows, err := r.getQueries(ctx).ListAudit(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("list audit records: %w", mapPgError(err))
+	}
+
+	records := make([]domain.AuditRecord, 0, len(rows))
+	for _, row := range rows {
+		record := domain.AuditRecord{
+			ID:        row.ID,
+			At:        row.At.Time,
+			Actor:     row.Actor,
+			SourceIP:  row.SourceIp,
+			RequestID: row.RequestID,
+			Action:    row.Action,
+			Outcome:   row.Outcome,
+		}
+		if row.WalletID.Valid {
+			id := uuid.UUID(row.WalletID.Bytes)
+			record.WalletID = &id
+		}
+		if row.BalanceBefore.Valid {
+			record.BalanceBefore = &row.BalanceBefore.Int64
+		}
+		if row.BalanceAfter.Valid {
+			record.BalanceAfter = &row.BalanceAfter.Int64
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"testing"
+	"wallet-service/internal/domain"
+	"wallet-service/pkg/testdb"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog_UpdateOrDelete_Rejected(t *testing.T) {
+	testdb.WithDB(t, []string{"../../migrations"}, func(pool *pgxpool.Pool) {
+		repo, err := NewPostgresRepository(pool, testLogger)
+		require.NoError(t, err)
+
+		record := &domain.AuditRecord{Actor: "auditor", Action: domain.AuditDeposit, Outcome: "success"}
+		require.NoError(t, repo.Audit.Append(t.Context(), record))
+
+		// Журнал только дополняется, даже владельцу таблицы
+		_, err = pool.Exec(t.Context(), `UPDATE app.audit_log SET outcome = 'error' WHERE id = $1`, record.ID)
+		assert.ErrorContains(t, err, "append-only")
+		_, err = pool.Exec(t.Context(), `DELETE FROM app.audit_log WHERE id = $1`, record.ID)
+		assert.ErrorContains(t, err, "append-only")
+		_, err = pool.Exec(t.Context(), `TRUNCATE app.audit_log`)
+		assert.ErrorContains(t, err, "append-only")
+
+		records, err := repo.Audit.List(t.Context(), AuditFilter{Actor: "auditor", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "success", records[0].Outcome)
+	})
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wallet-service/internal/db"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Bonuses stores the bonus buckets of wallets. Reads and writes join the
+// transaction in ctx, so the buckets change together with the balance they
+// are part of.
+type Bonuses interface {
+	// Bonuses returns the buckets of the wallet, soonest to expire first.
+	Bonuses(ctx context.Context, walletID uuid.UUID) ([]domain.Bonus, error)
+	// SaveBonuses replaces the buckets of the wallet. It returns
+	// domain.ErrWalletNotFound for an unknown wallet.
+	SaveBonuses(ctx context.Context, walletID uuid.UUID, bonuses []domain.Bonus) error
+	// Expired returns up to limit wallets with an id after after and a
+	// bucket expired by at, in id order.
+	Expired(ctx context.Context, at time.Time, after uuid.UUID, limit int) ([]uuid.UUID, error)
+}
+
+// BonusRepository keeps buckets in app.wallet_bonuses.
+type BonusRepository struct {
+	TxRepositoryImpl
+}
+
+func NewBonusRepository(pool *pgxpool.Pool, queries *db.Queries) *BonusRepository {
+	return &BonusRepository{
+		TxRepositoryImpl: TxRepositoryImpl{
+			db: pool,
+			q:  queries,
+		},
+	}
+}
+
+func (r *BonusRepository) Bonuses(ctx context.Context, walletID uuid.UUID) (_ []domain.Bonus, err error) {
+	ctx, span := tracer.Start(ctx, "BonusRepository.Bonuses")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.getQueries(ctx).ListWalletBonuses(ctx, UUIDToPgUUID(walletID))
+	if err != nil {
+		return nil, fmt.Errorf("list bonuses of wallet %s: %w", walletID, mapPgError(err))
+	}
+
+	bonuses := make([]domain.Bonus, 0, len(rows))
+	for _, row := range rows {
+		id, err := PgUUIDToUUID(row.ID)
+		if err != nil {
+			return nil, err
+		}
+		bonuses = append(bonuses, domain.Bonus{
+			ID:        id,
+			Amount:    row.Amount,
+			ExpiresAt: row.ExpiresAt.Time.UTC(),
+			GrantedAt: row.GrantedAt.Time.UTC(),
+		})
+	}
+	return bonuses, nil
+}
+
+func (r *BonusRepository) SaveBonuses(ctx context.Context, walletID uuid.UUID, bonuses []domain.Bonus) (err error) {
+	ctx, span := tracer.Start(ctx, "BonusRepository.SaveBonuses")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()), attribute.Int("wallet.bonuses", len(bonuses)))
+	defer func() { tracing.End(span, err) }()
+
+	arg := db.SaveWalletBonusesParams{
+		WalletID:  UUIDToPgUUID(walletID),
+		Ids:       make([]pgtype.UUID, 0, len(bonuses)),
+		Amounts:   make([]int64, 0, len(bonuses)),
+		ExpiresAt: make([]pgtype.Timestamptz, 0, len(bonuses)),
+		GrantedAt: make([]pgtype.Timestamptz, 0, len(bonuses)),
+	}
+	for _, b := range bonuses {
+		arg.Ids = append(arg.Ids, UUIDToPgUUID(b.ID))
+		arg.Amounts = append(arg.Amounts, b.Amount)
+		arg.ExpiresAt = append(arg.ExpiresAt, nullableTime(b.ExpiresAt))
+		arg.GrantedAt = append(arg.GrantedAt, nullableTime(b.GrantedAt))
+	}
+
+	if err = r.getQueries(ctx).SaveWalletBonuses(ctx, arg); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return domain.ErrWalletNotFound
+		}
+		return fmt.Errorf("save bonuses of wallet %s: %w", walletID, mapPgError(err))
+	}
+	return nil
+}
+
+func (r *BonusRepository) Expired(ctx context.Context, at time.Time, after uuid.UUID, limit int) (_ []uuid.UUID, err error) {
+	ctx, span := tracer.Start(ctx, "BonusRepository.Expired")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.getQueries(ctx).ListExpiredBonusWallets(ctx, db.ListExpiredBonusWalletsParams{
+		ExpiresAt: nullableTime(at),
+		WalletID:  UUIDToPgUUID(after),
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list wallets with expired bonuses: %w", mapPgError(err))
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		id, err := PgUUIDToUUID(row)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,51 @@
+package repository_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+	"github.com/ydb-platform/ydb-go-sdk/v3/balancers"
+)
+
+func TestPostgres_Conformance(t *testing.T) {
+	testdb.WithDB(t, []string{"../../migrations"}, func(pool *pgxpool.Pool) {
+		repo, err := repository.NewPostgresRepository(pool, slog.New(slog.DiscardHandler))
+		require.NoError(t, err)
+
+		repositorytest.Run(t, repo.Wallet)
+		repositorytest.RunAudit(t, repo.Wallet, repo.Audit)
+		repositorytest.RunLedger(t, repo.Wallet, repo.Ledger)
+		repositorytest.RunSchedules(t, repo.Wallet, repo.Schedules)
+		repositorytest.RunTiers(t, repo.Wallet, repo.Tiers)
+		repositorytest.RunInterest(t, repo.Wallet, repo.Interest)
+		repositorytest.RunBonuses(t, repo.Wallet, repo.Bonuses)
+		repositorytest.RunEscrows(t, repo.Wallet, repo.Escrows)
+	})
+}
+
+func TestYDB_Conformance(t *testing.T) {
+	testdb.WithYDB(t, func(dsn string) {
+		cfg := config.DatabaseConfig{YDBDSN: dsn, ApplicationName: "wallet-service-test", ConnectTimeout: time.Minute}
+		y, err := repository.OpenYDB(t.Context(), cfg, ydb.WithBalancer(balancers.SingleConn()))
+		require.NoError(t, err)
+		defer func() { _ = y.Close(context.Background()) }()
+
+		require.NoError(t, repository.BootstrapYDBSchema(t.Context(), y.DB))
+		// Повторный запуск не должен падать на уже созданной таблице
+		require.NoError(t, repository.BootstrapYDBSchema(t.Context(), y.DB))
+
+		repo := repository.NewYDBRepository(y.DB, slog.New(slog.DiscardHandler))
+		repositorytest.Run(t, repo.Wallet, repositorytest.Optimistic())
+		repositorytest.RunAudit(t, repo.Wallet, repo.Audit)
+		repositorytest.RunLedger(t, repo.Wallet, repo.Ledger)
+	})
+}
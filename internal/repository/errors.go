@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"wallet-service/internal/domain"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// mapPgError translates timeouts and transaction conflicts reported by
+// Postgres into the errors callers react to while keeping the original error
+// in the chain.
+func mapPgError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case pgerrcode.LockNotAvailable:
+		return fmt.Errorf("%w: %w", domain.ErrWalletBusy, err)
+	case pgerrcode.QueryCanceled:
+		return fmt.Errorf("%w: %w", domain.ErrTimeout, err)
+	case pgerrcode.SerializationFailure, pgerrcode.DeadlockDetected:
+		return fmt.Errorf("%w: %w", ErrTxConflict, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/domain"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapPgError_LockNotAvailable_ReturnsWalletBusy(t *testing.T) {
+	err := mapPgError(&pgconn.PgError{Code: pgerrcode.LockNotAvailable})
+
+	assert.ErrorIs(t, err, domain.ErrWalletBusy)
+	var pgErr *pgconn.PgError
+	assert.True(t, errors.As(err, &pgErr))
+}
+
+func TestMapPgError_QueryCanceled_ReturnsTimeout(t *testing.T) {
+	assert.ErrorIs(t, mapPgError(&pgconn.PgError{Code: pgerrcode.QueryCanceled}), domain.ErrTimeout)
+}
+
+func TestMapPgError_SerializationFailureAndDeadlock_ReturnsTxConflict(t *testing.T) {
+	for _, code := range []string{pgerrcode.SerializationFailure, pgerrcode.DeadlockDetected} {
+		assert.ErrorIs(t, mapPgError(&pgconn.PgError{Code: code}), ErrTxConflict, code)
+	}
+}
+
+func TestMapPgError_OtherError_Unchanged(t *testing.T) {
+	err := errors.New("connection refused")
+
+	assert.Equal(t, err, mapPgError(err))
+}
+
+func TestNewPoolConfig_Timeouts_SetAsRuntimeParams(t *testing.T) {
+	cfg := config.DatabaseConfig{
+		Host:             "localhost",
+		Port:             5432,
+		User:             "wallet",
+		Name:             "wallet",
+		SSLMode:          "disable",
+		Pool:             config.PoolConfig{MaxConns: 8, MinConns: 2, MaxConnLifetime: time.Hour},
+		StatementTimeout: 30 * time.Second,
+		LockTimeout:      1500 * time.Millisecond,
+	}
+
+	poolConfig, err := NewPoolConfig(cfg)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 8, poolConfig.MaxConns)
+	assert.EqualValues(t, 2, poolConfig.MinConns)
+	assert.Equal(t, time.Hour, poolConfig.MaxConnLifetime)
+	assert.Equal(t, "30000", poolConfig.ConnConfig.RuntimeParams["statement_timeout"])
+	assert.Equal(t, "1500", poolConfig.ConnConfig.RuntimeParams["lock_timeout"])
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wallet-service/internal/db"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Escrows stores escrows with the transitions that brought them to their
+// status. Reads and writes join the transaction in ctx, so an escrow moves
+// together with the funds it releases or refunds.
+type Escrows interface {
+	// Create stores a new escrow with the transition that created it. It
+	// returns domain.ErrWalletNotFound when a party is unknown.
+	Create(ctx context.Context, escrow *domain.Escrow, created domain.EscrowTransition) error
+	// Get and GetForUpdate return domain.ErrEscrowNotFound for an unknown
+	// escrow; GetForUpdate locks it until the transaction ends.
+	Get(ctx context.Context, id uuid.UUID) (*domain.Escrow, error)
+	GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.Escrow, error)
+	// Update stores the status of escrow and records the transition to it.
+	Update(ctx context.Context, escrow *domain.Escrow, transition domain.EscrowTransition) error
+	// Transitions returns the transitions of the escrow in the order they
+	// were made.
+	Transitions(ctx context.Context, id uuid.UUID) ([]domain.EscrowTransition, error)
+	// Expired returns up to limit escrows with an id after after that are
+	// not final and expired by at, in id order.
+	Expired(ctx context.Context, at time.Time, after uuid.UUID, limit int) ([]uuid.UUID, error)
+}
+
+// EscrowRepository keeps escrows in app.escrows and app.escrow_transitions.
+type EscrowRepository struct {
+	TxRepositoryImpl
+}
+
+func NewEscrowRepository(pool *pgxpool.Pool, queries *db.Queries) *EscrowRepository {
+	return &EscrowRepository{
+		TxRepositoryImpl: TxRepositoryImpl{
+			db: pool,
+			q:  queries,
+		},
+	}
+}
+
+func (r *EscrowRepository) Create(ctx context.Context, escrow *domain.Escrow, created domain.EscrowTransition) (err error) {
+	ctx, span := tracer.Start(ctx, "EscrowRepository.Create")
+	span.SetAttributes(attribute.String("escrow.id", escrow.ID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	err = r.getQueries(ctx).CreateEscrow(ctx, db.CreateEscrowParams{
+		ID:        UUIDToPgUUID(escrow.ID),
+		BuyerID:   UUIDToPgUUID(escrow.BuyerID),
+		SellerID:  UUIDToPgUUID(escrow.SellerID),
+		Amount:    escrow.Amount,
+		Status:    escrow.Status,
+		ExpiresAt: nullableTime(escrow.ExpiresAt),
+		CreatedAt: nullableTime(escrow.CreatedAt),
+		UpdatedAt: nullableTime(escrow.UpdatedAt),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return domain.ErrWalletNotFound
+		}
+		return fmt.Errorf("create escrow %s: %w", escrow.ID, mapPgError(err))
+	}
+	return r.appendTransition(ctx, created)
+}
+
+func (r *EscrowRepository) Get(ctx context.Context, id uuid.UUID) (_ *domain.Escrow, err error) {
+	ctx, span := tracer.Start(ctx, "EscrowRepository.Get")
+	span.SetAttributes(attribute.String("escrow.id", id.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).GetEscrow(ctx, UUIDToPgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrEscrowNotFound
+		}
+		return nil, fmt.Errorf("get escrow %s: %w", id, mapPgError(err))
+	}
+	return pgEscrowToDomain(&row)
+}
+
+func (r *EscrowRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (_ *domain.Escrow, err error) {
+	ctx, span := tracer.Start(ctx, "EscrowRepository.GetForUpdate")
+	span.SetAttributes(attribute.String("escrow.id", id.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).GetEscrowForUpdate(ctx, UUIDToPgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrEscrowNotFound
+		}
+		return nil, fmt.Errorf("get escrow %s for update: %w", id, mapPgError(err))
+	}
+	return pgEscrowToDomain(&row)
+}
+
+func (r *EscrowRepository) Update(ctx context.Context, escrow *domain.Escrow, transition domain.EscrowTransition) (err error) {
+	ctx, span := tracer.Start(ctx, "EscrowRepository.Update")
+	span.SetAttributes(attribute.String("escrow.id", escrow.ID.String()), attribute.String("escrow.status", escrow.Status))
+	defer func() { tracing.End(span, err) }()
+
+	err = r.getQueries(ctx).UpdateEscrowStatus(ctx, db.UpdateEscrowStatusParams{
+		ID:        UUIDToPgUUID(escrow.ID),
+		Status:    escrow.Status,
+		UpdatedAt: nullableTime(escrow.UpdatedAt),
+	})
+	if err != nil {
+		return fmt.Errorf("update escrow %s: %w", escrow.ID, mapPgError(err))
+	}
+	return r.appendTransition(ctx, transition)
+}
+
+func (r *EscrowRepository) appendTransition(ctx context.Context, t domain.EscrowTransition) error {
+	err := r.getQueries(ctx).AppendEscrowTransition(ctx, db.AppendEscrowTransitionParams{
+		EscrowID:   UUIDToPgUUID(t.EscrowID),
+		Event:      t.Event,
+		FromStatus: t.From,
+		ToStatus:   t.To,
+		Actor:      t.Actor,
+		At:         nullableTime(t.At),
+	})
+	if err != nil {
+		return fmt.Errorf("record %s of escrow %s: %w", t.Event, t.EscrowID, mapPgError(err))
+	}
+	return nil
+}
+
+func (r *EscrowRepository) Transitions(ctx context.Context, id uuid.UUID) (_ []domain.EscrowTransition, err error) {
+	ctx, span := tracer.Start(ctx, "EscrowRepository.Transitions")
+	span.SetAttributes(attribute.String("escrow.id", id.String()))
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.getQueries(ctx).ListEscrowTransitions(ctx, UUIDToPgUUID(id))
+	if err != nil {
+		return nil, fmt.Errorf("list transitions of escrow %s: %w", id, mapPgError(err))
+	}
+
+	transitions := make([]domain.EscrowTransition, 0, len(rows))
+	for _, row := range rows {
+		transitions = append(transitions, domain.EscrowTransition{
+			EscrowID: id,
+			Event:    row.Event,
+			From:     row.FromStatus,
+			To:       row.ToStatus,
+			Actor:    row.Actor,
+			At:       row.At.Time.UTC(),
+		})
+	}
+	return transitions, nil
+}
+
+func (r *EscrowRepository) Expired(ctx context.Context, at time.Time, after uuid.UUID, limit int) (_ []uuid.UUID, err error) {
+	ctx, span := tracer.Start(ctx, "EscrowRepository.Expired")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.getQueries(ctx).ListExpiredEscrows(ctx, db.ListExpiredEscrowsParams{
+		ExpiresAt: nullableTime(at),
+		ID:        UUIDToPgUUID(after),
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list expired escrows: %w", mapPgError(err))
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		id, err := PgUUIDToUUID(row)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func pgEscrowToDomain(row *db.AppEscrow) (*domain.Escrow, error) {
+	id, err := PgUUIDToUUID(row.ID)
+	if err != nil {
+		return nil, err
+	}
+	buyerID, err := PgUUIDToUUID(row.BuyerID)
+	if err != nil {
+		return nil, err
+	}
+	sellerID, err := PgUUIDToUUID(row.SellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Escrow{
+		ID:        id,
+		BuyerID:   buyerID,
+		SellerID:  sellerID,
+		Amount:    row.Amount,
+		Status:    row.Status,
+		ExpiresAt: row.ExpiresAt.Time.UTC(),
+		CreatedAt: row.CreatedAt.Time.UTC(),
+		UpdatedAt: row.UpdatedAt.Time.UTC(),
+	}, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wallet-service/internal/db"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Interest stores the interest accounts of savings wallets together with
+// their daily accruals and monthly payouts. Accrue and Pay move an account
+// on only from the day or month they are given, so a job that repeats them
+// after a crash or alongside another job changes nothing.
+type Interest interface {
+	// SetRate sets the annual rate of the wallet in basis points. A new
+	// account starts accruing on firstDay and pays out firstPeriod first.
+	// It returns domain.ErrWalletNotFound for an unknown wallet.
+	SetRate(ctx context.Context, walletID uuid.UUID, rate int64, firstDay, firstPeriod, at time.Time) (*domain.InterestAccount, error)
+	// Account returns domain.ErrInterestAccountNotFound for a wallet
+	// without an interest rate.
+	Account(ctx context.Context, walletID uuid.UUID) (*domain.InterestAccount, error)
+	// Accounts returns up to limit accounts with a wallet id after after,
+	// in wallet id order.
+	Accounts(ctx context.Context, after uuid.UUID, limit int) ([]domain.InterestAccount, error)
+	// Accrue records the accrual of accrual.Day and moves the account to
+	// next. It reports false when the day has been accrued already.
+	Accrue(ctx context.Context, accrual *domain.InterestAccrual, next, at time.Time) (bool, error)
+	// Accrued sums the accruals of the wallet on the days in [from, to).
+	Accrued(ctx context.Context, walletID uuid.UUID, from, to time.Time) (int64, error)
+	// Pay records the payout of payout.Period and moves the account to
+	// next. It reports false when the period has been paid already or is
+	// not fully accrued.
+	Pay(ctx context.Context, payout *domain.InterestPayout, next time.Time) (bool, error)
+}
+
+// InterestRepository keeps interest in app.interest_accounts,
+// app.interest_accruals and app.interest_payouts.
+type InterestRepository struct {
+	TxRepositoryImpl
+}
+
+func NewInterestRepository(pool *pgxpool.Pool, queries *db.Queries) *InterestRepository {
+	return &InterestRepository{
+		TxRepositoryImpl: TxRepositoryImpl{
+			db: pool,
+			q:  queries,
+		},
+	}
+}
+
+func (r *InterestRepository) SetRate(ctx context.Context, walletID uuid.UUID, rate int64, firstDay, firstPeriod, at time.Time) (_ *domain.InterestAccount, err error) {
+	ctx, span := tracer.Start(ctx, "InterestRepository.SetRate")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).SetInterestRate(ctx, db.SetInterestRateParams{
+		WalletID:      UUIDToPgUUID(walletID),
+		Rate:          rate,
+		NextAccrualAt: nullableTime(firstDay),
+		NextPayoutAt:  nullableTime(firstPeriod),
+		UpdatedAt:     nullableTime(at),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return nil, domain.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("set interest rate of wallet %s: %w", walletID, mapPgError(err))
+	}
+	return pgInterestAccountToDomain(&row)
+}
+
+func (r *InterestRepository) Account(ctx context.Context, walletID uuid.UUID) (_ *domain.InterestAccount, err error) {
+	ctx, span := tracer.Start(ctx, "InterestRepository.Account")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).GetInterestAccount(ctx, UUIDToPgUUID(walletID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInterestAccountNotFound
+		}
+		return nil, fmt.Errorf("get interest account of wallet %s: %w", walletID, mapPgError(err))
+	}
+	return pgInterestAccountToDomain(&row)
+}
+
+func (r *InterestRepository) Accounts(ctx context.Context, after uuid.UUID, limit int) (_ []domain.InterestAccount, err error) {
+	ctx, span := tracer.Start(ctx, "InterestRepository.Accounts")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.getQueries(ctx).ListInterestAccounts(ctx, db.ListInterestAccountsParams{
+		WalletID: UUIDToPgUUID(after),
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list interest accounts: %w", mapPgError(err))
+	}
+
+	accounts := make([]domain.InterestAccount, 0, len(rows))
+	for i := range rows {
+		account, err := pgInterestAccountToDomain(&rows[i])
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+	return accounts, nil
+}
+
+func (r *InterestRepository) Accrue(ctx context.Context, accrual *domain.InterestAccrual, next, at time.Time) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "InterestRepository.Accrue")
+	span.SetAttributes(attribute.String("wallet.id", accrual.WalletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	n, err := r.getQueries(ctx).AccrueInterest(ctx, db.AccrueInterestParams{
+		Amount:        accrual.Amount,
+		NextAccrualAt: nullableTime(next),
+		UpdatedAt:     nullableTime(at),
+		WalletID:      UUIDToPgUUID(accrual.WalletID),
+		Day:           nullableTime(accrual.Day),
+		Balance:       accrual.Balance,
+		Rate:          accrual.Rate,
+	})
+	if err != nil {
+		return false, fmt.Errorf("accrue interest of wallet %s: %w", accrual.WalletID, mapPgError(err))
+	}
+	return n > 0, nil
+}
+
+func (r *InterestRepository) Accrued(ctx context.Context, walletID uuid.UUID, from, to time.Time) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "InterestRepository.Accrued")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	sum, err := r.getQueries(ctx).SumInterestAccruals(ctx, db.SumInterestAccrualsParams{
+		WalletID: UUIDToPgUUID(walletID),
+		FromDay:  nullableTime(from),
+		ToDay:    nullableTime(to),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("sum interest accruals of wallet %s: %w", walletID, mapPgError(err))
+	}
+	return sum, nil
+}
+
+func (r *InterestRepository) Pay(ctx context.Context, payout *domain.InterestPayout, next time.Time) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "InterestRepository.Pay")
+	span.SetAttributes(attribute.String("wallet.id", payout.WalletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	n, err := r.getQueries(ctx).PayInterest(ctx, db.PayInterestParams{
+		Amount:       payout.Amount,
+		NextPayoutAt: nullableTime(next),
+		PaidAt:       nullableTime(payout.PaidAt),
+		WalletID:     UUIDToPgUUID(payout.WalletID),
+		Period:       nullableTime(payout.Period),
+	})
+	if err != nil {
+		return false, fmt.Errorf("pay interest of wallet %s: %w", payout.WalletID, mapPgError(err))
+	}
+	return n > 0, nil
+}
+
+func pgInterestAccountToDomain(row *db.AppInterestAccount) (*domain.InterestAccount, error) {
+	walletID, err := PgUUIDToUUID(row.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.InterestAccount{
+		WalletID:      walletID,
+		Rate:          row.Rate,
+		Accrued:       row.Accrued,
+		NextAccrualAt: row.NextAccrualAt.Time.UTC(),
+		NextPayoutAt:  row.NextPayoutAt.Time.UTC(),
+		CreatedAt:     row.CreatedAt.Time.UTC(),
+		UpdatedAt:     row.UpdatedAt.Time.UTC(),
+	}, nil
+}
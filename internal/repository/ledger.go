@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wallet-service/internal/db"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Ledger stores the transactions of every wallet as a hash chain. Append
+// called with a transaction context is committed or rolled back together
+// with the balance change; the caller holds the wallet row lock, which keeps
+// the chain of a wallet linear.
+type Ledger interface {
+	Append(ctx context.Context, tx *domain.Transaction) error
+	// Last returns the latest transaction of the wallet, or nil when it has
+	// none.
+	Last(ctx context.Context, walletID uuid.UUID) (*domain.Transaction, error)
+	// LastBefore returns the latest transaction of the wallet made before
+	// at, or nil when there is none.
+	LastBefore(ctx context.Context, walletID uuid.UUID, at time.Time) (*domain.Transaction, error)
+	// List returns up to limit transactions of the wallet following afterSeq
+	// in chain order.
+	List(ctx context.Context, walletID uuid.UUID, afterSeq int64, limit int) ([]domain.Transaction, error)
+	// WalletIDs pages through all wallets in id order, starting after after.
+	WalletIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error)
+	// SaveSnapshot stores a balance snapshot; saving one that exists is a
+	// no-op.
+	SaveSnapshot(ctx context.Context, snapshot *domain.BalanceSnapshot) error
+	// SnapshotBefore returns the latest snapshot of the wallet made before
+	// at, or nil when there is none.
+	SnapshotBefore(ctx context.Context, walletID uuid.UUID, at time.Time) (*domain.BalanceSnapshot, error)
+}
+
+type LedgerRepository struct {
+	TxRepositoryImpl
+}
+
+func NewLedgerRepository(pool *pgxpool.Pool, queries *db.Queries) *LedgerRepository {
+	return &LedgerRepository{
+		TxRepositoryImpl: TxRepositoryImpl{
+			db: pool,
+			q:  queries,
+		},
+	}
+}
+
+func (r *LedgerRepository) Append(ctx context.Context, tx *domain.Transaction) (err error) {
+	ctx, span := tracer.Start(ctx, "LedgerRepository.Append")
+	span.SetAttributes(attribute.String("wallet.id", tx.WalletID.String()), attribute.Int64("ledger.seq", tx.Seq))
+	defer func() { tracing.End(span, err) }()
+
+	err = r.getQueries(ctx).AppendTransaction(ctx, db.AppendTransactionParams{
+		WalletID:     UUIDToPgUUID(tx.WalletID),
+		Seq:          tx.Seq,
+		Kind:         tx.Kind,
+		Amount:       tx.Amount,
+		BalanceAfter: tx.BalanceAfter,
+		CreatedAt:    nullableTime(tx.At),
+		PrevHash:     tx.PrevHash[:],
+		Hash:         tx.Hash[:],
+	})
+	if err != nil {
+		return fmt.Errorf("append transaction %d of wallet %s: %w", tx.Seq, tx.WalletID, mapPgError(err))
+	}
+	return nil
+}
+
+func (r *LedgerRepository) Last(ctx context.Context, walletID uuid.UUID) (_ *domain.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "LedgerRepository.Last")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).LastTransaction(ctx, UUIDToPgUUID(walletID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get last transaction of wallet %s: %w", walletID, mapPgError(err))
+	}
+	return pgTransactionToDomain(&row)
+}
+
+func (r *LedgerRepository) LastBefore(ctx context.Context, walletID uuid.UUID, at time.Time) (_ *domain.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "LedgerRepository.LastBefore")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).LastTransactionBefore(ctx, db.LastTransactionBeforeParams{
+		WalletID:  UUIDToPgUUID(walletID),
+		CreatedAt: pgtype.Timestamptz{Time: at, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get transaction of wallet %s before %s: %w", walletID, at, mapPgError(err))
+	}
+	return pgTransactionToDomain(&row)
+}
+
+func (r *LedgerRepository) List(ctx context.Context, walletID uuid.UUID, afterSeq int64, limit int) (_ []domain.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "LedgerRepository.List")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.getQueries(ctx).ListTransactions(ctx, db.ListTransactionsParams{
+		WalletID: UUIDToPgUUID(walletID),
+		Seq:      afterSeq,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list transactions of wallet %s: %w", walletID, mapPgError(err))
+	}
+
+	txs := make([]domain.Transaction, 0, len(rows))
+	for i := range rows {
+		tx, err := pgTransactionToDomain(&rows[i])
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, *tx)
+	}
+	return txs, nil
+}
+
+func (r *LedgerRepository) WalletIDs(ctx context.Context, after uuid.UUID, limit int) (_ []uuid.UUID, err error) {
+	ctx, span := tracer.Start(ctx, "LedgerRepository.WalletIDs")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.getQueries(ctx).ListWalletIDs(ctx, db.ListWalletIDsParams{
+		ID:    UUIDToPgUUID(after),
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list wallets: %w", mapPgError(err))
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		id, err := PgUUIDToUUID(row)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *LedgerRepository) SaveSnapshot(ctx context.Context, snapshot *domain.BalanceSnapshot) (err error) {
+	ctx, span := tracer.Start(ctx, "LedgerRepository.SaveSnapshot")
+	span.SetAttributes(attribute.String("wallet.id", snapshot.WalletID.String()), attribute.Int64("ledger.seq", snapshot.Seq))
+	defer func() { tracing.End(span, err) }()
+
+	err = r.getQueries(ctx).SaveSnapshot(ctx, db.SaveSnapshotParams{
+		WalletID: UUIDToPgUUID(snapshot.WalletID),
+		Seq:      snapshot.Seq,
+		Balance:  snapshot.Balance,
+		At:       nullableTime(snapshot.At),
+	})
+	if err != nil {
+		return fmt.Errorf("save snapshot %d of wallet %s: %w", snapshot.Seq, snapshot.WalletID, mapPgError(err))
+	}
+	return nil
+}
+
+func (r *LedgerRepository) SnapshotBefore(ctx context.Context, walletID uuid.UUID, at time.Time) (_ *domain.BalanceSnapshot, err error) {
+	ctx, span := tracer.Start(ctx, "LedgerRepository.SnapshotBefore")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).LastSnapshotBefore(ctx, db.LastSnapshotBeforeParams{
+		WalletID: UUIDToPgUUID(walletID),
+		At:       pgtype.Timestamptz{Time: at, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get snapshot of wallet %s before %s: %w", walletID, at, mapPgError(err))
+	}
+
+	id, err := PgUUIDToUUID(row.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.BalanceSnapshot{WalletID: id, Seq: row.Seq, Balance: row.Balance, At: row.At.Time.UTC()}, nil
+}
+
+func pgTransactionToDomain(row *db.AppWalletTransaction) (*domain.Transaction, error) {
+	walletID, err := PgUUIDToUUID(row.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &domain.Transaction{
+		WalletID:     walletID,
+		Seq:          row.Seq,
+		Kind:         row.Kind,
+		Amount:       row.Amount,
+		BalanceAfter: row.BalanceAfter,
+		At:           row.CreatedAt.Time.UTC(),
+	}
+	// Hashes of a wrong length are left short and fail verification.
+	copy(tx.PrevHash[:], row.PrevHash)
+	copy(tx.Hash[:], row.Hash)
+	return tx, nil
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+)
+
+// AuditLog is the audit log kept next to the wallets of a WalletRepository.
+// Records appended in a transaction become visible when it commits.
+type AuditLog struct {
+	r *WalletRepository
+}
+
+func (r *WalletRepository) AuditLog() *AuditLog {
+	return &AuditLog{r: r}
+}
+
+func (a *AuditLog) Append(ctx context.Context, record *domain.AuditRecord) error {
+	r := a.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.txFrom(ctx)
+	if t != nil && t.done {
+		return repository.ErrTxClosed
+	}
+
+	r.auditSeq++
+	record.ID = r.auditSeq
+	record.At = time.Now()
+
+	if t != nil {
+		t.audit = append(t.audit, *record)
+	} else {
+		r.audit = append(r.audit, *record)
+	}
+	return nil
+}
+
+func (a *AuditLog) List(_ context.Context, filter repository.AuditFilter) ([]domain.AuditRecord, error) {
+	r := a.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var records []domain.AuditRecord
+	for i := range r.audit {
+		if filter.Matches(&r.audit[i]) {
+			records = append(records, r.audit[i])
+		}
+	}
+	slices.SortFunc(records, func(a, b domain.AuditRecord) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[:filter.Limit]
+	}
+	return records, nil
+}
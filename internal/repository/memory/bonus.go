@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Bonuses keeps the bonus buckets of the wallets of a WalletRepository.
+// Unlike the other side stores they are transactional: a transaction sees
+// its own buckets and the others see them once it commits.
+type Bonuses struct {
+	r *WalletRepository
+}
+
+func (r *WalletRepository) Bonuses() *Bonuses {
+	return &Bonuses{r: r}
+}
+
+func (b *Bonuses) Bonuses(ctx context.Context, walletID uuid.UUID) ([]domain.Bonus, error) {
+	r := b.r
+	t := r.txFrom(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t != nil {
+		if t.done {
+			return nil, repository.ErrTxClosed
+		}
+		if bonuses, ok := t.bonuses[walletID]; ok {
+			return slices.Clone(bonuses), nil
+		}
+	}
+	return slices.Clone(r.bonuses[walletID]), nil
+}
+
+func (b *Bonuses) SaveBonuses(ctx context.Context, walletID uuid.UUID, bonuses []domain.Bonus) error {
+	r := b.r
+	t := r.txFrom(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t != nil && t.done {
+		return repository.ErrTxClosed
+	}
+	if _, ok := r.read(t, walletID); !ok {
+		return domain.ErrWalletNotFound
+	}
+
+	// Stored in the order Postgres lists them: soonest expiry first.
+	bonuses = slices.Clone(bonuses)
+	slices.SortFunc(bonuses, func(a, b domain.Bonus) int {
+		if c := a.ExpiresAt.Compare(b.ExpiresAt); c != 0 {
+			return c
+		}
+		return slices.Compare(a.ID[:], b.ID[:])
+	})
+
+	if t == nil {
+		r.bonuses[walletID] = bonuses
+		return nil
+	}
+	if t.bonuses == nil {
+		t.bonuses = make(map[uuid.UUID][]domain.Bonus)
+	}
+	t.bonuses[walletID] = bonuses
+	return nil
+}
+
+func (b *Bonuses) Expired(_ context.Context, at time.Time, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	r := b.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []uuid.UUID
+	for id, bonuses := range r.bonuses {
+		if slices.Compare(id[:], after[:]) <= 0 {
+			continue
+		}
+		if slices.ContainsFunc(bonuses, func(b domain.Bonus) bool { return !b.ExpiresAt.After(at) }) {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	return ids[:min(limit, len(ids))], nil
+}
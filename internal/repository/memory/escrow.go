@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Escrows keeps the escrows of a WalletRepository. Like bonuses they are
+// transactional, and GetForUpdate takes a row lock on the escrow.
+type Escrows struct {
+	r *WalletRepository
+}
+
+func (r *WalletRepository) Escrows() *Escrows {
+	return &Escrows{r: r}
+}
+
+func (e *Escrows) Create(ctx context.Context, escrow *domain.Escrow, created domain.EscrowTransition) error {
+	return e.write(ctx, escrow, created, func(t *tx, _ bool) error {
+		if _, ok := e.r.read(t, escrow.BuyerID); !ok {
+			return domain.ErrWalletNotFound
+		}
+		if _, ok := e.r.read(t, escrow.SellerID); !ok {
+			return domain.ErrWalletNotFound
+		}
+		return nil
+	})
+}
+
+func (e *Escrows) Update(ctx context.Context, escrow *domain.Escrow, transition domain.EscrowTransition) error {
+	return e.write(ctx, escrow, transition, func(_ *tx, exists bool) error {
+		if !exists {
+			return domain.ErrEscrowNotFound
+		}
+		return nil
+	})
+}
+
+// write stores escrow with transition under a row lock. Outside a
+// transaction it runs in its own transaction that is committed straight
+// away.
+func (e *Escrows) write(ctx context.Context, escrow *domain.Escrow, transition domain.EscrowTransition, check func(t *tx, exists bool) error) (err error) {
+	r := e.r
+	t := r.txFrom(ctx)
+	if t == nil {
+		var autocommit repository.WalletTx
+		ctx, autocommit, err = r.WithTx(ctx)
+		if err != nil {
+			return err
+		}
+		t = r.txFrom(ctx)
+		defer func() {
+			if err != nil {
+				_ = autocommit.Rollback(ctx)
+				return
+			}
+			err = autocommit.Commit(ctx)
+		}()
+	}
+
+	if _, err = r.lock(ctx, t, escrow.ID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.readEscrow(t, escrow.ID)
+	if err = check(t, exists); err != nil {
+		return err
+	}
+	if t.escrows == nil {
+		t.escrows = make(map[uuid.UUID]domain.Escrow)
+	}
+	t.escrows[escrow.ID] = *escrow
+	t.escrowTransitions = append(t.escrowTransitions, transition)
+	return nil
+}
+
+func (e *Escrows) Get(ctx context.Context, id uuid.UUID) (*domain.Escrow, error) {
+	r := e.r
+	t := r.txFrom(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t != nil && t.done {
+		return nil, repository.ErrTxClosed
+	}
+	escrow, ok := r.readEscrow(t, id)
+	if !ok {
+		return nil, domain.ErrEscrowNotFound
+	}
+	return &escrow, nil
+}
+
+func (e *Escrows) GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.Escrow, error) {
+	r := e.r
+	t := r.txFrom(ctx)
+	if t == nil {
+		return e.Get(ctx, id)
+	}
+
+	acquired, err := r.lock(ctx, t, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	escrow, ok := r.readEscrow(t, id)
+	if !ok {
+		if acquired {
+			r.unlock(t, id)
+		}
+		return nil, domain.ErrEscrowNotFound
+	}
+	return &escrow, nil
+}
+
+func (e *Escrows) Transitions(ctx context.Context, id uuid.UUID) ([]domain.EscrowTransition, error) {
+	r := e.r
+	t := r.txFrom(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transitions := slices.Clone(r.escrowTransitions[id])
+	if t != nil {
+		for _, tr := range t.escrowTransitions {
+			if tr.EscrowID == id {
+				transitions = append(transitions, tr)
+			}
+		}
+	}
+	return transitions, nil
+}
+
+func (e *Escrows) Expired(_ context.Context, at time.Time, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	r := e.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []uuid.UUID
+	for id, escrow := range r.escrows {
+		if slices.Compare(id[:], after[:]) <= 0 {
+			continue
+		}
+		if !escrow.Final() && !escrow.ExpiresAt.After(at) {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	return ids[:min(limit, len(ids))], nil
+}
+
+// readEscrow returns the escrow visible to t, or the committed one when t is
+// nil. The caller must hold r.mu.
+func (r *WalletRepository) readEscrow(t *tx, id uuid.UUID) (domain.Escrow, bool) {
+	if t != nil {
+		if escrow, ok := t.escrows[id]; ok {
+			return escrow, true
+		}
+	}
+	escrow, ok := r.escrows[id]
+	return escrow, ok
+}
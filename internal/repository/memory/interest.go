@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+	"wallet-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Interest keeps the interest accounts of the wallets of a
+// WalletRepository. Changes take effect at once, outside any transaction.
+type Interest struct {
+	r *WalletRepository
+}
+
+type interestKey struct {
+	walletID uuid.UUID
+	at       time.Time
+}
+
+func (r *WalletRepository) Interest() *Interest {
+	return &Interest{r: r}
+}
+
+func (i *Interest) SetRate(_ context.Context, walletID uuid.UUID, rate int64, firstDay, firstPeriod, at time.Time) (*domain.InterestAccount, error) {
+	r := i.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.wallets[walletID]; !ok {
+		return nil, domain.ErrWalletNotFound
+	}
+	account, ok := r.interest[walletID]
+	if !ok {
+		account = &domain.InterestAccount{
+			WalletID:      walletID,
+			NextAccrualAt: firstDay,
+			NextPayoutAt:  firstPeriod,
+			CreatedAt:     at,
+		}
+		r.interest[walletID] = account
+	}
+	account.Rate = rate
+	account.UpdatedAt = at
+
+	result := *account
+	return &result, nil
+}
+
+func (i *Interest) Account(_ context.Context, walletID uuid.UUID) (*domain.InterestAccount, error) {
+	r := i.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.interest[walletID]
+	if !ok {
+		return nil, domain.ErrInterestAccountNotFound
+	}
+	result := *account
+	return &result, nil
+}
+
+func (i *Interest) Accounts(_ context.Context, after uuid.UUID, limit int) ([]domain.InterestAccount, error) {
+	r := i.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	accounts := make([]domain.InterestAccount, 0, len(r.interest))
+	for id, account := range r.interest {
+		if slices.Compare(id[:], after[:]) > 0 {
+			accounts = append(accounts, *account)
+		}
+	}
+	slices.SortFunc(accounts, func(a, b domain.InterestAccount) int {
+		return slices.Compare(a.WalletID[:], b.WalletID[:])
+	})
+	return accounts[:min(limit, len(accounts))], nil
+}
+
+func (i *Interest) Accrue(_ context.Context, accrual *domain.InterestAccrual, next, at time.Time) (bool, error) {
+	r := i.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.interest[accrual.WalletID]
+	if !ok || !account.NextAccrualAt.Equal(accrual.Day) {
+		return false, nil
+	}
+	account.Accrued += accrual.Amount
+	account.NextAccrualAt = next
+	account.UpdatedAt = at
+	r.accruals[interestKey{accrual.WalletID, accrual.Day}] = *accrual
+	return true, nil
+}
+
+func (i *Interest) Accrued(_ context.Context, walletID uuid.UUID, from, to time.Time) (int64, error) {
+	r := i.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sum int64
+	for key, accrual := range r.accruals {
+		if key.walletID == walletID && !key.at.Before(from) && key.at.Before(to) {
+			sum += accrual.Amount
+		}
+	}
+	return sum, nil
+}
+
+func (i *Interest) Pay(_ context.Context, payout *domain.InterestPayout, next time.Time) (bool, error) {
+	r := i.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.interest[payout.WalletID]
+	if !ok || !account.NextPayoutAt.Equal(payout.Period) || account.NextAccrualAt.Before(next) {
+		return false, nil
+	}
+	account.Accrued -= payout.Amount
+	account.NextPayoutAt = next
+	account.UpdatedAt = payout.PaidAt
+	r.payouts[interestKey{payout.WalletID, payout.Period}] = *payout
+	return true, nil
+}
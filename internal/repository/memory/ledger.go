@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Ledger is the transaction chain kept next to the wallets of a
+// WalletRepository. Transactions appended in a transaction become visible to
+// others when it commits.
+type Ledger struct {
+	r *WalletRepository
+}
+
+func (r *WalletRepository) Ledger() *Ledger {
+	return &Ledger{r: r}
+}
+
+func (l *Ledger) Append(ctx context.Context, tx *domain.Transaction) error {
+	r := l.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := r.txFrom(ctx)
+	if t != nil && t.done {
+		return repository.ErrTxClosed
+	}
+	if _, ok := r.read(t, tx.WalletID); !ok {
+		return domain.ErrWalletNotFound
+	}
+	for _, existing := range l.visible(t, tx.WalletID) {
+		if existing.Seq == tx.Seq {
+			return fmt.Errorf("transaction %d of wallet %s already exists", tx.Seq, tx.WalletID)
+		}
+	}
+
+	if t != nil {
+		t.ledger = append(t.ledger, *tx)
+	} else {
+		r.ledger[tx.WalletID] = append(r.ledger[tx.WalletID], *tx)
+	}
+	return nil
+}
+
+func (l *Ledger) Last(ctx context.Context, walletID uuid.UUID) (*domain.Transaction, error) {
+	r := l.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	txs := l.visible(r.txFrom(ctx), walletID)
+	if len(txs) == 0 {
+		return nil, nil
+	}
+	last := txs[len(txs)-1]
+	return &last, nil
+}
+
+func (l *Ledger) LastBefore(ctx context.Context, walletID uuid.UUID, at time.Time) (*domain.Transaction, error) {
+	r := l.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var last *domain.Transaction
+	for _, tx := range l.visible(r.txFrom(ctx), walletID) {
+		if tx.At.Before(at) && (last == nil || !tx.At.Before(last.At)) {
+			last = &tx
+		}
+	}
+	return last, nil
+}
+
+func (l *Ledger) List(ctx context.Context, walletID uuid.UUID, afterSeq int64, limit int) ([]domain.Transaction, error) {
+	r := l.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var txs []domain.Transaction
+	for _, tx := range l.visible(r.txFrom(ctx), walletID) {
+		if tx.Seq > afterSeq && len(txs) < limit {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
+func (l *Ledger) WalletIDs(_ context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	r := l.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []uuid.UUID
+	for id := range r.wallets {
+		if bytes.Compare(id[:], after[:]) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (l *Ledger) SaveSnapshot(_ context.Context, snapshot *domain.BalanceSnapshot) error {
+	r := l.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.wallets[snapshot.WalletID]; !ok {
+		return domain.ErrWalletNotFound
+	}
+	for _, existing := range r.snapshots[snapshot.WalletID] {
+		if existing.Seq == snapshot.Seq {
+			return nil
+		}
+	}
+	r.snapshots[snapshot.WalletID] = append(r.snapshots[snapshot.WalletID], *snapshot)
+	return nil
+}
+
+func (l *Ledger) SnapshotBefore(_ context.Context, walletID uuid.UUID, at time.Time) (*domain.BalanceSnapshot, error) {
+	r := l.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var last *domain.BalanceSnapshot
+	for _, snapshot := range r.snapshots[walletID] {
+		if snapshot.At.Before(at) && (last == nil || snapshot.At.After(last.At) ||
+			snapshot.At.Equal(last.At) && snapshot.Seq > last.Seq) {
+			last = &snapshot
+		}
+	}
+	return last, nil
+}
+
+// visible returns the transactions of the wallet seen by t, or the committed
+// ones when t is nil, ordered by Seq. The caller must hold r.mu.
+func (l *Ledger) visible(t *tx, walletID uuid.UUID) []domain.Transaction {
+	txs := slices.Clone(l.r.ledger[walletID])
+	if t != nil {
+		for _, tx := range t.ledger {
+			if tx.WalletID == walletID {
+				txs = append(txs, tx)
+			}
+		}
+	}
+	slices.SortFunc(txs, func(a, b domain.Transaction) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	return txs
+}
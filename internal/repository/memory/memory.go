@@ -0,0 +1,369 @@
+// Package memory implements repository.Wallet on top of process memory.
+//
+// It follows the Postgres implementation closely enough to run the service
+// and its tests without a database: transactions see their own writes, other
+// callers only see committed state, and GetForUpdate, Update and Create take a
+// row lock that is held until the transaction ends. Repeatable read and
+// serializable transactions also lock the rows they read with Get, which
+// makes them serializable. Deadlocks are not detected; a blocked caller
+// waits until its context is done.
+package memory
+
+import (
+	"context"
+	"sync"
+	"wallet-service/config"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// TestWallets mirrors the fixtures loaded by migrations/test.
+var TestWallets = map[uuid.UUID]int64{
+	uuid.MustParse("3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901"): 100,
+	uuid.MustParse("5d2c7e80-1a34-4b74-8cc2-9f0e4f3c2a12"): 10,
+	uuid.MustParse("5d2c7e80-1a34-4b74-8cc2-9f0e4f3c2a13"): 0,
+	uuid.MustParse("5d2c7e80-1a34-4b74-8cc2-9f0e4f3c2a14"): 10000,
+}
+
+type WalletRepository struct {
+	mu      sync.Mutex
+	wallets map[uuid.UUID]int64
+	locks   map[uuid.UUID]*rowLock
+	// isolation is used by transactions that do not ask for a level.
+	isolation string
+
+	audit    []domain.AuditRecord
+	auditSeq int64
+
+	ledger map[uuid.UUID][]domain.Transaction
+	// snapshots are not transactional: they are derived from committed
+	// transactions and saved on their own.
+	snapshots map[uuid.UUID][]domain.BalanceSnapshot
+	// schedules are not transactional either.
+	schedules map[uuid.UUID]*domain.Schedule
+	// tiers are not transactional either.
+	tiers map[uuid.UUID]string
+	// interest is not transactional either.
+	interest map[uuid.UUID]*domain.InterestAccount
+	accruals map[interestKey]domain.InterestAccrual
+	payouts  map[interestKey]domain.InterestPayout
+
+	// bonuses are transactional, see Bonuses.
+	bonuses map[uuid.UUID][]domain.Bonus
+	// escrows are transactional too, see Escrows.
+	escrows           map[uuid.UUID]domain.Escrow
+	escrowTransitions map[uuid.UUID][]domain.EscrowTransition
+}
+
+type rowLock struct {
+	owner    *tx
+	released chan struct{}
+}
+
+type Option func(r *WalletRepository)
+
+// WithWallets preloads the repository with committed wallets.
+func WithWallets(wallets map[uuid.UUID]int64) Option {
+	return func(r *WalletRepository) {
+		for id, balance := range wallets {
+			r.wallets[id] = balance
+		}
+	}
+}
+
+// WithDefaultIsolation sets the level of transactions that do not ask for
+// one; without it they run at read committed.
+func WithDefaultIsolation(level string) Option {
+	return func(r *WalletRepository) {
+		r.isolation = level
+	}
+}
+
+func NewWalletRepository(opts ...Option) *WalletRepository {
+	r := &WalletRepository{
+		wallets:   make(map[uuid.UUID]int64),
+		locks:     make(map[uuid.UUID]*rowLock),
+		ledger:    make(map[uuid.UUID][]domain.Transaction),
+		snapshots: make(map[uuid.UUID][]domain.BalanceSnapshot),
+		schedules: make(map[uuid.UUID]*domain.Schedule),
+		tiers:     make(map[uuid.UUID]string),
+		interest:  make(map[uuid.UUID]*domain.InterestAccount),
+		accruals:  make(map[interestKey]domain.InterestAccrual),
+		payouts:   make(map[interestKey]domain.InterestPayout),
+		bonuses:   make(map[uuid.UUID][]domain.Bonus),
+
+		escrows:           make(map[uuid.UUID]domain.Escrow),
+		escrowTransitions: make(map[uuid.UUID][]domain.EscrowTransition),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func NewRepository(opts ...Option) *repository.Repository {
+	r := NewWalletRepository(opts...)
+	return &repository.Repository{Wallet: r, Audit: r.AuditLog(), Ledger: r.Ledger(), Schedules: r.Schedules(), Tiers: r.Tiers(), Interest: r.Interest(), Bonuses: r.Bonuses(), Escrows: r.Escrows()}
+}
+
+type txKeyType struct{}
+
+var txKey = txKeyType{}
+
+func (r *WalletRepository) WithTx(ctx context.Context, opts ...repository.TxOption) (context.Context, repository.WalletTx, error) {
+	level := repository.NewTxOptions(opts...).Isolation
+	if level == "" {
+		level = r.isolation
+	}
+
+	t := &tx{
+		repo:       r,
+		writes:     make(map[uuid.UUID]int64),
+		lockOnRead: level == config.IsolationRepeatableRead || level == config.IsolationSerializable,
+	}
+	return context.WithValue(ctx, txKey, t), t, nil
+}
+
+func (r *WalletRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Wallet, error) {
+	t := r.txFrom(ctx)
+	if t != nil && t.lockOnRead {
+		return r.GetForUpdate(ctx, id)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t != nil && t.done {
+		return nil, repository.ErrTxClosed
+	}
+
+	balance, ok := r.read(t, id)
+	if !ok {
+		return nil, domain.ErrWalletNotFound
+	}
+	return domain.NewWallet(id, balance)
+}
+
+func (r *WalletRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.Wallet, error) {
+	t := r.txFrom(ctx)
+	if t == nil {
+		// Outside a transaction the lock would be released right away.
+		return r.Get(ctx, id)
+	}
+
+	acquired, err := r.lock(ctx, t, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	balance, ok := r.read(t, id)
+	if !ok {
+		if acquired {
+			r.unlock(t, id)
+		}
+		return nil, domain.ErrWalletNotFound
+	}
+	return domain.NewWallet(id, balance)
+}
+
+func (r *WalletRepository) Update(ctx context.Context, wallet *domain.Wallet) (*domain.Wallet, error) {
+	return r.write(ctx, wallet, func(exists bool) error {
+		if !exists {
+			return domain.ErrWalletNotFound
+		}
+		return nil
+	})
+}
+
+func (r *WalletRepository) Create(ctx context.Context, wallet *domain.Wallet) (*domain.Wallet, error) {
+	return r.write(ctx, wallet, func(exists bool) error {
+		if exists {
+			return domain.ErrWalletExists
+		}
+		return nil
+	})
+}
+
+// write stores wallet under a row lock. Outside a transaction it runs in its
+// own transaction that is committed straight away.
+func (r *WalletRepository) write(ctx context.Context, wallet *domain.Wallet, check func(exists bool) error) (_ *domain.Wallet, err error) {
+	t := r.txFrom(ctx)
+	if t == nil {
+		var autocommit repository.WalletTx
+		ctx, autocommit, err = r.WithTx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		t = r.txFrom(ctx)
+		defer func() {
+			if err != nil {
+				_ = autocommit.Rollback(ctx)
+				return
+			}
+			err = autocommit.Commit(ctx)
+		}()
+	}
+
+	id := wallet.ID()
+	acquired, err := r.lock(ctx, t, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.read(t, id)
+	if err := check(exists); err != nil {
+		if acquired {
+			r.unlock(t, id)
+		}
+		return nil, err
+	}
+
+	t.writes[id] = wallet.Balance()
+	return domain.NewWallet(id, wallet.Balance())
+}
+
+func (r *WalletRepository) txFrom(ctx context.Context) *tx {
+	if t, ok := ctx.Value(txKey).(*tx); ok && t.repo == r {
+		return t
+	}
+	return nil
+}
+
+// read returns the balance visible to t, or the committed one when t is nil.
+// The caller must hold r.mu.
+func (r *WalletRepository) read(t *tx, id uuid.UUID) (int64, bool) {
+	if t != nil {
+		if balance, ok := t.writes[id]; ok {
+			return balance, true
+		}
+	}
+	balance, ok := r.wallets[id]
+	return balance, ok
+}
+
+// lock blocks until t holds the row lock on id or ctx is done. It reports
+// whether the lock was newly taken by this call.
+func (r *WalletRepository) lock(ctx context.Context, t *tx, id uuid.UUID) (bool, error) {
+	for {
+		r.mu.Lock()
+		if t.done {
+			r.mu.Unlock()
+			return false, repository.ErrTxClosed
+		}
+
+		held, ok := r.locks[id]
+		if !ok {
+			r.locks[id] = &rowLock{owner: t, released: make(chan struct{})}
+			t.locked = append(t.locked, id)
+			r.mu.Unlock()
+			return true, nil
+		}
+		if held.owner == t {
+			r.mu.Unlock()
+			return false, nil
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-held.released:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// unlock releases a single row lock held by t. The caller must hold r.mu.
+func (r *WalletRepository) unlock(t *tx, id uuid.UUID) {
+	held, ok := r.locks[id]
+	if !ok || held.owner != t {
+		return
+	}
+	delete(r.locks, id)
+	close(held.released)
+
+	for i, locked := range t.locked {
+		if locked == id {
+			t.locked = append(t.locked[:i], t.locked[i+1:]...)
+			break
+		}
+	}
+}
+
+type tx struct {
+	repo   *WalletRepository
+	writes map[uuid.UUID]int64
+	locked []uuid.UUID
+	audit  []domain.AuditRecord
+	ledger []domain.Transaction
+	// bonuses holds the buckets saved by the transaction, by wallet.
+	bonuses map[uuid.UUID][]domain.Bonus
+	// escrows and escrowTransitions hold the escrows written by the
+	// transaction and the transitions it recorded.
+	escrows           map[uuid.UUID]domain.Escrow
+	escrowTransitions []domain.EscrowTransition
+	done              bool
+	// lockOnRead makes Get take the row lock like GetForUpdate.
+	lockOnRead bool
+}
+
+func (t *tx) Commit(_ context.Context) error {
+	return t.finish(true)
+}
+
+func (t *tx) Rollback(_ context.Context) error {
+	return t.finish(false)
+}
+
+func (t *tx) finish(commit bool) error {
+	r := t.repo
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t.done {
+		return repository.ErrTxClosed
+	}
+	t.done = true
+
+	if commit {
+		for id, balance := range t.writes {
+			r.wallets[id] = balance
+		}
+		r.audit = append(r.audit, t.audit...)
+		for _, tx := range t.ledger {
+			r.ledger[tx.WalletID] = append(r.ledger[tx.WalletID], tx)
+		}
+		for id, bonuses := range t.bonuses {
+			r.bonuses[id] = bonuses
+		}
+		for id, escrow := range t.escrows {
+			r.escrows[id] = escrow
+		}
+		for _, tr := range t.escrowTransitions {
+			r.escrowTransitions[tr.EscrowID] = append(r.escrowTransitions[tr.EscrowID], tr)
+		}
+	}
+	t.writes = nil
+	t.bonuses = nil
+	t.escrows = nil
+	t.escrowTransitions = nil
+	t.audit = nil
+	t.ledger = nil
+
+	for _, id := range t.locked {
+		if held, ok := r.locks[id]; ok && held.owner == t {
+			delete(r.locks, id)
+			close(held.released)
+		}
+	}
+	t.locked = nil
+
+	return nil
+}
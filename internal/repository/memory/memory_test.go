@@ -0,0 +1,78 @@
+package memory_test
+
+import (
+	"testing"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository/memory"
+	"wallet-service/internal/repository/repositorytest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Conformance(t *testing.T) {
+	repositorytest.Run(t, memory.NewWalletRepository())
+}
+
+func TestMemory_AuditConformance(t *testing.T) {
+	repo := memory.NewRepository()
+	repositorytest.RunAudit(t, repo.Wallet, repo.Audit)
+}
+
+func TestMemory_LedgerConformance(t *testing.T) {
+	repo := memory.NewRepository()
+	repositorytest.RunLedger(t, repo.Wallet, repo.Ledger)
+}
+
+func TestMemory_SchedulesConformance(t *testing.T) {
+	repo := memory.NewRepository()
+	repositorytest.RunSchedules(t, repo.Wallet, repo.Schedules)
+}
+
+func TestMemory_TiersConformance(t *testing.T) {
+	repo := memory.NewRepository()
+	repositorytest.RunTiers(t, repo.Wallet, repo.Tiers)
+}
+
+func TestMemory_InterestConformance(t *testing.T) {
+	repo := memory.NewRepository()
+	repositorytest.RunInterest(t, repo.Wallet, repo.Interest)
+}
+
+func TestMemory_BonusesConformance(t *testing.T) {
+	repo := memory.NewRepository()
+	repositorytest.RunBonuses(t, repo.Wallet, repo.Bonuses)
+}
+
+func TestMemory_EscrowsConformance(t *testing.T) {
+	repo := memory.NewRepository()
+	repositorytest.RunEscrows(t, repo.Wallet, repo.Escrows)
+}
+
+func TestWithWallets_Preloaded_Readable(t *testing.T) {
+	repo := memory.NewWalletRepository(memory.WithWallets(memory.TestWallets))
+
+	id := uuid.MustParse("3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901")
+	wallet, err := repo.Get(t.Context(), id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), wallet.Balance())
+}
+
+func TestGetForUpdate_MissingWallet_DoesNotKeepLock(t *testing.T) {
+	repo := memory.NewWalletRepository()
+	id := uuid.New()
+
+	ctx, tx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(t.Context()) }()
+
+	_, err = repo.GetForUpdate(ctx, id)
+	require.ErrorIs(t, err, domain.ErrWalletNotFound)
+
+	// Создание вне транзакции не должно ждать блокировку несуществующей строки
+	wallet, err := domain.NewWallet(id, 1)
+	require.NoError(t, err)
+	_, err = repo.Create(t.Context(), wallet)
+	require.NoError(t, err)
+}
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+	"wallet-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Schedules keeps the scheduled operations of the wallets of a
+// WalletRepository. Changes take effect at once, outside any transaction.
+type Schedules struct {
+	r *WalletRepository
+}
+
+func (r *WalletRepository) Schedules() *Schedules {
+	return &Schedules{r: r}
+}
+
+func (s *Schedules) Create(_ context.Context, schedule *domain.Schedule) (*domain.Schedule, error) {
+	r := s.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.wallets[schedule.WalletID]; !ok {
+		return nil, domain.ErrWalletNotFound
+	}
+
+	stored := *schedule
+	r.schedules[stored.ID] = &stored
+	created := stored
+	return &created, nil
+}
+
+func (s *Schedules) Get(_ context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	r := s.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedule, ok := r.schedules[id]
+	if !ok {
+		return nil, domain.ErrScheduleNotFound
+	}
+	found := *schedule
+	return &found, nil
+}
+
+func (s *Schedules) List(_ context.Context, walletID uuid.UUID, status string, limit int) ([]domain.Schedule, error) {
+	r := s.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var schedules []domain.Schedule
+	for _, schedule := range r.schedules {
+		if schedule.WalletID == walletID && (status == "" || schedule.Status == status) {
+			schedules = append(schedules, *schedule)
+		}
+	}
+	slices.SortFunc(schedules, func(a, b domain.Schedule) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return slices.Compare(a.ID[:], b.ID[:])
+	})
+	if len(schedules) > limit {
+		schedules = schedules[:limit]
+	}
+	return schedules, nil
+}
+
+func (s *Schedules) Cancel(_ context.Context, id uuid.UUID, at time.Time) (*domain.Schedule, error) {
+	r := s.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedule, ok := r.schedules[id]
+	if !ok {
+		return nil, domain.ErrScheduleNotFound
+	}
+	if schedule.Status != domain.SchedulePending && schedule.Status != domain.ScheduleRetrying {
+		return nil, domain.ErrScheduleNotCancellable
+	}
+
+	schedule.Status = domain.ScheduleCancelled
+	schedule.UpdatedAt = at
+	cancelled := *schedule
+	return &cancelled, nil
+}
+
+func (s *Schedules) Claim(_ context.Context, lease time.Duration, limit int) ([]domain.ScheduleClaim, error) {
+	r := s.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	var due []*domain.Schedule
+	for _, schedule := range r.schedules {
+		switch schedule.Status {
+		case domain.SchedulePending, domain.ScheduleRetrying:
+			if !schedule.NextRunAt.After(now) {
+				due = append(due, schedule)
+			}
+		case domain.ScheduleRunning:
+			if schedule.LeaseUntil.Before(now) {
+				due = append(due, schedule)
+			}
+		}
+	}
+	slices.SortFunc(due, func(a, b *domain.Schedule) int {
+		return cmp.Compare(a.NextRunAt.UnixNano(), b.NextRunAt.UnixNano())
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claims := make([]domain.ScheduleClaim, 0, len(due))
+	for _, schedule := range due {
+		var claim domain.ScheduleClaim
+		if schedule.Status == domain.ScheduleRunning {
+			interrupted := schedule.ClaimedAt
+			claim.Interrupted = &interrupted
+		}
+
+		claimedAt := now
+		// A claim must differ from the one it replaces to identify it.
+		if !claimedAt.After(schedule.ClaimedAt) {
+			claimedAt = schedule.ClaimedAt.Add(time.Microsecond)
+		}
+		schedule.Status = domain.ScheduleRunning
+		schedule.Attempts++
+		schedule.ClaimedAt = claimedAt
+		schedule.LeaseUntil = claimedAt.Add(lease)
+		schedule.UpdatedAt = claimedAt
+
+		claim.Schedule = *schedule
+		claims = append(claims, claim)
+	}
+	return claims, nil
+}
+
+func (s *Schedules) Finish(_ context.Context, schedule *domain.Schedule) error {
+	r := s.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.schedules[schedule.ID]
+	if !ok || stored.Status != domain.ScheduleRunning || !stored.ClaimedAt.Equal(schedule.ClaimedAt) {
+		return domain.ErrScheduleLeaseLost
+	}
+
+	stored.Status = schedule.Status
+	stored.NextRunAt = schedule.NextRunAt
+	stored.Attempts = schedule.Attempts
+	stored.Runs = schedule.Runs
+	stored.Failures = schedule.Failures
+	stored.LastRunAt = schedule.LastRunAt
+	stored.LastError = schedule.LastError
+	stored.UpdatedAt = schedule.UpdatedAt
+	stored.ClaimedAt = time.Time{}
+	stored.LeaseUntil = time.Time{}
+	return nil
+}
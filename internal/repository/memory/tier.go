@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"wallet-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Tiers keeps the fee tiers of the wallets of a WalletRepository. Changes
+// take effect at once, outside any transaction.
+type Tiers struct {
+	r *WalletRepository
+}
+
+func (r *WalletRepository) Tiers() *Tiers {
+	return &Tiers{r: r}
+}
+
+func (t *Tiers) Tier(_ context.Context, walletID uuid.UUID) (string, error) {
+	r := t.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tiers[walletID], nil
+}
+
+func (t *Tiers) SetTier(_ context.Context, walletID uuid.UUID, tier string) error {
+	r := t.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.wallets[walletID]; !ok {
+		return domain.ErrWalletNotFound
+	}
+	r.tiers[walletID] = tier
+	return nil
+}
@@ -1,15 +1,81 @@
 package repository
 
 import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+	"wallet-service/config"
 	"wallet-service/internal/db"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewPostgresRepository(pool *pgxpool.Pool) (*Repository, error) {
+func NewPostgresRepository(pool *pgxpool.Pool, log *slog.Logger, opts ...Option) (*Repository, error) {
 	queries := db.New(pool)
 
 	return &Repository{
-		Wallet: NewWalletRepository(pool, queries),
+		Wallet:    NewWalletRepository(pool, queries, log, opts...),
+		Audit:     NewAuditRepository(pool, queries),
+		Ledger:    NewLedgerRepository(pool, queries),
+		Schedules: NewScheduleRepository(pool, queries),
+		Tiers:     NewTierRepository(pool, queries),
+		Interest:  NewInterestRepository(pool, queries),
+		Bonuses:   NewBonusRepository(pool, queries),
+		Escrows:   NewEscrowRepository(pool, queries),
 	}, nil
 }
+
+// NewPool opens a connection pool sized and timed according to cfg. Every
+// connection runs with the configured statement and lock timeouts.
+func NewPool(ctx context.Context, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := NewPoolConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return pgxpool.NewWithConfig(ctx, poolConfig)
+}
+
+// NewReplicaPool opens a pool to cfg.ReplicaDSN with the same sizing and
+// timeouts as the primary.
+func NewReplicaPool(ctx context.Context, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := newPoolConfig(cfg.ReplicaDSN, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return pgxpool.NewWithConfig(ctx, poolConfig)
+}
+
+func NewPoolConfig(cfg config.DatabaseConfig) (*pgxpool.Config, error) {
+	return newPoolConfig(cfg.DSN(), cfg)
+}
+
+func newPoolConfig(dsn string, cfg config.DatabaseConfig) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Pool.MaxConns > 0 {
+		poolConfig.MaxConns = int32(cfg.Pool.MaxConns)
+	}
+	poolConfig.MinConns = int32(cfg.Pool.MinConns)
+	poolConfig.MaxConnLifetime = cfg.Pool.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = cfg.Pool.MaxConnIdleTime
+	poolConfig.HealthCheckPeriod = cfg.Pool.HealthCheckPeriod
+
+	if cfg.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = milliseconds(cfg.StatementTimeout)
+	}
+	if cfg.LockTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["lock_timeout"] = milliseconds(cfg.LockTimeout)
+	}
+
+	poolConfig.ConnConfig.Tracer = NewQueryTracer()
+
+	return poolConfig, nil
+}
+
+func milliseconds(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
@@ -2,39 +2,64 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
+	"wallet-service/config"
 	"wallet-service/internal/db"
+	"wallet-service/internal/metrics"
+	"wallet-service/internal/tracing"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-//go:generate mockgen -source=postgres_tx.go -destination=mocks/tx.mock.go
-
-type WalletTx interface {
-	pgx.Tx
-}
-
-type TxRepository interface {
-	WithTx(ctx context.Context) (context.Context, pgx.Tx, error)
+var pgIsolationLevels = map[string]pgx.TxIsoLevel{
+	config.IsolationReadCommitted:  pgx.ReadCommitted,
+	config.IsolationRepeatableRead: pgx.RepeatableRead,
+	config.IsolationSerializable:   pgx.Serializable,
 }
 
 type TxRepositoryImpl struct {
 	db *pgxpool.Pool
 	q  *db.Queries
+	// isolation is used by transactions that do not ask for a level;
+	// empty means the server default.
+	isolation string
 }
 type txKeyType struct{}
 
 var txKey = txKeyType{}
 
-func (r *TxRepositoryImpl) WithTx(ctx context.Context) (context.Context, pgx.Tx, error) {
-	tx, err := r.db.Begin(ctx)
+// WithTx begins a transaction. Under repeatable_read and serializable a
+// conflicting concurrent change fails the transaction with ErrTxConflict
+// instead of being silently overwritten.
+func (r *TxRepositoryImpl) WithTx(ctx context.Context, opts ...TxOption) (context.Context, WalletTx, error) {
+	o := NewTxOptions(opts...)
+	if o.Isolation == "" {
+		o.Isolation = r.isolation
+	}
+	var txOptions pgx.TxOptions
+	if o.Isolation != "" {
+		level, ok := pgIsolationLevels[o.Isolation]
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported isolation level %q", o.Isolation)
+		}
+		txOptions.IsoLevel = level
+	}
+
+	spanCtx, span := tracer.Start(ctx, "WalletRepository.WithTx")
+	span.SetAttributes(attribute.String("db.isolation", o.Isolation))
+	tx, err := r.db.BeginTx(spanCtx, txOptions)
+	tracing.End(span, err)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	txQueries := r.q.WithTx(tx)
 
-	return context.WithValue(ctx, txKey, txQueries), tx, nil
+	return context.WithValue(ctx, txKey, txQueries), &observedTx{WalletTx: pgTx{tx}, start: time.Now()}, nil
 }
 
 func (r *TxRepositoryImpl) getQueries(ctx context.Context) *db.Queries {
@@ -43,3 +68,49 @@ func (r *TxRepositoryImpl) getQueries(ctx context.Context) *db.Queries {
 	}
 	return r.q
 }
+
+// observedTx records how long a transaction stayed open.
+type observedTx struct {
+	WalletTx
+	start time.Time
+	once  sync.Once
+}
+
+func (t *observedTx) Commit(ctx context.Context) error {
+	err := t.WalletTx.Commit(ctx)
+	if err == nil {
+		t.observe("commit")
+	}
+	return err
+}
+
+func (t *observedTx) Rollback(ctx context.Context) error {
+	err := t.WalletTx.Rollback(ctx)
+	if err == nil {
+		t.observe("rollback")
+	}
+	return err
+}
+
+func (t *observedTx) observe(outcome string) {
+	t.once.Do(func() {
+		metrics.TxDuration.WithLabelValues(outcome).Observe(time.Since(t.start).Seconds())
+	})
+}
+
+// pgTx reports serialization failures and deadlocks detected on commit as
+// ErrTxConflict.
+type pgTx struct {
+	tx pgx.Tx
+}
+
+func (t pgTx) Commit(ctx context.Context) error {
+	if err := t.tx.Commit(ctx); err != nil {
+		return mapPgError(err)
+	}
+	return nil
+}
+
+func (t pgTx) Rollback(ctx context.Context) error {
+	return t.tx.Rollback(ctx)
+}
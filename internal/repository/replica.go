@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+	"wallet-service/internal/db"
+	"wallet-service/internal/dbsource"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/metrics"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaLagQuery reports zero once everything received has been replayed,
+// so an idle primary does not look like growing lag.
+const replicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END::float8`
+
+// Replica is a read-only pool guarded by its replication lag. Until the
+// first successful measurement the replica is treated as stale.
+type Replica struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+	maxLag  time.Duration
+	prefer  func() bool
+	log     *slog.Logger
+
+	lag   atomic.Int64
+	fresh atomic.Bool
+}
+
+type ReplicaOption func(r *Replica)
+
+// PreferWhen sends eventual reads to the replica regardless of its lag
+// while prefer reports true, e.g. when the primary is under maintenance.
+func PreferWhen(prefer func() bool) ReplicaOption {
+	return func(r *Replica) {
+		r.prefer = prefer
+	}
+}
+
+func NewReplica(pool *pgxpool.Pool, maxLag time.Duration, log *slog.Logger, opts ...ReplicaOption) *Replica {
+	r := &Replica{
+		pool:    pool,
+		queries: db.New(pool),
+		maxLag:  maxLag,
+		log:     log,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Monitor measures the lag every interval until ctx is done.
+func (r *Replica) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.measure(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Replica) measure(ctx context.Context) {
+	var seconds float64
+	err := r.pool.QueryRow(ctx, replicaLagQuery).Scan(&seconds)
+	if err != nil {
+		if r.fresh.Swap(false) {
+			r.log.WarnContext(ctx, "replica lag check failed, reading from primary", logger.Err(err))
+		}
+		return
+	}
+
+	r.observe(time.Duration(seconds * float64(time.Second)))
+}
+
+func (r *Replica) observe(lag time.Duration) {
+	r.lag.Store(int64(lag))
+	metrics.ReplicaLag.Set(lag.Seconds())
+
+	fresh := lag <= r.maxLag
+	if r.fresh.Swap(fresh) != fresh {
+		r.log.Info("replica freshness changed",
+			slog.Bool("fresh", fresh),
+			slog.Duration("lag", lag),
+			slog.Duration("max_lag", r.maxLag),
+		)
+	}
+}
+
+func (r *Replica) Lag() time.Duration {
+	return time.Duration(r.lag.Load())
+}
+
+// Fresh reports whether the last measured lag is within the budget.
+func (r *Replica) Fresh() bool {
+	return r.fresh.Load()
+}
+
+func (r *Replica) serves(ctx context.Context) bool {
+	if dbsource.ConsistencyFrom(ctx) == dbsource.Strong {
+		return false
+	}
+	if r.prefer != nil && r.prefer() {
+		return true
+	}
+	return r.Fresh()
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+	"wallet-service/internal/db"
+	"wallet-service/internal/dbsource"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReplica(opts ...ReplicaOption) *Replica {
+	return NewReplica(nil, time.Second, testLogger, opts...)
+}
+
+func TestReadQueries_FreshReplica_UsesReplica(t *testing.T) {
+	replica := newTestReplica()
+	replica.observe(100 * time.Millisecond)
+	r := NewWalletRepository(nil, db.New(nil), testLogger, WithReplica(replica))
+	ctx := dbsource.Track(t.Context())
+
+	q, source := r.readQueries(ctx)
+
+	assert.Equal(t, dbsource.Replica, source)
+	assert.Same(t, replica.queries, q)
+	assert.Equal(t, dbsource.Replica, dbsource.Served(ctx))
+}
+
+func TestReadQueries_LaggingReplica_UsesPrimary(t *testing.T) {
+	replica := newTestReplica()
+	replica.observe(5 * time.Second)
+	r := NewWalletRepository(nil, db.New(nil), testLogger, WithReplica(replica))
+
+	q, source := r.readQueries(t.Context())
+
+	assert.Equal(t, dbsource.Primary, source)
+	assert.Same(t, r.q, q)
+}
+
+func TestReadQueries_NotMeasuredYet_UsesPrimary(t *testing.T) {
+	r := NewWalletRepository(nil, db.New(nil), testLogger, WithReplica(newTestReplica()))
+
+	_, source := r.readQueries(t.Context())
+
+	assert.Equal(t, dbsource.Primary, source)
+}
+
+func TestReadQueries_StrongConsistency_UsesPrimary(t *testing.T) {
+	replica := newTestReplica()
+	replica.observe(0)
+	r := NewWalletRepository(nil, db.New(nil), testLogger, WithReplica(replica))
+	ctx := dbsource.WithConsistency(t.Context(), dbsource.Strong)
+
+	_, source := r.readQueries(ctx)
+
+	assert.Equal(t, dbsource.Primary, source)
+}
+
+func TestReadQueries_PreferredLaggingReplica_UsesReplica(t *testing.T) {
+	replica := newTestReplica(PreferWhen(func() bool { return true }))
+	replica.observe(time.Minute)
+	r := NewWalletRepository(nil, db.New(nil), testLogger, WithReplica(replica))
+
+	_, source := r.readQueries(t.Context())
+
+	assert.Equal(t, dbsource.Replica, source)
+}
+
+func TestReadQueries_InsideTransaction_UsesTransaction(t *testing.T) {
+	replica := newTestReplica()
+	replica.observe(0)
+	r := NewWalletRepository(nil, db.New(nil), testLogger, WithReplica(replica))
+	txQueries := db.New(nil)
+	ctx := context.WithValue(t.Context(), txKey, txQueries)
+
+	q, source := r.readQueries(ctx)
+
+	assert.Equal(t, dbsource.Primary, source)
+	assert.Same(t, txQueries, q)
+}
+
+func TestReadQueries_NoReplica_UsesPrimary(t *testing.T) {
+	r := NewWalletRepository(nil, db.New(nil), testLogger)
+
+	_, source := r.readQueries(t.Context())
+
+	assert.Equal(t, dbsource.Primary, source)
+}
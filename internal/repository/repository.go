@@ -2,20 +2,82 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"wallet-service/internal/domain"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 //go:generate mockgen -source=repository.go -destination=mocks/mock.go
 
+// ErrTxClosed is returned by Commit and Rollback once the transaction has
+// already been committed or rolled back.
+var ErrTxClosed = pgx.ErrTxClosed
+
+// ErrTxConflict means the transaction lost a race with a concurrent one and
+// was aborted; running the whole unit of work again may succeed.
+var ErrTxConflict = errors.New("transaction conflicts with a concurrent transaction")
+
+// WalletTx is the transaction handle returned by WithTx. Operations run with
+// the context returned alongside it take part in the transaction.
+type WalletTx interface {
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// TxOptions are the settings of a single transaction. A zero value leaves
+// every setting at the repository default.
+type TxOptions struct {
+	// Isolation is one of the config.Isolation* levels.
+	Isolation string
+}
+
+type TxOption func(o *TxOptions)
+
+// WithIsolation runs the transaction at level instead of the repository
+// default. A repository may run it at a stricter level than asked.
+func WithIsolation(level string) TxOption {
+	return func(o *TxOptions) {
+		o.Isolation = level
+	}
+}
+
+// NewTxOptions applies opts on top of the zero TxOptions.
+func NewTxOptions(opts ...TxOption) TxOptions {
+	var o TxOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+type TxRepository interface {
+	WithTx(ctx context.Context, opts ...TxOption) (context.Context, WalletTx, error)
+}
+
 type Wallet interface {
 	TxRepository
 	Get(ctx context.Context, id uuid.UUID) (*domain.Wallet, error)
 	GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.Wallet, error)
 	Update(ctx context.Context, wallet *domain.Wallet) (*domain.Wallet, error)
+	Create(ctx context.Context, wallet *domain.Wallet) (*domain.Wallet, error)
 }
 
 type Repository struct {
 	Wallet
+	Audit  Audit
+	Ledger Ledger
+	// Schedules is nil when the storage cannot run scheduled operations.
+	Schedules Schedules
+	// Tiers is nil when the storage keeps no tiers; every wallet is then in
+	// the default tier.
+	Tiers Tiers
+	// Interest is nil when the storage cannot keep savings wallets.
+	Interest Interest
+	// Bonuses is nil when the storage keeps no bonus buckets; every wallet
+	// then holds cash only.
+	Bonuses Bonuses
+	// Escrows is nil when the storage cannot hold funds in escrow.
+	Escrows Escrows
 }
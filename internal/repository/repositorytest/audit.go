@@ -0,0 +1,187 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type auditCase struct {
+	name string
+	fn   func(t *testing.T, wallets repository.Wallet, log repository.Audit)
+}
+
+// RunAudit checks log against the shared audit contract. wallets must share
+// transactions with log. Every case filters by its own actor, so the log may
+// already contain records.
+func RunAudit(t *testing.T, wallets repository.Wallet, log repository.Audit) {
+	cases := []auditCase{
+		{"Append_Outside_Tx_Listed", testAuditAppendListed},
+		{"Append_TxCommitted_Listed", testAuditCommitted},
+		{"Append_TxRolledBack_Discarded", testAuditRolledBack},
+		{"List_Filters_MatchingOnly", testAuditFilters},
+		{"List_BeforeID_NextPage", testAuditPaging},
+		{"List_TimeRange_Bounds", testAuditTimeRange},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, wallets, log)
+		})
+	}
+}
+
+func auditRecord(actor, action string, walletID *uuid.UUID) *domain.AuditRecord {
+	before, after := int64(10), int64(15)
+	return &domain.AuditRecord{
+		Actor:         actor,
+		SourceIP:      "192.0.2.1",
+		RequestID:     "req-" + actor,
+		Action:        action,
+		Outcome:       "success",
+		WalletID:      walletID,
+		BalanceBefore: &before,
+		BalanceAfter:  &after,
+	}
+}
+
+func listByActor(t *testing.T, log repository.Audit, actor string) []domain.AuditRecord {
+	t.Helper()
+
+	records, err := log.List(t.Context(), repository.AuditFilter{Actor: actor, Limit: 100})
+	require.NoError(t, err)
+	return records
+}
+
+func testAuditAppendListed(t *testing.T, _ repository.Wallet, log repository.Audit) {
+	actor := uuid.NewString()
+	walletID := uuid.New()
+
+	record := auditRecord(actor, domain.AuditDeposit, &walletID)
+	require.NoError(t, log.Append(t.Context(), record))
+	assert.NotZero(t, record.ID)
+	assert.False(t, record.At.IsZero())
+
+	records := listByActor(t, log, actor)
+	require.Len(t, records, 1)
+	got := records[0]
+	assert.Equal(t, record.ID, got.ID)
+	assert.WithinDuration(t, record.At, got.At, time.Millisecond)
+	assert.Equal(t, "192.0.2.1", got.SourceIP)
+	assert.Equal(t, "req-"+actor, got.RequestID)
+	assert.Equal(t, domain.AuditDeposit, got.Action)
+	assert.Equal(t, "success", got.Outcome)
+	require.NotNil(t, got.WalletID)
+	assert.Equal(t, walletID, *got.WalletID)
+	require.NotNil(t, got.BalanceBefore)
+	require.NotNil(t, got.BalanceAfter)
+	assert.Equal(t, int64(10), *got.BalanceBefore)
+	assert.Equal(t, int64(15), *got.BalanceAfter)
+
+	// Действие без кошелька хранится без кошелька и балансов
+	admin := &domain.AuditRecord{Actor: actor, Action: domain.AuditMaintenanceEnable, Outcome: "success"}
+	require.NoError(t, log.Append(t.Context(), admin))
+
+	records = listByActor(t, log, actor)
+	require.Len(t, records, 2)
+	assert.Equal(t, domain.AuditMaintenanceEnable, records[0].Action)
+	assert.Nil(t, records[0].WalletID)
+	assert.Nil(t, records[0].BalanceBefore)
+	assert.Nil(t, records[0].BalanceAfter)
+}
+
+func testAuditCommitted(t *testing.T, wallets repository.Wallet, log repository.Audit) {
+	actor := uuid.NewString()
+
+	ctx, tx, err := wallets.WithTx(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, log.Append(ctx, auditRecord(actor, domain.AuditWithdraw, nil)))
+
+	assert.Empty(t, listByActor(t, log, actor))
+
+	require.NoError(t, tx.Commit(ctx))
+	assert.Len(t, listByActor(t, log, actor), 1)
+}
+
+func testAuditRolledBack(t *testing.T, wallets repository.Wallet, log repository.Audit) {
+	actor := uuid.NewString()
+
+	ctx, tx, err := wallets.WithTx(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, log.Append(ctx, auditRecord(actor, domain.AuditWithdraw, nil)))
+	require.NoError(t, tx.Rollback(ctx))
+
+	assert.Empty(t, listByActor(t, log, actor))
+}
+
+func testAuditFilters(t *testing.T, _ repository.Wallet, log repository.Audit) {
+	actor := uuid.NewString()
+	first, second := uuid.New(), uuid.New()
+
+	require.NoError(t, log.Append(t.Context(), auditRecord(actor, domain.AuditDeposit, &first)))
+	require.NoError(t, log.Append(t.Context(), auditRecord(actor, domain.AuditWithdraw, &first)))
+	rejected := auditRecord(actor, domain.AuditWithdraw, &second)
+	rejected.Outcome = "insufficient_balance"
+	require.NoError(t, log.Append(t.Context(), rejected))
+
+	list := func(filter repository.AuditFilter) []domain.AuditRecord {
+		filter.Actor, filter.Limit = actor, 100
+		records, err := log.List(t.Context(), filter)
+		require.NoError(t, err)
+		return records
+	}
+
+	all := list(repository.AuditFilter{})
+	require.Len(t, all, 3)
+	// Новые записи идут первыми
+	assert.Greater(t, all[0].ID, all[1].ID)
+	assert.Greater(t, all[1].ID, all[2].ID)
+
+	assert.Len(t, list(repository.AuditFilter{WalletID: &first}), 2)
+	assert.Len(t, list(repository.AuditFilter{Action: domain.AuditWithdraw}), 2)
+	assert.Len(t, list(repository.AuditFilter{Action: domain.AuditWithdraw, WalletID: &first}), 1)
+
+	failed := list(repository.AuditFilter{Outcome: "insufficient_balance"})
+	require.Len(t, failed, 1)
+	assert.Equal(t, rejected.ID, failed[0].ID)
+
+	assert.Empty(t, list(repository.AuditFilter{Action: domain.AuditMaintenanceEnable}))
+}
+
+func testAuditPaging(t *testing.T, _ repository.Wallet, log repository.Audit) {
+	actor := uuid.NewString()
+	for range 5 {
+		require.NoError(t, log.Append(t.Context(), auditRecord(actor, domain.AuditDeposit, nil)))
+	}
+
+	page, err := log.List(t.Context(), repository.AuditFilter{Actor: actor, Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, page, 3)
+
+	rest, err := log.List(t.Context(), repository.AuditFilter{Actor: actor, Limit: 3, BeforeID: page[2].ID})
+	require.NoError(t, err)
+	require.Len(t, rest, 2)
+	assert.Less(t, rest[0].ID, page[2].ID)
+}
+
+func testAuditTimeRange(t *testing.T, _ repository.Wallet, log repository.Audit) {
+	actor := uuid.NewString()
+	record := auditRecord(actor, domain.AuditDeposit, nil)
+	require.NoError(t, log.Append(t.Context(), record))
+
+	list := func(from, to time.Time) []domain.AuditRecord {
+		records, err := log.List(t.Context(), repository.AuditFilter{Actor: actor, From: from, To: to, Limit: 10})
+		require.NoError(t, err)
+		return records
+	}
+
+	hour := time.Hour
+	assert.Len(t, list(record.At.Add(-hour), record.At.Add(hour)), 1)
+	assert.Empty(t, list(record.At.Add(hour), time.Time{}))
+	assert.Empty(t, list(time.Time{}, record.At.Add(-hour)))
+}
@@ -0,0 +1,90 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunBonuses checks bonuses against the shared bonus contract.
+func RunBonuses(t *testing.T, wallets repository.Wallet, bonuses repository.Bonuses) {
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	bonus := func(amount int64, expiresAt time.Time) domain.Bonus {
+		return domain.Bonus{ID: uuid.New(), Amount: amount, ExpiresAt: expiresAt, GrantedAt: at.Add(-time.Hour)}
+	}
+
+	t.Run("SaveBonuses_Replaced", func(t *testing.T) {
+		id := createWallet(t, wallets, 100)
+		kept, dropped := bonus(30, at.Add(time.Hour)), bonus(20, at.Add(2*time.Hour))
+		require.NoError(t, bonuses.SaveBonuses(t.Context(), id, []domain.Bonus{dropped, kept}))
+
+		got, err := bonuses.Bonuses(t.Context(), id)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, kept.ID, got[0].ID)
+
+		kept.Amount = 5
+		require.NoError(t, bonuses.SaveBonuses(t.Context(), id, []domain.Bonus{kept}))
+		got, err = bonuses.Bonuses(t.Context(), id)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, int64(5), got[0].Amount)
+		assert.True(t, got[0].ExpiresAt.Equal(kept.ExpiresAt))
+	})
+
+	t.Run("SaveBonuses_RolledBack", func(t *testing.T) {
+		id := createWallet(t, wallets, 100)
+
+		ctx, tx, err := wallets.WithTx(t.Context())
+		require.NoError(t, err)
+		require.NoError(t, bonuses.SaveBonuses(ctx, id, []domain.Bonus{bonus(10, at)}))
+		inside, err := bonuses.Bonuses(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, inside, 1)
+		require.NoError(t, tx.Rollback(ctx))
+
+		got, err := bonuses.Bonuses(t.Context(), id)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("SaveBonuses_UnknownWallet_NotFound", func(t *testing.T) {
+		err := bonuses.SaveBonuses(t.Context(), uuid.New(), []domain.Bonus{bonus(10, at)})
+		assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+	})
+
+	t.Run("Expired_OnlyDue", func(t *testing.T) {
+		due := createWallet(t, wallets, 100)
+		require.NoError(t, bonuses.SaveBonuses(t.Context(), due, []domain.Bonus{bonus(10, at.Add(-time.Minute)), bonus(10, at)}))
+		later := createWallet(t, wallets, 100)
+		require.NoError(t, bonuses.SaveBonuses(t.Context(), later, []domain.Bonus{bonus(10, at.Add(time.Minute))}))
+
+		var found []uuid.UUID
+		after := uuid.Nil
+		for {
+			ids, err := bonuses.Expired(t.Context(), at, after, 1)
+			require.NoError(t, err)
+			if len(ids) == 0 {
+				break
+			}
+			found = append(found, ids...)
+			after = ids[len(ids)-1]
+		}
+		assert.Contains(t, found, due)
+		assert.NotContains(t, found, later)
+		assert.Equal(t, len(found), len(uniqueIDs(found)))
+	})
+}
+
+func uniqueIDs(ids []uuid.UUID) map[uuid.UUID]bool {
+	set := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
@@ -0,0 +1,118 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunEscrows checks escrows against the shared escrow contract.
+func RunEscrows(t *testing.T, wallets repository.Wallet, escrows repository.Escrows) {
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	newEscrow := func(t *testing.T, expiresAt time.Time) (*domain.Escrow, domain.EscrowTransition) {
+		t.Helper()
+		e, created, err := domain.NewEscrow(createWallet(t, wallets, 0), createWallet(t, wallets, 0), 100, expiresAt, "test", at.Add(-time.Hour))
+		require.NoError(t, err)
+		return e, created
+	}
+
+	t.Run("Create_Get", func(t *testing.T) {
+		e, created := newEscrow(t, at)
+		require.NoError(t, escrows.Create(t.Context(), e, created))
+
+		got, err := escrows.Get(t.Context(), e.ID)
+		require.NoError(t, err)
+		assert.Equal(t, e.BuyerID, got.BuyerID)
+		assert.Equal(t, e.SellerID, got.SellerID)
+		assert.Equal(t, domain.EscrowHeld, got.Status)
+		assert.True(t, got.ExpiresAt.Equal(at))
+
+		_, err = escrows.Get(t.Context(), uuid.New())
+		assert.ErrorIs(t, err, domain.ErrEscrowNotFound)
+	})
+
+	t.Run("Create_UnknownWallet_NotFound", func(t *testing.T) {
+		e, created, err := domain.NewEscrow(createWallet(t, wallets, 0), uuid.New(), 100, at, "test", at.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.ErrorIs(t, escrows.Create(t.Context(), e, created), domain.ErrWalletNotFound)
+	})
+
+	t.Run("Update_TransitionsInOrder", func(t *testing.T) {
+		e, created := newEscrow(t, at)
+		require.NoError(t, escrows.Create(t.Context(), e, created))
+
+		for _, event := range []string{domain.EscrowConfirmSeller, domain.EscrowConfirmBuyer} {
+			ctx, tx, err := wallets.WithTx(t.Context())
+			require.NoError(t, err)
+			locked, err := escrows.GetForUpdate(ctx, e.ID)
+			require.NoError(t, err)
+			transition, err := locked.Apply(event, "test", at.Add(-time.Minute))
+			require.NoError(t, err)
+			require.NoError(t, escrows.Update(ctx, locked, transition))
+			require.NoError(t, tx.Commit(ctx))
+		}
+
+		got, err := escrows.Get(t.Context(), e.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EscrowReleased, got.Status)
+
+		transitions, err := escrows.Transitions(t.Context(), e.ID)
+		require.NoError(t, err)
+		require.Len(t, transitions, 3)
+		assert.Equal(t, domain.EscrowCreate, transitions[0].Event)
+		assert.Equal(t, domain.EscrowSellerConfirmed, transitions[1].To)
+		assert.Equal(t, domain.EscrowSellerConfirmed, transitions[2].From)
+		assert.Equal(t, domain.EscrowReleased, transitions[2].To)
+	})
+
+	t.Run("Update_RolledBack", func(t *testing.T) {
+		e, created := newEscrow(t, at)
+		require.NoError(t, escrows.Create(t.Context(), e, created))
+
+		ctx, tx, err := wallets.WithTx(t.Context())
+		require.NoError(t, err)
+		transition, err := e.Apply(domain.EscrowCancel, "test", at.Add(-time.Minute))
+		require.NoError(t, err)
+		require.NoError(t, escrows.Update(ctx, e, transition))
+		require.NoError(t, tx.Rollback(ctx))
+
+		got, err := escrows.Get(t.Context(), e.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EscrowHeld, got.Status)
+		transitions, err := escrows.Transitions(t.Context(), e.ID)
+		require.NoError(t, err)
+		assert.Len(t, transitions, 1)
+	})
+
+	t.Run("Expired_OnlyOpenAndDue", func(t *testing.T) {
+		due, created := newEscrow(t, at.Add(-time.Minute))
+		require.NoError(t, escrows.Create(t.Context(), due, created))
+		later, created := newEscrow(t, at.Add(time.Minute))
+		require.NoError(t, escrows.Create(t.Context(), later, created))
+		closed, created := newEscrow(t, at.Add(-time.Minute))
+		require.NoError(t, escrows.Create(t.Context(), closed, created))
+		transition, err := closed.Apply(domain.EscrowCancel, "test", at.Add(-30*time.Minute))
+		require.NoError(t, err)
+		require.NoError(t, escrows.Update(t.Context(), closed, transition))
+
+		var found []uuid.UUID
+		after := uuid.Nil
+		for {
+			ids, err := escrows.Expired(t.Context(), at, after, 1)
+			require.NoError(t, err)
+			if len(ids) == 0 {
+				break
+			}
+			found = append(found, ids...)
+			after = ids[len(ids)-1]
+		}
+		assert.Contains(t, found, due.ID)
+		assert.NotContains(t, found, later.ID)
+		assert.NotContains(t, found, closed.ID)
+	})
+}
@@ -0,0 +1,111 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunInterest checks interest against the shared interest contract.
+func RunInterest(t *testing.T, wallets repository.Wallet, interest repository.Interest) {
+	period := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("SetRate_KeepsProgress", func(t *testing.T) {
+		id := createWallet(t, wallets, 0)
+		_, err := interest.SetRate(t.Context(), id, 500, period, period, at)
+		require.NoError(t, err)
+
+		account, err := interest.SetRate(t.Context(), id, 250, period.AddDate(0, 0, 5), period.AddDate(0, 1, 0), at.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(250), account.Rate)
+		assert.True(t, account.NextAccrualAt.Equal(period))
+		assert.True(t, account.NextPayoutAt.Equal(period))
+	})
+
+	t.Run("SetRate_UnknownWallet_NotFound", func(t *testing.T) {
+		_, err := interest.SetRate(t.Context(), uuid.New(), 500, period, period, at)
+		assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+	})
+
+	t.Run("Account_Unset_NotFound", func(t *testing.T) {
+		_, err := interest.Account(t.Context(), createWallet(t, wallets, 0))
+		assert.ErrorIs(t, err, domain.ErrInterestAccountNotFound)
+	})
+
+	t.Run("Accrue_SameDayTwice_Once", func(t *testing.T) {
+		id := createWallet(t, wallets, 0)
+		_, err := interest.SetRate(t.Context(), id, 500, period, period, at)
+		require.NoError(t, err)
+
+		accrual := &domain.InterestAccrual{WalletID: id, Day: period, Balance: 1000, Rate: 500, Amount: 7}
+		ok, err := interest.Accrue(t.Context(), accrual, period.AddDate(0, 0, 1), at)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = interest.Accrue(t.Context(), accrual, period.AddDate(0, 0, 1), at)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		account, err := interest.Account(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), account.Accrued)
+		assert.True(t, account.NextAccrualAt.Equal(period.AddDate(0, 0, 1)))
+
+		sum, err := interest.Accrued(t.Context(), id, period, period.AddDate(0, 1, 0))
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), sum)
+	})
+
+	t.Run("Pay_OnlyAccruedPeriodOnce", func(t *testing.T) {
+		id := createWallet(t, wallets, 0)
+		_, err := interest.SetRate(t.Context(), id, 500, period, period, at)
+		require.NoError(t, err)
+		next := period.AddDate(0, 1, 0)
+		payout := &domain.InterestPayout{WalletID: id, Period: period, Amount: 0, PaidAt: next}
+
+		// Месяц ещё не начислен целиком
+		ok, err := interest.Pay(t.Context(), payout, next)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		for day := period; day.Before(next); day = day.AddDate(0, 0, 1) {
+			ok, err := interest.Accrue(t.Context(), &domain.InterestAccrual{WalletID: id, Day: day}, day.AddDate(0, 0, 1), at)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+
+		ok, err = interest.Pay(t.Context(), payout, next)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = interest.Pay(t.Context(), payout, next)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		account, err := interest.Account(t.Context(), id)
+		require.NoError(t, err)
+		assert.True(t, account.NextPayoutAt.Equal(next))
+	})
+
+	t.Run("Accounts_Paged", func(t *testing.T) {
+		for range 2 {
+			_, err := interest.SetRate(t.Context(), createWallet(t, wallets, 0), 500, period, period, at)
+			require.NoError(t, err)
+		}
+
+		first, err := interest.Accounts(t.Context(), uuid.Nil, 1)
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+
+		rest, err := interest.Accounts(t.Context(), first[0].WalletID, 100)
+		require.NoError(t, err)
+		require.NotEmpty(t, rest)
+		for _, account := range rest {
+			assert.Greater(t, account.WalletID.String(), first[0].WalletID.String())
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package repositorytest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ledgerCase struct {
+	name string
+	fn   func(t *testing.T, wallets repository.Wallet, ledger repository.Ledger)
+}
+
+// RunLedger checks ledger against the shared transaction chain contract.
+// wallets must share transactions with ledger. Every case creates its own
+// wallets.
+func RunLedger(t *testing.T, wallets repository.Wallet, ledger repository.Ledger) {
+	cases := []ledgerCase{
+		{"Last_NoTransactions_Nil", testLedgerLastEmpty},
+		{"Append_RoundTrip_Intact", testLedgerRoundTrip},
+		{"Append_TxRolledBack_Discarded", testLedgerRolledBack},
+		{"LastBefore_Time_Bounded", testLedgerLastBefore},
+		{"List_AfterSeq_Paged", testLedgerPaging},
+		{"WalletIDs_Ordered_Paged", testLedgerWalletIDs},
+		{"SnapshotBefore_Time_Bounded", testLedgerSnapshotBefore},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, wallets, ledger)
+		})
+	}
+}
+
+// appendChain appends n deposits of 10 to the wallet outside a transaction.
+func appendChain(t *testing.T, ledger repository.Ledger, walletID uuid.UUID, n int) []*domain.Transaction {
+	t.Helper()
+
+	var (
+		prev *domain.Transaction
+		txs  []*domain.Transaction
+	)
+	for i := range n {
+		tx := domain.NewTransaction(prev, walletID, domain.TransactionDeposit, 10, int64(10*(i+1)), time.Now())
+		require.NoError(t, ledger.Append(t.Context(), tx))
+		txs = append(txs, tx)
+		prev = tx
+	}
+	return txs
+}
+
+func testLedgerLastEmpty(t *testing.T, wallets repository.Wallet, ledger repository.Ledger) {
+	id := createWallet(t, wallets, 0)
+
+	last, err := ledger.Last(t.Context(), id)
+	require.NoError(t, err)
+	assert.Nil(t, last)
+}
+
+func testLedgerRoundTrip(t *testing.T, wallets repository.Wallet, ledger repository.Ledger) {
+	id := createWallet(t, wallets, 0)
+	appended := appendChain(t, ledger, id, 2)
+
+	last, err := ledger.Last(t.Context(), id)
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	assert.Equal(t, *appended[1], *last)
+	// Хеш, пересчитанный после чтения, совпадает с записанным
+	assert.Nil(t, last.Check(appended[0]))
+}
+
+func testLedgerRolledBack(t *testing.T, wallets repository.Wallet, ledger repository.Ledger) {
+	id := createWallet(t, wallets, 0)
+
+	ctx, tx, err := wallets.WithTx(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, ledger.Append(ctx, domain.NewTransaction(nil, id, domain.TransactionDeposit, 5, 5, time.Now())))
+
+	// Транзакция видит собственную запись
+	last, err := ledger.Last(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	require.NoError(t, tx.Rollback(t.Context()))
+
+	last, err = ledger.Last(t.Context(), id)
+	require.NoError(t, err)
+	assert.Nil(t, last)
+}
+
+func testLedgerLastBefore(t *testing.T, wallets repository.Wallet, ledger repository.Ledger) {
+	id := createWallet(t, wallets, 0)
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	first := domain.NewTransaction(nil, id, domain.TransactionDeposit, 10, 10, at)
+	second := domain.NewTransaction(first, id, domain.TransactionDeposit, 10, 20, at.Add(time.Minute))
+	require.NoError(t, ledger.Append(t.Context(), first))
+	require.NoError(t, ledger.Append(t.Context(), second))
+
+	got, err := ledger.LastBefore(t.Context(), id, at)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	// Граница исключается
+	got, err = ledger.LastBefore(t.Context(), id, second.At)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(1), got.Seq)
+
+	got, err = ledger.LastBefore(t.Context(), id, at.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(2), got.Seq)
+}
+
+func testLedgerSnapshotBefore(t *testing.T, wallets repository.Wallet, ledger repository.Ledger) {
+	id := createWallet(t, wallets, 0)
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	first := &domain.BalanceSnapshot{WalletID: id, Seq: 3, Balance: 30, At: at}
+	second := &domain.BalanceSnapshot{WalletID: id, Seq: 7, Balance: 70, At: at.Add(time.Minute)}
+	require.NoError(t, ledger.SaveSnapshot(t.Context(), first))
+	require.NoError(t, ledger.SaveSnapshot(t.Context(), second))
+	// Повторное сохранение ничего не меняет
+	require.NoError(t, ledger.SaveSnapshot(t.Context(), first))
+
+	got, err := ledger.SnapshotBefore(t.Context(), id, at)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = ledger.SnapshotBefore(t.Context(), id, second.At)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, *first, *got)
+
+	got, err = ledger.SnapshotBefore(t.Context(), id, at.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(7), got.Seq)
+	assert.Equal(t, int64(70), got.Balance)
+}
+
+func testLedgerPaging(t *testing.T, wallets repository.Wallet, ledger repository.Ledger) {
+	id := createWallet(t, wallets, 0)
+	appendChain(t, ledger, id, 5)
+
+	page, err := ledger.List(t.Context(), id, 0, 3)
+	require.NoError(t, err)
+	require.Len(t, page, 3)
+	assert.Equal(t, []int64{1, 2, 3}, seqs(page))
+
+	page, err = ledger.List(t.Context(), id, 3, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 5}, seqs(page))
+}
+
+func testLedgerWalletIDs(t *testing.T, wallets repository.Wallet, ledger repository.Ledger) {
+	created := map[uuid.UUID]bool{}
+	for range 3 {
+		created[createWallet(t, wallets, 0)] = true
+	}
+
+	var (
+		seen  []uuid.UUID
+		after uuid.UUID
+	)
+	for {
+		ids, err := ledger.WalletIDs(t.Context(), after, 2)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(ids), 2)
+		if len(ids) == 0 {
+			break
+		}
+		seen = append(seen, ids...)
+		after = ids[len(ids)-1]
+	}
+
+	for i := 1; i < len(seen); i++ {
+		assert.Negative(t, bytes.Compare(seen[i-1][:], seen[i][:]))
+	}
+	for _, id := range seen {
+		delete(created, id)
+	}
+	assert.Empty(t, created)
+}
+
+func seqs(txs []domain.Transaction) []int64 {
+	out := make([]int64, 0, len(txs))
+	for _, tx := range txs {
+		out = append(out, tx.Seq)
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+package repositorytest
+
+import (
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scheduleCase struct {
+	name string
+	fn   func(t *testing.T, wallets repository.Wallet, schedules repository.Schedules)
+}
+
+// RunSchedules checks schedules against the shared scheduling contract.
+// Every case creates its own wallets and leaves none of its schedules due,
+// so the cases may claim each other's schedules only while they run.
+func RunSchedules(t *testing.T, wallets repository.Wallet, schedules repository.Schedules) {
+	cases := []scheduleCase{
+		{"Create_RoundTrip_Pending", testScheduleRoundTrip},
+		{"Create_UnknownWallet_NotFound", testScheduleUnknownWallet},
+		{"Get_Unknown_NotFound", testScheduleGetUnknown},
+		{"List_Status_Filtered", testScheduleList},
+		{"Cancel_States_Guarded", testScheduleCancel},
+		{"Claim_Due_Once", testScheduleClaimOnce},
+		{"Finish_StaleClaim_LeaseLost", testScheduleLeaseLost},
+		{"Claim_ExpiredLease_Interrupted", testScheduleExpiredLease},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, wallets, schedules)
+		})
+	}
+}
+
+func createSchedule(t *testing.T, schedules repository.Schedules, walletID uuid.UUID, runAt time.Time) *domain.Schedule {
+	t.Helper()
+
+	schedule, err := domain.NewSchedule(walletID, domain.ScheduleDeposit, 10, "", runAt, 3, "tester", time.Now())
+	require.NoError(t, err)
+
+	created, err := schedules.Create(t.Context(), schedule)
+	require.NoError(t, err)
+	return created
+}
+
+// claimOf claims every due schedule and returns the claim of id, if any.
+// The other claims are left to expire.
+func claimOf(t *testing.T, schedules repository.Schedules, lease time.Duration, id uuid.UUID) *domain.ScheduleClaim {
+	t.Helper()
+
+	claims, err := schedules.Claim(t.Context(), lease, 100)
+	require.NoError(t, err)
+	for i := range claims {
+		if claims[i].Schedule.ID == id {
+			return &claims[i]
+		}
+	}
+	return nil
+}
+
+// finish ends the claimed one-off schedule as completed.
+func finish(t *testing.T, schedules repository.Schedules, schedule domain.Schedule) {
+	t.Helper()
+
+	schedule.Status = domain.ScheduleCompleted
+	schedule.Runs++
+	schedule.LastRunAt = time.Now().UTC().Truncate(time.Microsecond)
+	schedule.UpdatedAt = schedule.LastRunAt
+	require.NoError(t, schedules.Finish(t.Context(), &schedule))
+}
+
+func testScheduleRoundTrip(t *testing.T, wallets repository.Wallet, schedules repository.Schedules) {
+	id := createWallet(t, wallets, 0)
+	schedule, err := domain.NewSchedule(id, domain.ScheduleWithdraw, 25, "0 9 * * 1", time.Now().Add(time.Hour), 4, "tester", time.Now())
+	require.NoError(t, err)
+
+	created, err := schedules.Create(t.Context(), schedule)
+	require.NoError(t, err)
+	assert.Equal(t, *schedule, *created)
+
+	got, err := schedules.Get(t.Context(), schedule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *schedule, *got)
+	assert.True(t, got.Recurring())
+}
+
+func testScheduleUnknownWallet(t *testing.T, _ repository.Wallet, schedules repository.Schedules) {
+	schedule, err := domain.NewSchedule(uuid.New(), domain.ScheduleDeposit, 10, "", time.Now().Add(time.Hour), 1, "", time.Now())
+	require.NoError(t, err)
+
+	_, err = schedules.Create(t.Context(), schedule)
+	assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+}
+
+func testScheduleGetUnknown(t *testing.T, _ repository.Wallet, schedules repository.Schedules) {
+	_, err := schedules.Get(t.Context(), uuid.New())
+	assert.ErrorIs(t, err, domain.ErrScheduleNotFound)
+}
+
+func testScheduleList(t *testing.T, wallets repository.Wallet, schedules repository.Schedules) {
+	id := createWallet(t, wallets, 0)
+	later := time.Now().Add(time.Hour)
+	first := createSchedule(t, schedules, id, later)
+	second := createSchedule(t, schedules, id, later)
+	_, err := schedules.Cancel(t.Context(), first.ID, time.Now())
+	require.NoError(t, err)
+	// Расписания другого кошелька в список не попадают
+	createSchedule(t, schedules, createWallet(t, wallets, 0), later)
+
+	all, err := schedules.List(t.Context(), id, "", 10)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	pending, err := schedules.List(t.Context(), id, domain.SchedulePending, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.ID, pending[0].ID)
+
+	limited, err := schedules.List(t.Context(), id, "", 1)
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+}
+
+func testScheduleCancel(t *testing.T, wallets repository.Wallet, schedules repository.Schedules) {
+	id := createWallet(t, wallets, 0)
+	schedule := createSchedule(t, schedules, id, time.Now().Add(time.Hour))
+	at := time.Now().UTC().Truncate(time.Microsecond)
+
+	cancelled, err := schedules.Cancel(t.Context(), schedule.ID, at)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ScheduleCancelled, cancelled.Status)
+	assert.Equal(t, at, cancelled.UpdatedAt)
+
+	_, err = schedules.Cancel(t.Context(), schedule.ID, at)
+	assert.ErrorIs(t, err, domain.ErrScheduleNotCancellable)
+
+	_, err = schedules.Cancel(t.Context(), uuid.New(), at)
+	assert.ErrorIs(t, err, domain.ErrScheduleNotFound)
+
+	// Запущенное расписание отменить нельзя
+	running := createSchedule(t, schedules, id, time.Now().Add(-time.Minute))
+	claim := claimOf(t, schedules, time.Minute, running.ID)
+	require.NotNil(t, claim)
+	_, err = schedules.Cancel(t.Context(), running.ID, at)
+	assert.ErrorIs(t, err, domain.ErrScheduleNotCancellable)
+	finish(t, schedules, claim.Schedule)
+}
+
+func testScheduleClaimOnce(t *testing.T, wallets repository.Wallet, schedules repository.Schedules) {
+	id := createWallet(t, wallets, 0)
+	due := createSchedule(t, schedules, id, time.Now().Add(-time.Minute))
+	future := createSchedule(t, schedules, id, time.Now().Add(time.Hour))
+
+	claim := claimOf(t, schedules, time.Minute, due.ID)
+	require.NotNil(t, claim)
+	assert.Nil(t, claim.Interrupted)
+	assert.Equal(t, domain.ScheduleRunning, claim.Schedule.Status)
+	assert.Equal(t, 1, claim.Schedule.Attempts)
+	assert.True(t, claim.Schedule.LeaseUntil.After(claim.Schedule.ClaimedAt))
+
+	// Пока аренда не истекла, расписание не выдаётся повторно
+	assert.Nil(t, claimOf(t, schedules, time.Minute, due.ID))
+	assert.Nil(t, claimOf(t, schedules, time.Minute, future.ID))
+
+	finish(t, schedules, claim.Schedule)
+	got, err := schedules.Get(t.Context(), due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ScheduleCompleted, got.Status)
+	assert.Equal(t, int64(1), got.Runs)
+	assert.True(t, got.ClaimedAt.IsZero())
+	assert.Nil(t, claimOf(t, schedules, time.Minute, due.ID))
+}
+
+func testScheduleLeaseLost(t *testing.T, wallets repository.Wallet, schedules repository.Schedules) {
+	id := createWallet(t, wallets, 0)
+	schedule := createSchedule(t, schedules, id, time.Now().Add(-time.Minute))
+
+	claim := claimOf(t, schedules, time.Minute, schedule.ID)
+	require.NotNil(t, claim)
+	stale := claim.Schedule
+	stale.ClaimedAt = stale.ClaimedAt.Add(-time.Second)
+	stale.Status = domain.ScheduleFailed
+	assert.ErrorIs(t, schedules.Finish(t.Context(), &stale), domain.ErrScheduleLeaseLost)
+
+	finish(t, schedules, claim.Schedule)
+	// Повторное завершение той же аренды тоже отклоняется
+	again := claim.Schedule
+	assert.ErrorIs(t, schedules.Finish(t.Context(), &again), domain.ErrScheduleLeaseLost)
+}
+
+func testScheduleExpiredLease(t *testing.T, wallets repository.Wallet, schedules repository.Schedules) {
+	id := createWallet(t, wallets, 0)
+	schedule := createSchedule(t, schedules, id, time.Now().Add(-time.Minute))
+
+	first := claimOf(t, schedules, time.Millisecond, schedule.ID)
+	require.NotNil(t, first)
+	time.Sleep(10 * time.Millisecond)
+
+	second := claimOf(t, schedules, time.Minute, schedule.ID)
+	require.NotNil(t, second)
+	require.NotNil(t, second.Interrupted)
+	assert.Equal(t, first.Schedule.ClaimedAt, *second.Interrupted)
+	assert.Equal(t, 2, second.Schedule.Attempts)
+
+	// Первый исполнитель потерял аренду
+	assert.ErrorIs(t, schedules.Finish(t.Context(), &first.Schedule), domain.ErrScheduleLeaseLost)
+	finish(t, schedules, second.Schedule)
+}
@@ -0,0 +1,69 @@
+package repositorytest
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/memory"
+	"wallet-service/pkg/testdb"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+	"github.com/ydb-platform/ydb-go-sdk/v3/balancers"
+)
+
+var testLogger = slog.New(slog.DiscardHandler)
+
+// WithRepository runs fn against the storage named by DATABASE_DRIVER with
+// the test wallets loaded: postgres (the default) and ydb start a container,
+// memory keeps the wallets in process and needs no Docker.
+func WithRepository(t *testing.T, migrationsPath []string, fn func(repo *repository.Repository)) {
+	WithRepositoryIsolation(t, migrationsPath, "", fn)
+}
+
+// WithRepositoryIsolation is WithRepository with transactions running at
+// isolation by default. YDB ignores it: its transactions are always
+// serializable.
+func WithRepositoryIsolation(t *testing.T, migrationsPath []string, isolation string, fn func(repo *repository.Repository)) {
+	switch os.Getenv("DATABASE_DRIVER") {
+	case config.DriverMemory:
+		fn(memory.NewRepository(memory.WithWallets(memory.TestWallets), memory.WithDefaultIsolation(isolation)))
+	case config.DriverYDB:
+		testdb.WithYDB(t, func(dsn string) {
+			cfg := config.DatabaseConfig{YDBDSN: dsn, ApplicationName: "wallet-service-test", ConnectTimeout: time.Minute}
+			y, err := repository.OpenYDB(t.Context(), cfg, ydb.WithBalancer(balancers.SingleConn()))
+			if err != nil {
+				t.Fatalf("failed to open ydb: %v", err)
+			}
+			defer func() { _ = y.Close(context.Background()) }()
+
+			if err := repository.BootstrapYDBSchema(t.Context(), y.DB); err != nil {
+				t.Fatalf("failed to create ydb schema: %v", err)
+			}
+			repo := repository.NewYDBRepository(y.DB, testLogger)
+			for id, balance := range memory.TestWallets {
+				wallet, err := domain.NewWallet(id, balance)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := repo.Create(t.Context(), wallet); err != nil {
+					t.Fatalf("failed to create test wallet: %v", err)
+				}
+			}
+			fn(repo)
+		})
+	default:
+		testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
+			repo, err := repository.NewPostgresRepository(pool, testLogger, repository.WithDefaultIsolation(isolation))
+			if err != nil {
+				t.Fatalf("failed to create repository: %v", err)
+			}
+			fn(repo)
+		})
+	}
+}
@@ -0,0 +1,451 @@
+// Package repositorytest holds the behaviour every repository.Wallet
+// implementation must share with the Postgres one.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockWait is how long a blocked GetForUpdate is given to prove it waits.
+const lockWait = 100 * time.Millisecond
+
+var isolations = []string{config.IsolationReadCommitted, config.IsolationRepeatableRead, config.IsolationSerializable}
+
+var errReserve = errors.New("withdrawal breaks the reserve")
+
+type options struct {
+	optimistic bool
+}
+
+type Option func(o *options)
+
+// Optimistic is for repositories whose GetForUpdate does not block: instead
+// of waiting for the lock, the transaction that loses a race fails with
+// repository.ErrTxConflict.
+func Optimistic() Option {
+	return func(o *options) {
+		o.optimistic = true
+	}
+}
+
+type testCase struct {
+	name string
+	fn   func(t *testing.T, repo repository.Wallet)
+}
+
+// Run checks repo against the shared contract. Every case creates its own
+// wallets, so repo may already contain data.
+func Run(t *testing.T, repo repository.Wallet, opts ...Option) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cases := []testCase{
+		{"Create_NewWallet_Readable", testCreateReadable},
+		{"Create_Duplicate_ErrWalletExists", testCreateDuplicate},
+		{"Get_Missing_ErrWalletNotFound", testGetMissing},
+		{"Update_Missing_ErrWalletNotFound", testUpdateMissing},
+		{"GetForUpdate_Missing_ErrWalletNotFound", testGetForUpdateMissing},
+		{"WithTx_Commit_ChangesVisible", testCommitVisible},
+		{"WithTx_Rollback_ChangesDiscarded", testRollbackDiscarded},
+		{"WithTx_Uncommitted_InvisibleOutside", testUncommittedInvisible},
+		{"WithTx_CreateRolledBack_WalletMissing", testCreateRolledBack},
+		{"WithTx_ConcurrentDeposits_NoLostUpdates", testConcurrentDeposits},
+		{"WithTx_Serializable_NoWriteSkew", testSerializableNoWriteSkew},
+		{"WithTx_FinishTwice_ErrTxClosed", testFinishTwice},
+	}
+	if o.optimistic {
+		cases = append(cases,
+			testCase{"GetForUpdate_ConcurrentWrite_ErrTxConflict", testGetForUpdateConflict},
+		)
+	} else {
+		cases = append(cases,
+			testCase{"GetForUpdate_Locked_WaitsForCommit", testGetForUpdateWaits},
+			testCase{"GetForUpdate_Locked_ContextCancelled", testGetForUpdateCancelled},
+		)
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, repo)
+		})
+	}
+}
+
+func createWallet(t *testing.T, repo repository.Wallet, balance int64) uuid.UUID {
+	t.Helper()
+
+	wallet, err := domain.NewWallet(uuid.New(), balance)
+	require.NoError(t, err)
+
+	created, err := repo.Create(t.Context(), wallet)
+	require.NoError(t, err)
+	require.Equal(t, balance, created.Balance())
+
+	return wallet.ID()
+}
+
+func balanceOf(t *testing.T, ctx context.Context, repo repository.Wallet, id uuid.UUID) int64 {
+	t.Helper()
+
+	wallet, err := repo.Get(ctx, id)
+	require.NoError(t, err)
+
+	return wallet.Balance()
+}
+
+func setBalance(t *testing.T, ctx context.Context, repo repository.Wallet, id uuid.UUID, balance int64) {
+	t.Helper()
+
+	wallet, err := domain.NewWallet(id, balance)
+	require.NoError(t, err)
+
+	_, err = repo.Update(ctx, wallet)
+	require.NoError(t, err)
+}
+
+func testCreateReadable(t *testing.T, repo repository.Wallet) {
+	id := createWallet(t, repo, 42)
+
+	wallet, err := repo.Get(t.Context(), id)
+	require.NoError(t, err)
+	assert.Equal(t, id, wallet.ID())
+	assert.Equal(t, int64(42), wallet.Balance())
+}
+
+func testCreateDuplicate(t *testing.T, repo repository.Wallet) {
+	id := createWallet(t, repo, 10)
+
+	wallet, err := domain.NewWallet(id, 500)
+	require.NoError(t, err)
+
+	_, err = repo.Create(t.Context(), wallet)
+	require.ErrorIs(t, err, domain.ErrWalletExists)
+
+	assert.Equal(t, int64(10), balanceOf(t, t.Context(), repo, id))
+}
+
+func testGetMissing(t *testing.T, repo repository.Wallet) {
+	_, err := repo.Get(t.Context(), uuid.New())
+	require.ErrorIs(t, err, domain.ErrWalletNotFound)
+}
+
+func testUpdateMissing(t *testing.T, repo repository.Wallet) {
+	wallet, err := domain.NewWallet(uuid.New(), 10)
+	require.NoError(t, err)
+
+	_, err = repo.Update(t.Context(), wallet)
+	require.ErrorIs(t, err, domain.ErrWalletNotFound)
+}
+
+func testGetForUpdateMissing(t *testing.T, repo repository.Wallet) {
+	ctx, tx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(t.Context()) }()
+
+	_, err = repo.GetForUpdate(ctx, uuid.New())
+	require.ErrorIs(t, err, domain.ErrWalletNotFound)
+}
+
+func testCommitVisible(t *testing.T, repo repository.Wallet) {
+	id := createWallet(t, repo, 100)
+
+	ctx, tx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+
+	wallet, err := repo.GetForUpdate(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), wallet.Balance())
+
+	setBalance(t, ctx, repo, id, 150)
+	assert.Equal(t, int64(150), balanceOf(t, ctx, repo, id))
+
+	require.NoError(t, tx.Commit(ctx))
+	assert.Equal(t, int64(150), balanceOf(t, t.Context(), repo, id))
+}
+
+func testRollbackDiscarded(t *testing.T, repo repository.Wallet) {
+	id := createWallet(t, repo, 100)
+
+	ctx, tx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+
+	_, err = repo.GetForUpdate(ctx, id)
+	require.NoError(t, err)
+	setBalance(t, ctx, repo, id, 0)
+
+	require.NoError(t, tx.Rollback(ctx))
+	assert.Equal(t, int64(100), balanceOf(t, t.Context(), repo, id))
+}
+
+func testUncommittedInvisible(t *testing.T, repo repository.Wallet) {
+	id := createWallet(t, repo, 100)
+
+	ctx, tx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(t.Context()) }()
+
+	setBalance(t, ctx, repo, id, 7)
+
+	// Чтение вне транзакции не блокируется и видит только закоммиченное
+	assert.Equal(t, int64(100), balanceOf(t, t.Context(), repo, id))
+	assert.Equal(t, int64(7), balanceOf(t, ctx, repo, id))
+}
+
+func testCreateRolledBack(t *testing.T, repo repository.Wallet) {
+	ctx, tx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+
+	wallet, err := domain.NewWallet(uuid.New(), 5)
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, wallet)
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Rollback(ctx))
+
+	_, err = repo.Get(t.Context(), wallet.ID())
+	require.ErrorIs(t, err, domain.ErrWalletNotFound)
+}
+
+func testGetForUpdateWaits(t *testing.T, repo repository.Wallet) {
+	id := createWallet(t, repo, 100)
+
+	first, firstTx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+	_, err = repo.GetForUpdate(first, id)
+	require.NoError(t, err)
+
+	second, secondTx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+	defer func() { _ = secondTx.Rollback(t.Context()) }()
+
+	type result struct {
+		wallet *domain.Wallet
+		err    error
+	}
+	locked := make(chan result, 1)
+	go func() {
+		wallet, err := repo.GetForUpdate(second, id)
+		locked <- result{wallet, err}
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("GetForUpdate returned while the row was locked")
+	case <-time.After(lockWait):
+	}
+
+	setBalance(t, first, repo, id, 60)
+	require.NoError(t, firstTx.Commit(first))
+
+	select {
+	case res := <-locked:
+		require.NoError(t, res.err)
+		// После снятия блокировки читается уже закоммиченный баланс
+		assert.Equal(t, int64(60), res.wallet.Balance())
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetForUpdate did not return after the lock was released")
+	}
+}
+
+func testGetForUpdateCancelled(t *testing.T, repo repository.Wallet) {
+	id := createWallet(t, repo, 100)
+
+	first, firstTx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+	defer func() { _ = firstTx.Rollback(t.Context()) }()
+	_, err = repo.GetForUpdate(first, id)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), lockWait)
+	defer cancel()
+
+	second, secondTx, err := repo.WithTx(ctx)
+	require.NoError(t, err)
+	defer func() { _ = secondTx.Rollback(t.Context()) }()
+
+	_, err = repo.GetForUpdate(second, id)
+	require.Error(t, err)
+}
+
+func testGetForUpdateConflict(t *testing.T, repo repository.Wallet) {
+	id := createWallet(t, repo, 100)
+
+	first, firstTx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+	defer func() { _ = firstTx.Rollback(t.Context()) }()
+	_, err = repo.GetForUpdate(first, id)
+	require.NoError(t, err)
+
+	second, secondTx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+	_, err = repo.GetForUpdate(second, id)
+	require.NoError(t, err)
+	setBalance(t, second, repo, id, 60)
+	require.NoError(t, secondTx.Commit(second))
+
+	// Первая транзакция читала версию, которую уже изменили, и должна упасть
+	wallet, err := domain.NewWallet(id, 150)
+	require.NoError(t, err)
+	_, err = repo.Update(first, wallet)
+	if err == nil {
+		err = firstTx.Commit(first)
+	}
+	require.ErrorIs(t, err, repository.ErrTxConflict)
+
+	assert.Equal(t, int64(60), balanceOf(t, t.Context(), repo, id))
+}
+
+func testConcurrentDeposits(t *testing.T, repo repository.Wallet) {
+	const workers = 20
+
+	for _, level := range isolations {
+		t.Run(level, func(t *testing.T) {
+			id := createWallet(t, repo, 0)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, workers)
+			for range workers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- depositWithRetry(t.Context(), repo, id, 1, workers, repository.WithIsolation(level))
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, int64(workers), balanceOf(t, t.Context(), repo, id))
+		})
+	}
+}
+
+// depositWithRetry repeats the deposit while it conflicts with another one,
+// which happens with optimistic repositories and above read committed.
+func depositWithRetry(ctx context.Context, repo repository.Wallet, id uuid.UUID, amount int64, attempts int, opts ...repository.TxOption) error {
+	var err error
+	for range attempts {
+		err = inTx(ctx, repo, func(ctx context.Context) error {
+			return deposit(ctx, repo, id, amount)
+		}, opts...)
+		if !errors.Is(err, repository.ErrTxConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+func deposit(ctx context.Context, repo repository.Wallet, id uuid.UUID, amount int64) error {
+	wallet, err := repo.GetForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := wallet.Deposit(amount); err != nil {
+		return err
+	}
+	_, err = repo.Update(ctx, wallet)
+	return err
+}
+
+// inTx runs fn in a transaction and commits it when fn succeeds.
+func inTx(ctx context.Context, repo repository.Wallet, fn func(ctx context.Context) error, opts ...repository.TxOption) error {
+	txCtx, tx, err := repo.WithTx(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(txCtx); err != nil {
+		return err
+	}
+	return tx.Commit(txCtx)
+}
+
+// withdrawKeepingReserve withdraws amount from one wallet only if both
+// wallets together keep at least reserve. Neither row is locked explicitly,
+// so only the isolation level protects the rule.
+func withdrawKeepingReserve(ctx context.Context, repo repository.Wallet, from, other uuid.UUID, amount, reserve int64) error {
+	wallet, err := repo.Get(ctx, from)
+	if err != nil {
+		return err
+	}
+	otherWallet, err := repo.Get(ctx, other)
+	if err != nil {
+		return err
+	}
+	if wallet.Balance()+otherWallet.Balance()-amount < reserve {
+		return errReserve
+	}
+	if err := wallet.Withdraw(amount); err != nil {
+		return err
+	}
+	_, err = repo.Update(ctx, wallet)
+	return err
+}
+
+func testSerializableNoWriteSkew(t *testing.T, repo repository.Wallet) {
+	const reserve = 100
+	a := createWallet(t, repo, 100)
+	b := createWallet(t, repo, 100)
+	serializable := repository.WithIsolation(config.IsolationSerializable)
+
+	first, firstTx, err := repo.WithTx(t.Context(), serializable)
+	require.NoError(t, err)
+	defer func() { _ = firstTx.Rollback(t.Context()) }()
+	require.NoError(t, withdrawKeepingReserve(first, repo, a, b, 100, reserve))
+
+	second := make(chan error, 1)
+	go func() {
+		second <- inTx(t.Context(), repo, func(ctx context.Context) error {
+			return withdrawKeepingReserve(ctx, repo, b, a, 100, reserve)
+		}, serializable)
+	}()
+
+	// Вторая транзакция успевает прочитать оба кошелька или ждёт блокировки
+	time.Sleep(lockWait)
+	firstErr := firstTx.Commit(first)
+
+	var secondErr error
+	select {
+	case secondErr = <-second:
+	case <-time.After(5 * time.Second):
+		t.Fatal("second transaction did not finish")
+	}
+
+	// Проходит ровно одно списание, второе либо видит нарушение резерва, либо
+	// откатывается как конфликт
+	succeeded := 0
+	for _, err := range []error{firstErr, secondErr} {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.True(t, errors.Is(err, errReserve) || errors.Is(err, repository.ErrTxConflict), "unexpected error: %v", err)
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, int64(reserve), balanceOf(t, t.Context(), repo, a)+balanceOf(t, t.Context(), repo, b))
+}
+
+func testFinishTwice(t *testing.T, repo repository.Wallet) {
+	ctx, tx, err := repo.WithTx(t.Context())
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Commit(ctx))
+	require.ErrorIs(t, tx.Rollback(ctx), repository.ErrTxClosed)
+	require.ErrorIs(t, tx.Commit(ctx), repository.ErrTxClosed)
+}
@@ -0,0 +1,35 @@
+package repositorytest
+
+import (
+	"testing"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunTiers checks tiers against the shared tier contract.
+func RunTiers(t *testing.T, wallets repository.Wallet, tiers repository.Tiers) {
+	t.Run("Tier_Unset_Empty", func(t *testing.T) {
+		tier, err := tiers.Tier(t.Context(), createWallet(t, wallets, 0))
+		require.NoError(t, err)
+		assert.Empty(t, tier)
+	})
+
+	t.Run("SetTier_Overwritten", func(t *testing.T) {
+		id := createWallet(t, wallets, 0)
+		require.NoError(t, tiers.SetTier(t.Context(), id, "silver"))
+		require.NoError(t, tiers.SetTier(t.Context(), id, "gold"))
+
+		tier, err := tiers.Tier(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, "gold", tier)
+	})
+
+	t.Run("SetTier_UnknownWallet_NotFound", func(t *testing.T) {
+		err := tiers.SetTier(t.Context(), uuid.New(), "gold")
+		assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+	})
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/metrics"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	retryReasonConflict   = "conflict"
+	retryReasonConnection = "connection"
+)
+
+// DefaultRetry matches the defaults of the DATABASE_TX_* settings.
+var DefaultRetry = config.RetryConfig{
+	MaxAttempts: 5,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+	Budget:      2 * time.Second,
+}
+
+// TxRunner runs a unit of work in a transaction and repeats it while it fails
+// with a transient error: a serialization failure, a deadlock or a connection
+// dropped before commit. Between attempts it sleeps with jittered exponential
+// backoff.
+type TxRunner struct {
+	r      TxRepository
+	policy config.RetryConfig
+	log    *slog.Logger
+}
+
+func NewTxRunner(r TxRepository, policy config.RetryConfig, log *slog.Logger) *TxRunner {
+	return &TxRunner{r: r, policy: policy, log: log}
+}
+
+// Run calls fn with a transaction begun with opts and commits when fn
+// succeeds. fn may run several times, so it must not have effects outside
+// the transaction. Once retries are exhausted a conflict is reported as
+// domain.ErrWalletBusy and a connection failure as domain.ErrUnavailable.
+func (t *TxRunner) Run(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		reason, err := t.runOnce(ctx, fn, opts)
+		if err == nil || reason == "" {
+			observeAttempts(attempt, err)
+			return err
+		}
+
+		delay := t.backoff(attempt)
+		if attempt >= t.policy.MaxAttempts || (t.policy.Budget > 0 && time.Since(start)+delay > t.policy.Budget) {
+			observeAttempts(attempt, err)
+			return exhausted(reason, attempt, err)
+		}
+
+		metrics.TxRetries.WithLabelValues(reason).Inc()
+		trace.SpanFromContext(ctx).AddEvent("tx.retry", trace.WithAttributes(
+			attribute.Int("tx.attempt", attempt),
+			attribute.String("tx.retry_reason", reason),
+		))
+		t.log.DebugContext(ctx, "retrying transaction",
+			slog.Int("attempt", attempt),
+			slog.String("reason", reason),
+			slog.Duration("delay", delay),
+			logger.Err(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			observeAttempts(attempt, err)
+			return err
+		}
+	}
+}
+
+// runOnce makes a single attempt and returns why its error may be retried,
+// or an empty reason when it may not.
+func (t *TxRunner) runOnce(ctx context.Context, fn func(ctx context.Context) error, opts []TxOption) (string, error) {
+	txCtx, tx, err := t.r.WithTx(ctx, opts...)
+	if err != nil {
+		return retryReason(ctx, err, false), err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, ErrTxClosed) {
+			t.log.WarnContext(ctx, "failed to roll back transaction", logger.Err(err))
+		}
+	}()
+
+	if err := fn(txCtx); err != nil {
+		return retryReason(ctx, err, false), err
+	}
+	if err := tx.Commit(txCtx); err != nil {
+		return retryReason(ctx, err, true), err
+	}
+	return "", nil
+}
+
+// backoff returns the delay before the retry following attempt: half of the
+// exponential step plus a random share of the other half.
+func (t *TxRunner) backoff(attempt int) time.Duration {
+	step := t.policy.BaseDelay << min(attempt-1, 30)
+	if step <= 0 || step > t.policy.MaxDelay {
+		step = t.policy.MaxDelay
+	}
+	if step <= 0 {
+		return 0
+	}
+	half := step / 2
+	return half + rand.N(step-half+1)
+}
+
+func retryReason(ctx context.Context, err error, committing bool) string {
+	if ctx.Err() != nil {
+		return ""
+	}
+	switch {
+	case errors.Is(err, ErrTxConflict):
+		return retryReasonConflict
+	case pgconn.SafeToRetry(err):
+		return retryReasonConnection
+	case committing:
+		// The commit may have been applied before the connection dropped.
+		return ""
+	case isConnectionError(err):
+		return retryReasonConnection
+	default:
+		return ""
+	}
+}
+
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		(errors.As(err, &netErr) && !netErr.Timeout())
+}
+
+func exhausted(reason string, attempts int, err error) error {
+	sentinel := domain.ErrWalletBusy
+	if reason == retryReasonConnection {
+		sentinel = domain.ErrUnavailable
+	}
+	return fmt.Errorf("%w after %d attempts: %w", sentinel, attempts, err)
+}
+
+func observeAttempts(attempts int, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	metrics.TxAttempts.WithLabelValues(outcome).Observe(float64(attempts))
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTx struct {
+	commitErr error
+	commits   int
+	rollbacks int
+}
+
+func (t *fakeTx) Commit(context.Context) error {
+	t.commits++
+	return t.commitErr
+}
+
+func (t *fakeTx) Rollback(context.Context) error {
+	t.rollbacks++
+	return nil
+}
+
+// fakeTxRepository hands out transactions whose commits fail with the queued
+// errors, one per transaction.
+type fakeTxRepository struct {
+	commitErrs []error
+	begun      []*fakeTx
+	options    []TxOptions
+}
+
+func (r *fakeTxRepository) WithTx(ctx context.Context, opts ...TxOption) (context.Context, WalletTx, error) {
+	r.options = append(r.options, NewTxOptions(opts...))
+	tx := &fakeTx{}
+	if len(r.commitErrs) > 0 {
+		tx.commitErr, r.commitErrs = r.commitErrs[0], r.commitErrs[1:]
+	}
+	r.begun = append(r.begun, tx)
+	return ctx, tx, nil
+}
+
+var fastRetry = config.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+func TestTxRunner_ConflictThenSuccess_Retried(t *testing.T) {
+	repo := &fakeTxRepository{commitErrs: []error{ErrTxConflict}}
+	runner := NewTxRunner(repo, fastRetry, testLogger)
+
+	calls := 0
+	err := runner.Run(t.Context(), func(context.Context) error {
+		calls++
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Len(t, repo.begun, 2)
+}
+
+func TestTxRunner_Isolation_KeptOnRetry(t *testing.T) {
+	repo := &fakeTxRepository{commitErrs: []error{ErrTxConflict}}
+	runner := NewTxRunner(repo, fastRetry, testLogger)
+
+	err := runner.Run(t.Context(), func(context.Context) error { return nil }, WithIsolation(config.IsolationSerializable))
+
+	require.NoError(t, err)
+	require.Len(t, repo.options, 2)
+	for _, o := range repo.options {
+		assert.Equal(t, config.IsolationSerializable, o.Isolation)
+	}
+}
+
+func TestTxRunner_DomainError_NotRetried(t *testing.T) {
+	repo := &fakeTxRepository{}
+	runner := NewTxRunner(repo, fastRetry, testLogger)
+
+	calls := 0
+	err := runner.Run(t.Context(), func(context.Context) error {
+		calls++
+		return domain.ErrInsufficientBalance
+	})
+
+	assert.ErrorIs(t, err, domain.ErrInsufficientBalance)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 1, repo.begun[0].rollbacks)
+	assert.Zero(t, repo.begun[0].commits)
+}
+
+func TestTxRunner_ConflictEveryTime_ReturnsWalletBusy(t *testing.T) {
+	repo := &fakeTxRepository{}
+	runner := NewTxRunner(repo, fastRetry, testLogger)
+
+	calls := 0
+	err := runner.Run(t.Context(), func(context.Context) error {
+		calls++
+		return ErrTxConflict
+	})
+
+	assert.ErrorIs(t, err, domain.ErrWalletBusy)
+	assert.ErrorIs(t, err, ErrTxConflict)
+	assert.Equal(t, fastRetry.MaxAttempts, calls)
+}
+
+func TestTxRunner_ConnectionResetBeforeCommit_ReturnsUnavailable(t *testing.T) {
+	repo := &fakeTxRepository{}
+	runner := NewTxRunner(repo, fastRetry, testLogger)
+
+	calls := 0
+	err := runner.Run(t.Context(), func(context.Context) error {
+		calls++
+		return syscall.ECONNRESET
+	})
+
+	assert.ErrorIs(t, err, domain.ErrUnavailable)
+	assert.Equal(t, fastRetry.MaxAttempts, calls)
+}
+
+func TestTxRunner_ConnectionLostOnCommit_NotRetried(t *testing.T) {
+	// Коммит мог примениться до обрыва соединения, повтор привёл бы к двойному списанию
+	repo := &fakeTxRepository{commitErrs: []error{io.ErrUnexpectedEOF}}
+	runner := NewTxRunner(repo, fastRetry, testLogger)
+
+	err := runner.Run(t.Context(), func(context.Context) error { return nil })
+
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Len(t, repo.begun, 1)
+}
+
+func TestTxRunner_BudgetSpent_StopsEarly(t *testing.T) {
+	repo := &fakeTxRepository{}
+	policy := config.RetryConfig{MaxAttempts: 100, BaseDelay: 20 * time.Millisecond, MaxDelay: 20 * time.Millisecond, Budget: 50 * time.Millisecond}
+	runner := NewTxRunner(repo, policy, testLogger)
+
+	calls := 0
+	err := runner.Run(t.Context(), func(context.Context) error {
+		calls++
+		return ErrTxConflict
+	})
+
+	assert.ErrorIs(t, err, domain.ErrWalletBusy)
+	assert.Less(t, calls, 6)
+}
+
+func TestTxRunner_ContextCancelled_StopsRetrying(t *testing.T) {
+	repo := &fakeTxRepository{}
+	runner := NewTxRunner(repo, fastRetry, testLogger)
+	ctx, cancel := context.WithCancel(t.Context())
+
+	calls := 0
+	err := runner.Run(ctx, func(context.Context) error {
+		calls++
+		cancel()
+		return ErrTxConflict
+	})
+
+	assert.ErrorIs(t, err, ErrTxConflict)
+	assert.Equal(t, 1, calls)
+}
+
+func TestTxRunner_Backoff_WithinBounds(t *testing.T) {
+	runner := NewTxRunner(&fakeTxRepository{}, config.RetryConfig{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}, testLogger)
+
+	for range 100 {
+		first := runner.backoff(1)
+		assert.GreaterOrEqual(t, first, 5*time.Millisecond)
+		assert.LessOrEqual(t, first, 10*time.Millisecond)
+
+		capped := runner.backoff(10)
+		assert.GreaterOrEqual(t, capped, 25*time.Millisecond)
+		assert.LessOrEqual(t, capped, 50*time.Millisecond)
+	}
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wallet-service/internal/db"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Schedules stores scheduled wallet operations and hands due ones out to
+// workers. Several workers may claim at once: each due schedule goes to one
+// of them, and a schedule whose worker did not finish before its lease
+// expired is handed out again.
+type Schedules interface {
+	Create(ctx context.Context, schedule *domain.Schedule) (*domain.Schedule, error)
+	// Get returns domain.ErrScheduleNotFound for an unknown id.
+	Get(ctx context.Context, id uuid.UUID) (*domain.Schedule, error)
+	// List returns up to limit schedules of the wallet, newest first. An
+	// empty status matches every status.
+	List(ctx context.Context, walletID uuid.UUID, status string, limit int) ([]domain.Schedule, error)
+	// Cancel stops a pending or retrying schedule, or returns
+	// domain.ErrScheduleNotCancellable.
+	Cancel(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Schedule, error)
+	// Claim marks up to limit due schedules as running for lease and returns
+	// them.
+	Claim(ctx context.Context, lease time.Duration, limit int) ([]domain.ScheduleClaim, error)
+	// Finish stores the outcome of a run and releases the claim identified
+	// by schedule.ClaimedAt, or returns domain.ErrScheduleLeaseLost when the
+	// schedule has been claimed again since.
+	Finish(ctx context.Context, schedule *domain.Schedule) error
+}
+
+// ScheduleRepository keeps schedules in app.schedules. Due schedules are
+// claimed with FOR UPDATE SKIP LOCKED, so concurrent workers never wait for
+// each other or get the same schedule.
+type ScheduleRepository struct {
+	TxRepositoryImpl
+}
+
+func NewScheduleRepository(pool *pgxpool.Pool, queries *db.Queries) *ScheduleRepository {
+	return &ScheduleRepository{
+		TxRepositoryImpl: TxRepositoryImpl{
+			db: pool,
+			q:  queries,
+		},
+	}
+}
+
+func (r *ScheduleRepository) Create(ctx context.Context, schedule *domain.Schedule) (_ *domain.Schedule, err error) {
+	ctx, span := tracer.Start(ctx, "ScheduleRepository.Create")
+	span.SetAttributes(attribute.String("wallet.id", schedule.WalletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).CreateSchedule(ctx, db.CreateScheduleParams{
+		ID:          UUIDToPgUUID(schedule.ID),
+		WalletID:    UUIDToPgUUID(schedule.WalletID),
+		Operation:   schedule.Operation,
+		Amount:      schedule.Amount,
+		Cron:        schedule.Cron,
+		Status:      schedule.Status,
+		NextRunAt:   nullableTime(schedule.NextRunAt),
+		MaxAttempts: int32(schedule.MaxAttempts),
+		CreatedBy:   schedule.CreatedBy,
+		CreatedAt:   nullableTime(schedule.CreatedAt),
+		UpdatedAt:   nullableTime(schedule.UpdatedAt),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return nil, domain.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("create schedule: %w", mapPgError(err))
+	}
+	return pgScheduleToDomain(&row)
+}
+
+func (r *ScheduleRepository) Get(ctx context.Context, id uuid.UUID) (_ *domain.Schedule, err error) {
+	ctx, span := tracer.Start(ctx, "ScheduleRepository.Get")
+	span.SetAttributes(attribute.String("schedule.id", id.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).GetSchedule(ctx, UUIDToPgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("get schedule %s: %w", id, mapPgError(err))
+	}
+	return pgScheduleToDomain(&row)
+}
+
+func (r *ScheduleRepository) List(ctx context.Context, walletID uuid.UUID, status string, limit int) (_ []domain.Schedule, err error) {
+	ctx, span := tracer.Start(ctx, "ScheduleRepository.List")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.getQueries(ctx).ListSchedules(ctx, db.ListSchedulesParams{
+		WalletID: UUIDToPgUUID(walletID),
+		Status:   nullableText(status),
+		RowLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list schedules of wallet %s: %w", walletID, mapPgError(err))
+	}
+
+	schedules := make([]domain.Schedule, 0, len(rows))
+	for i := range rows {
+		schedule, err := pgScheduleToDomain(&rows[i])
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, nil
+}
+
+func (r *ScheduleRepository) Cancel(ctx context.Context, id uuid.UUID, at time.Time) (_ *domain.Schedule, err error) {
+	ctx, span := tracer.Start(ctx, "ScheduleRepository.Cancel")
+	span.SetAttributes(attribute.String("schedule.id", id.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row, err := r.getQueries(ctx).CancelSchedule(ctx, db.CancelScheduleParams{
+		ID:        UUIDToPgUUID(id),
+		UpdatedAt: nullableTime(at),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Tell a missing schedule from one that cannot be cancelled.
+		if _, err := r.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrScheduleNotCancellable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cancel schedule %s: %w", id, mapPgError(err))
+	}
+	return pgScheduleToDomain(&row)
+}
+
+func (r *ScheduleRepository) Claim(ctx context.Context, lease time.Duration, limit int) (_ []domain.ScheduleClaim, err error) {
+	ctx, span := tracer.Start(ctx, "ScheduleRepository.Claim")
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.getQueries(ctx).ClaimSchedules(ctx, db.ClaimSchedulesParams{
+		LeaseSeconds: lease.Seconds(),
+		RowLimit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("claim schedules: %w", mapPgError(err))
+	}
+
+	claims := make([]domain.ScheduleClaim, 0, len(rows))
+	for _, row := range rows {
+		schedule, err := pgScheduleToDomain(&db.AppSchedule{
+			ID:          row.ID,
+			WalletID:    row.WalletID,
+			Operation:   row.Operation,
+			Amount:      row.Amount,
+			Cron:        row.Cron,
+			Status:      row.Status,
+			NextRunAt:   row.NextRunAt,
+			Attempts:    row.Attempts,
+			MaxAttempts: row.MaxAttempts,
+			Runs:        row.Runs,
+			Failures:    row.Failures,
+			LastRunAt:   row.LastRunAt,
+			LastError:   row.LastError,
+			CreatedBy:   row.CreatedBy,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+			ClaimedAt:   row.ClaimedAt,
+			LeaseUntil:  row.LeaseUntil,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		claim := domain.ScheduleClaim{Schedule: *schedule}
+		if row.PreviousStatus == domain.ScheduleRunning && row.PreviousClaimedAt.Valid {
+			interrupted := row.PreviousClaimedAt.Time.UTC()
+			claim.Interrupted = &interrupted
+		}
+		claims = append(claims, claim)
+	}
+	span.SetAttributes(attribute.Int("schedule.claimed", len(claims)))
+	return claims, nil
+}
+
+func (r *ScheduleRepository) Finish(ctx context.Context, schedule *domain.Schedule) (err error) {
+	ctx, span := tracer.Start(ctx, "ScheduleRepository.Finish")
+	span.SetAttributes(attribute.String("schedule.id", schedule.ID.String()), attribute.String("schedule.status", schedule.Status))
+	defer func() { tracing.End(span, err) }()
+
+	n, err := r.getQueries(ctx).FinishSchedule(ctx, db.FinishScheduleParams{
+		ID:        UUIDToPgUUID(schedule.ID),
+		ClaimedAt: pgtype.Timestamptz{Time: schedule.ClaimedAt, Valid: true},
+		Status:    schedule.Status,
+		NextRunAt: nullableTime(schedule.NextRunAt),
+		Attempts:  int32(schedule.Attempts),
+		Runs:      schedule.Runs,
+		Failures:  schedule.Failures,
+		LastRunAt: nullableTime(schedule.LastRunAt),
+		LastError: schedule.LastError,
+		UpdatedAt: nullableTime(schedule.UpdatedAt),
+	})
+	if err != nil {
+		return fmt.Errorf("finish schedule %s: %w", schedule.ID, mapPgError(err))
+	}
+	if n == 0 {
+		return domain.ErrScheduleLeaseLost
+	}
+	return nil
+}
+
+func pgScheduleToDomain(row *db.AppSchedule) (*domain.Schedule, error) {
+	id, err := PgUUIDToUUID(row.ID)
+	if err != nil {
+		return nil, err
+	}
+	walletID, err := PgUUIDToUUID(row.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Schedule{
+		ID:          id,
+		WalletID:    walletID,
+		Operation:   row.Operation,
+		Amount:      row.Amount,
+		Cron:        row.Cron,
+		Status:      row.Status,
+		NextRunAt:   row.NextRunAt.Time.UTC(),
+		Attempts:    int(row.Attempts),
+		MaxAttempts: int(row.MaxAttempts),
+		Runs:        row.Runs,
+		Failures:    row.Failures,
+		LastRunAt:   timeOrZero(row.LastRunAt),
+		LastError:   row.LastError,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt.Time.UTC(),
+		UpdatedAt:   row.UpdatedAt.Time.UTC(),
+		ClaimedAt:   timeOrZero(row.ClaimedAt),
+		LeaseUntil:  timeOrZero(row.LeaseUntil),
+	}, nil
+}
+
+func timeOrZero(t pgtype.Timestamptz) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time.UTC()
+}
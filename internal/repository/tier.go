@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wallet-service/internal/db"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Tiers stores the fee tier of each wallet.
+type Tiers interface {
+	// Tier returns the tier of the wallet, empty when none was set.
+	Tier(ctx context.Context, walletID uuid.UUID) (string, error)
+	// SetTier returns domain.ErrWalletNotFound for an unknown wallet.
+	SetTier(ctx context.Context, walletID uuid.UUID, tier string) error
+}
+
+// TierRepository keeps tiers in app.wallet_tiers.
+type TierRepository struct {
+	TxRepositoryImpl
+}
+
+func NewTierRepository(pool *pgxpool.Pool, queries *db.Queries) *TierRepository {
+	return &TierRepository{
+		TxRepositoryImpl: TxRepositoryImpl{
+			db: pool,
+			q:  queries,
+		},
+	}
+}
+
+func (r *TierRepository) Tier(ctx context.Context, walletID uuid.UUID) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "TierRepository.Tier")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	tier, err := r.getQueries(ctx).GetWalletTier(ctx, UUIDToPgUUID(walletID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("get tier of wallet %s: %w", walletID, mapPgError(err))
+	}
+	return tier, nil
+}
+
+func (r *TierRepository) SetTier(ctx context.Context, walletID uuid.UUID, tier string) (err error) {
+	ctx, span := tracer.Start(ctx, "TierRepository.SetTier")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	err = r.getQueries(ctx).SetWalletTier(ctx, db.SetWalletTierParams{
+		WalletID:  UUIDToPgUUID(walletID),
+		Tier:      tier,
+		UpdatedAt: nullableTime(time.Now().UTC()),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return domain.ErrWalletNotFound
+		}
+		return fmt.Errorf("set tier of wallet %s: %w", walletID, mapPgError(err))
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryName_SqlcHeader_ReturnsName(t *testing.T) {
+	assert.Equal(t, "GetForUpdate", queryName("-- name: GetForUpdate :one\nSELECT id, balance FROM app.wallets"))
+}
+
+func TestQueryName_PlainQuery_ReturnsDefault(t *testing.T) {
+	assert.Equal(t, "query", queryName("SELECT 1"))
+}
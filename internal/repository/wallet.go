@@ -3,63 +3,102 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"time"
 	"wallet-service/internal/db"
+	"wallet-service/internal/dbsource"
 	"wallet-service/internal/domain"
+	"wallet-service/internal/metrics"
+	"wallet-service/internal/tracing"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/ydb-platform/ydb-go-sdk/v3/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type WalletRepository struct {
 	TxRepositoryImpl
+	log *slog.Logger
+
+	replica *Replica
 }
 
-func (r *WalletRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Wallet, error) {
-	q := r.getQueries(ctx)
+type Option func(r *WalletRepository)
+
+// WithDefaultIsolation sets the level of transactions that do not ask for
+// one; without it they run at the server default, read committed.
+func WithDefaultIsolation(level string) Option {
+	return func(r *WalletRepository) {
+		r.isolation = level
+	}
+}
+
+// WithReplica serves reads made outside a transaction from replica unless
+// the caller asks for strong consistency or the replica lags too far behind.
+func WithReplica(replica *Replica) Option {
+	return func(r *WalletRepository) {
+		r.replica = replica
+	}
+}
+
+func (r *WalletRepository) Get(ctx context.Context, id uuid.UUID) (_ *domain.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "WalletRepository.Get")
+	span.SetAttributes(attribute.String("wallet.id", id.String()))
+	defer func() { tracing.End(span, err) }()
+
+	q, source := r.readQueries(ctx)
+	span.SetAttributes(attribute.String("db.source", source))
 
 	row, err := q.Get(ctx, UUIDToPgUUID(id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrWalletNotFound
 		}
-		log.Error(err)
-
-		return nil, err
+		return nil, fmt.Errorf("get wallet %s: %w", id, mapPgError(err))
 	}
 
 	wallet, err := pgWalletToDomain(&row)
 	if err != nil {
-		log.Error(err)
 		return nil, err
 	}
 
 	return wallet, nil
 }
 
-func (r *WalletRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.Wallet, error) {
+func (r *WalletRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (_ *domain.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "WalletRepository.GetForUpdate")
+	span.SetAttributes(attribute.String("wallet.id", id.String()))
+	defer func() { tracing.End(span, err) }()
+
 	q := r.getQueries(ctx)
 
+	start := time.Now()
 	row, err := q.GetForUpdate(ctx, UUIDToPgUUID(id))
+	wait := time.Since(start)
+	metrics.LockWaitDuration.Observe(wait.Seconds())
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrWalletNotFound
 		}
-		log.Error(err)
-		return nil, err
+		return nil, fmt.Errorf("lock wallet %s: %w", id, mapPgError(err))
 	}
+	r.log.DebugContext(ctx, "wallet row locked", slog.String("wallet_id", id.String()), slog.Duration("wait", wait))
 
 	wallet, err := pgWalletToDomain(&row)
 	if err != nil {
-		log.Error(err)
 		return nil, err
 	}
 
 	return wallet, nil
 }
 
-func (r *WalletRepository) Update(ctx context.Context, wallet *domain.Wallet) (*domain.Wallet, error) {
+func (r *WalletRepository) Update(ctx context.Context, wallet *domain.Wallet) (_ *domain.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "WalletRepository.Update")
+	span.SetAttributes(attribute.String("wallet.id", wallet.ID().String()))
+	defer func() { tracing.End(span, err) }()
+
 	q := r.getQueries(ctx)
 
 	row, err := q.Update(ctx, db.UpdateParams{
@@ -70,39 +109,83 @@ func (r *WalletRepository) Update(ctx context.Context, wallet *domain.Wallet) (*
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrWalletNotFound
 		}
-		log.Error(err)
+		return nil, fmt.Errorf("update wallet %s: %w", wallet.ID(), mapPgError(err))
+	}
+
+	domainWallet, err := pgWalletToDomain(&row)
+	if err != nil {
 		return nil, err
 	}
 
+	return domainWallet, nil
+}
+
+func (r *WalletRepository) Create(ctx context.Context, wallet *domain.Wallet) (_ *domain.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "WalletRepository.Create")
+	span.SetAttributes(attribute.String("wallet.id", wallet.ID().String()))
+	defer func() { tracing.End(span, err) }()
+
+	q := r.getQueries(ctx)
+
+	row, err := q.Create(ctx, db.CreateParams{
+		ID:      UUIDToPgUUID(wallet.ID()),
+		Balance: wallet.Balance(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWalletExists
+		}
+		return nil, fmt.Errorf("create wallet %s: %w", wallet.ID(), mapPgError(err))
+	}
+
 	domainWallet, err := pgWalletToDomain(&row)
 	if err != nil {
-		log.Error(err)
 		return nil, err
 	}
 
 	return domainWallet, nil
 }
 
-func NewWalletRepository(pool *pgxpool.Pool, queries *db.Queries) *WalletRepository {
-	return &WalletRepository{
-		TxRepositoryImpl{
+func NewWalletRepository(pool *pgxpool.Pool, queries *db.Queries, log *slog.Logger, opts ...Option) *WalletRepository {
+	r := &WalletRepository{
+		TxRepositoryImpl: TxRepositoryImpl{
 			db: pool,
 			q:  queries,
 		},
+		log: log,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// readQueries picks the source of a read and records it for the caller.
+// Reads inside a transaction always stay on the primary.
+func (r *WalletRepository) readQueries(ctx context.Context) (*db.Queries, string) {
+	if ctx.Value(txKey) != nil {
+		return r.getQueries(ctx), dbsource.Primary
+	}
+
+	q, source := r.q, dbsource.Primary
+	if r.replica != nil && r.replica.serves(ctx) {
+		q, source = r.replica.queries, dbsource.Replica
 	}
+	metrics.Reads.WithLabelValues(source).Inc()
+	dbsource.Record(ctx, source)
+
+	return q, source
 }
 
 func pgWalletToDomain(pgw *db.AppWallet) (*domain.Wallet, error) {
 	id, err := PgUUIDToUUID(pgw.ID)
 	if err != nil {
-		log.Error(err)
 		return nil, err
 	}
 
 	wallet, err := domain.NewWallet(id, pgw.Balance)
 	if err != nil {
-		log.Error(err)
-		return nil, err
+		return nil, fmt.Errorf("wallet %s: %w", id, err)
 	}
 
 	return wallet, nil
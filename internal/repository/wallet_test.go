@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"log/slog"
 	"testing"
 	"time"
 	"wallet-service/internal/domain"
@@ -13,9 +14,11 @@ import (
 
 var migrationsPath = []string{"../../migrations", "../../migrations/test"}
 
+var testLogger = slog.New(slog.DiscardHandler)
+
 func TestGet_ExistWallet_ReturnsWallet(t *testing.T) {
 	testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
-		repo, err := NewPostgresRepository(pool)
+		repo, err := NewPostgresRepository(pool, testLogger)
 		if err != nil {
 			t.Fatalf("error inititalization repository: %v", err)
 		}
@@ -34,7 +37,7 @@ func TestGet_ExistWallet_ReturnsWallet(t *testing.T) {
 
 func TestGet_NonExistentWallet_ReturnsNilNil(t *testing.T) {
 	testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
-		repo, err := NewPostgresRepository(pool)
+		repo, err := NewPostgresRepository(pool, testLogger)
 		id, err := uuid.Parse(testdb.WalletNonExistentID)
 		assert.NoError(t, err)
 
@@ -47,7 +50,7 @@ func TestGet_NonExistentWallet_ReturnsNilNil(t *testing.T) {
 
 func TestUpdate_CorrectModel_ReturnsUpdatedModel(t *testing.T) {
 	testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
-		repo, err := NewPostgresRepository(pool)
+		repo, err := NewPostgresRepository(pool, testLogger)
 		var value int64 = 100
 		id, err := uuid.Parse(testdb.WalletCorrectID)
 		assert.NoError(t, err)
@@ -65,7 +68,7 @@ func TestUpdate_CorrectModel_ReturnsUpdatedModel(t *testing.T) {
 
 func TestUpdate_NonExistentWallet_ReturnsUpdatedModel(t *testing.T) {
 	testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
-		repo, err := NewPostgresRepository(pool)
+		repo, err := NewPostgresRepository(pool, testLogger)
 		id, err := uuid.Parse(testdb.WalletNonExistentID)
 		assert.NoError(t, err)
 		model, err := domain.NewWallet(id, 0)
@@ -78,9 +81,40 @@ func TestUpdate_NonExistentWallet_ReturnsUpdatedModel(t *testing.T) {
 	})
 }
 
+func TestCreate_NewWallet_ReturnsCreatedModel(t *testing.T) {
+	testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
+		repo, err := NewPostgresRepository(pool, testLogger)
+		assert.NoError(t, err)
+		model, err := domain.NewWallet(uuid.New(), 500)
+		assert.NoError(t, err)
+
+		created, err := repo.Create(t.Context(), model)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.ID(), created.ID())
+		assert.Equal(t, int64(500), created.Balance())
+	})
+}
+
+func TestCreate_ExistWallet_ReturnsErrWalletExists(t *testing.T) {
+	testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
+		repo, err := NewPostgresRepository(pool, testLogger)
+		assert.NoError(t, err)
+		id, err := uuid.Parse(testdb.WalletCorrectID)
+		assert.NoError(t, err)
+		model, err := domain.NewWallet(id, 1)
+		assert.NoError(t, err)
+
+		created, err := repo.Create(t.Context(), model)
+
+		assert.ErrorIs(t, err, domain.ErrWalletExists)
+		assert.Nil(t, created)
+	})
+}
+
 func TestGetForUpdate_CorrectWallet_LocksRow(t *testing.T) {
 	testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
-		repo, err := NewPostgresRepository(pool)
+		repo, err := NewPostgresRepository(pool, testLogger)
 		id, _ := uuid.Parse(testdb.WalletCorrectID)
 
 		// Захват блокировки
@@ -126,7 +160,7 @@ func TestGetForUpdate_CorrectWallet_LocksRow(t *testing.T) {
 
 func TestGetForUpdate_ConcurrentDeposits_CorrectBalance(t *testing.T) {
 	testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
-		repo, err := NewPostgresRepository(pool)
+		repo, err := NewPostgresRepository(pool, testLogger)
 		var deposit1, deposit2 int64 = 50, 30
 
 		id, _ := uuid.Parse(testdb.WalletEmptyWalletID)
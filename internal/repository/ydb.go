@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/domain"
+
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+)
+
+const (
+	ydbWalletsTable      = "wallets"
+	ydbAuditTable        = "audit_log"
+	ydbTransactionsTable = "wallet_transactions"
+	ydbSnapshotsTable    = "wallet_balance_snapshots"
+)
+
+// YDB is a database/sql handle running over the YDB query service. Every
+// read-write transaction is serializable.
+type YDB struct {
+	*sql.DB
+	driver *ydb.Driver
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// OpenYDB connects to cfg.YDBDSN. Extra opts are passed to the native
+// driver, e.g. a single-connection balancer for a local container.
+func OpenYDB(ctx context.Context, cfg config.DatabaseConfig, opts ...ydb.Option) (*YDB, error) {
+	opts = append([]ydb.Option{
+		ydb.WithApplicationName(cfg.ApplicationName),
+		ydb.WithDialTimeout(cfg.ConnectTimeout),
+	}, opts...)
+
+	driver, err := ydb.Open(ctx, cfg.YDBDSN, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("open ydb driver: %w", err)
+	}
+
+	connector, err := ydb.Connector(driver,
+		ydb.WithQueryService(true),
+		ydb.WithAutoDeclare(),
+	)
+	if err != nil {
+		_ = driver.Close(ctx)
+		return nil, fmt.Errorf("create ydb connector: %w", err)
+	}
+
+	db := sql.OpenDB(connector)
+	if cfg.Pool.MaxConns > 0 {
+		db.SetMaxOpenConns(cfg.Pool.MaxConns)
+	}
+	db.SetMaxIdleConns(max(cfg.Pool.MinConns, 2))
+	db.SetConnMaxLifetime(cfg.Pool.MaxConnLifetime)
+	// Idle sessions are kept alive by the driver itself.
+	db.SetConnMaxIdleTime(time.Second)
+
+	return &YDB{DB: db, driver: driver}, nil
+}
+
+// Close releases the handle and the driver; later calls are no-ops.
+func (y *YDB) Close(ctx context.Context) error {
+	y.closeOnce.Do(func() {
+		y.closeErr = errors.Join(y.DB.Close(), y.driver.Close(ctx))
+	})
+	return y.closeErr
+}
+
+// BootstrapYDBSchema creates the tables the repository needs. It is safe to
+// run repeatedly.
+func BootstrapYDBSchema(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+ydbWalletsTable+` (
+			id Utf8 NOT NULL,
+			balance Int64 NOT NULL,
+			PRIMARY KEY (id)
+		)`)
+	if err != nil {
+		return fmt.Errorf("create table %s: %w", ydbWalletsTable, err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+ydbAuditTable+` (
+			id Int64 NOT NULL,
+			at Timestamp NOT NULL,
+			actor Utf8 NOT NULL,
+			source_ip Utf8 NOT NULL,
+			request_id Utf8 NOT NULL,
+			action Utf8 NOT NULL,
+			wallet_id Utf8,
+			balance_before Int64,
+			balance_after Int64,
+			outcome Utf8 NOT NULL,
+			PRIMARY KEY (id)
+		)`)
+	if err != nil {
+		return fmt.Errorf("create table %s: %w", ydbAuditTable, err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+ydbTransactionsTable+` (
+			wallet_id Utf8 NOT NULL,
+			seq Int64 NOT NULL,
+			kind Utf8 NOT NULL,
+			amount Int64 NOT NULL,
+			balance_after Int64 NOT NULL,
+			at Timestamp NOT NULL,
+			prev_hash String NOT NULL,
+			hash String NOT NULL,
+			PRIMARY KEY (wallet_id, seq)
+		)`)
+	if err != nil {
+		return fmt.Errorf("create table %s: %w", ydbTransactionsTable, err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+ydbSnapshotsTable+` (
+			wallet_id Utf8 NOT NULL,
+			seq Int64 NOT NULL,
+			balance Int64 NOT NULL,
+			at Timestamp NOT NULL,
+			PRIMARY KEY (wallet_id, seq)
+		)`)
+	if err != nil {
+		return fmt.Errorf("create table %s: %w", ydbSnapshotsTable, err)
+	}
+	return nil
+}
+
+// YDBSchemaCheck fails until BootstrapYDBSchema has created the tables.
+func YDBSchemaCheck(db *sql.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var count uint64
+		err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+ydbWalletsTable+` WHERE id = ""`).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("table %s is not readable: %w", ydbWalletsTable, err)
+		}
+		return nil
+	}
+}
+
+func NewYDBRepository(db *sql.DB, log *slog.Logger) *Repository {
+	wallets := NewYDBWalletRepository(db, log)
+	return &Repository{
+		Wallet: wallets,
+		Audit:  NewYDBAuditRepository(wallets),
+		Ledger: NewYDBLedgerRepository(wallets),
+	}
+}
+
+// mapYDBError translates YDB failures into the errors the service reacts to
+// while keeping the original error in the chain.
+func mapYDBError(err error) error {
+	switch {
+	case ydb.IsOperationErrorTransactionLocksInvalidated(err):
+		return fmt.Errorf("%w: %w", ErrTxConflict, err)
+	case ydb.IsTimeoutError(err):
+		return fmt.Errorf("%w: %w", domain.ErrTimeout, err)
+	default:
+		return err
+	}
+}
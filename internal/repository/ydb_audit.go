@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// YDBAuditRepository keeps the audit log in YDB next to the wallets and
+// shares their transactions. YDB has no triggers: the service only ever
+// inserts, and UPDATE and DELETE have to be withheld through access rights.
+type YDBAuditRepository struct {
+	wallets *YDBWalletRepository
+
+	mu     sync.Mutex
+	lastID int64
+}
+
+func NewYDBAuditRepository(wallets *YDBWalletRepository) *YDBAuditRepository {
+	return &YDBAuditRepository{wallets: wallets}
+}
+
+func (r *YDBAuditRepository) Append(ctx context.Context, record *domain.AuditRecord) (err error) {
+	ctx, span := tracer.Start(ctx, "YDBAuditRepository.Append")
+	span.SetAttributes(attribute.String("audit.action", record.Action))
+	defer func() { tracing.End(span, err) }()
+
+	record.ID = r.nextID()
+	record.At = time.Now().UTC()
+
+	var walletID *string
+	if record.WalletID != nil {
+		id := record.WalletID.String()
+		walletID = &id
+	}
+
+	return r.wallets.write(ctx, func(q ydbQuerier) error {
+		// INSERT rather than UPSERT: a clashing id fails instead of
+		// overwriting a record.
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO `+ydbAuditTable+` (id, at, actor, source_ip, request_id, action, wallet_id, balance_before, balance_after, outcome)
+			VALUES ($id, $at, $actor, $source_ip, $request_id, $action, $wallet_id, $balance_before, $balance_after, $outcome)`,
+			sql.Named("id", record.ID),
+			sql.Named("at", record.At),
+			sql.Named("actor", record.Actor),
+			sql.Named("source_ip", record.SourceIP),
+			sql.Named("request_id", record.RequestID),
+			sql.Named("action", record.Action),
+			sql.Named("wallet_id", walletID),
+			sql.Named("balance_before", record.BalanceBefore),
+			sql.Named("balance_after", record.BalanceAfter),
+			sql.Named("outcome", record.Outcome),
+		)
+		if err != nil {
+			return fmt.Errorf("append audit record: %w", mapYDBError(err))
+		}
+		return nil
+	})
+}
+
+func (r *YDBAuditRepository) List(ctx context.Context, filter AuditFilter) (_ []domain.AuditRecord, err error) {
+	ctx, span := tracer.Start(ctx, "YDBAuditRepository.List")
+	defer func() { tracing.End(span, err) }()
+
+	var (
+		where []string
+		args  = []any{sql.Named("limit", uint64(filter.Limit))}
+	)
+	match := func(cond string, arg sql.NamedArg) {
+		where = append(where, cond)
+		args = append(args, arg)
+	}
+	if filter.WalletID != nil {
+		match("wallet_id = $wallet_id", sql.Named("wallet_id", filter.WalletID.String()))
+	}
+	if filter.Actor != "" {
+		match("actor = $actor", sql.Named("actor", filter.Actor))
+	}
+	if filter.Action != "" {
+		match("action = $action", sql.Named("action", filter.Action))
+	}
+	if filter.Outcome != "" {
+		match("outcome = $outcome", sql.Named("outcome", filter.Outcome))
+	}
+	if !filter.From.IsZero() {
+		match("at >= $from", sql.Named("from", filter.From.UTC()))
+	}
+	if !filter.To.IsZero() {
+		match("at < $to", sql.Named("to", filter.To.UTC()))
+	}
+	if filter.BeforeID > 0 {
+		match("id < $before_id", sql.Named("before_id", filter.BeforeID))
+	}
+
+	query := `SELECT id, at, actor, source_ip, request_id, action, wallet_id, balance_before, balance_after, outcome FROM ` + ydbAuditTable
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	query += ` ORDER BY id DESC LIMIT $limit`
+
+	rows, err := r.wallets.querier(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list audit records: %w", mapYDBError(err))
+	}
+	defer rows.Close()
+
+	var records []domain.AuditRecord
+	for rows.Next() {
+		var (
+			record                      domain.AuditRecord
+			walletID                    sql.NullString
+			balanceBefore, balanceAfter sql.NullInt64
+		)
+		err := rows.Scan(&record.ID, &record.At, &record.Actor, &record.SourceIP, &record.RequestID,
+			&record.Action, &walletID, &balanceBefore, &balanceAfter, &record.Outcome)
+		if err != nil {
+			return nil, fmt.Errorf("read audit record: %w", err)
+		}
+		if walletID.Valid {
+			id, err := uuid.Parse(walletID.String)
+			if err != nil {
+				return nil, fmt.Errorf("audit record %d: %w", record.ID, err)
+			}
+			record.WalletID = &id
+		}
+		if balanceBefore.Valid {
+			record.BalanceBefore = &balanceBefore.Int64
+		}
+		if balanceAfter.Valid {
+			record.BalanceAfter = &balanceAfter.Int64
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list audit records: %w", mapYDBError(err))
+	}
+	return records, nil
+}
+
+// nextID orders records by the time they were appended. It is strictly
+// increasing within the process; instances appending in the same
+// nanosecond collide and the INSERT fails.
+func (r *YDBAuditRepository) nextID() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID = max(r.lastID+1, time.Now().UnixNano())
+	return r.lastID
+}
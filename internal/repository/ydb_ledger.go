@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// YDBLedgerRepository keeps the transaction chains in YDB next to the wallets
+// and shares their transactions.
+type YDBLedgerRepository struct {
+	wallets *YDBWalletRepository
+}
+
+func NewYDBLedgerRepository(wallets *YDBWalletRepository) *YDBLedgerRepository {
+	return &YDBLedgerRepository{wallets: wallets}
+}
+
+const ydbTransactionColumns = `wallet_id, seq, kind, amount, balance_after, at, prev_hash, hash`
+
+func (r *YDBLedgerRepository) Append(ctx context.Context, tx *domain.Transaction) (err error) {
+	ctx, span := tracer.Start(ctx, "YDBLedgerRepository.Append")
+	span.SetAttributes(attribute.String("wallet.id", tx.WalletID.String()), attribute.Int64("ledger.seq", tx.Seq))
+	defer func() { tracing.End(span, err) }()
+
+	return r.wallets.write(ctx, func(q ydbQuerier) error {
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO `+ydbTransactionsTable+` (`+ydbTransactionColumns+`)
+			VALUES ($wallet_id, $seq, $kind, $amount, $balance_after, $at, $prev_hash, $hash)`,
+			sql.Named("wallet_id", tx.WalletID.String()),
+			sql.Named("seq", tx.Seq),
+			sql.Named("kind", tx.Kind),
+			sql.Named("amount", tx.Amount),
+			sql.Named("balance_after", tx.BalanceAfter),
+			sql.Named("at", tx.At),
+			sql.Named("prev_hash", tx.PrevHash[:]),
+			sql.Named("hash", tx.Hash[:]),
+		)
+		if err != nil {
+			return fmt.Errorf("append transaction %d of wallet %s: %w", tx.Seq, tx.WalletID, mapYDBError(err))
+		}
+		return nil
+	})
+}
+
+func (r *YDBLedgerRepository) Last(ctx context.Context, walletID uuid.UUID) (_ *domain.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "YDBLedgerRepository.Last")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row := r.wallets.querier(ctx).QueryRowContext(ctx,
+		`SELECT `+ydbTransactionColumns+` FROM `+ydbTransactionsTable+`
+		WHERE wallet_id = $wallet_id ORDER BY seq DESC LIMIT 1`,
+		sql.Named("wallet_id", walletID.String()),
+	)
+	tx, err := scanYDBTransaction(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get last transaction of wallet %s: %w", walletID, mapYDBError(err))
+	}
+	return tx, nil
+}
+
+func (r *YDBLedgerRepository) LastBefore(ctx context.Context, walletID uuid.UUID, at time.Time) (_ *domain.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "YDBLedgerRepository.LastBefore")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	row := r.wallets.querier(ctx).QueryRowContext(ctx,
+		`SELECT `+ydbTransactionColumns+` FROM `+ydbTransactionsTable+`
+		WHERE wallet_id = $wallet_id AND at < $at ORDER BY at DESC, seq DESC LIMIT 1`,
+		sql.Named("wallet_id", walletID.String()),
+		sql.Named("at", at.UTC()),
+	)
+	tx, err := scanYDBTransaction(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get transaction of wallet %s before %s: %w", walletID, at, mapYDBError(err))
+	}
+	return tx, nil
+}
+
+func (r *YDBLedgerRepository) List(ctx context.Context, walletID uuid.UUID, afterSeq int64, limit int) (_ []domain.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "YDBLedgerRepository.List")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.wallets.querier(ctx).QueryContext(ctx,
+		`SELECT `+ydbTransactionColumns+` FROM `+ydbTransactionsTable+`
+		WHERE wallet_id = $wallet_id AND seq > $after_seq ORDER BY seq LIMIT $limit`,
+		sql.Named("wallet_id", walletID.String()),
+		sql.Named("after_seq", afterSeq),
+		sql.Named("limit", uint64(limit)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list transactions of wallet %s: %w", walletID, mapYDBError(err))
+	}
+	defer rows.Close()
+
+	var txs []domain.Transaction
+	for rows.Next() {
+		tx, err := scanYDBTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("read transaction of wallet %s: %w", walletID, err)
+		}
+		txs = append(txs, *tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list transactions of wallet %s: %w", walletID, mapYDBError(err))
+	}
+	return txs, nil
+}
+
+func (r *YDBLedgerRepository) WalletIDs(ctx context.Context, after uuid.UUID, limit int) (_ []uuid.UUID, err error) {
+	ctx, span := tracer.Start(ctx, "YDBLedgerRepository.WalletIDs")
+	defer func() { tracing.End(span, err) }()
+
+	// Lowercase hex ids sort like the bytes they encode.
+	rows, err := r.wallets.querier(ctx).QueryContext(ctx,
+		`SELECT id FROM `+ydbWalletsTable+` WHERE id > $after ORDER BY id LIMIT $limit`,
+		sql.Named("after", after.String()),
+		sql.Named("limit", uint64(limit)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list wallets: %w", mapYDBError(err))
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, fmt.Errorf("read wallet id: %w", err)
+		}
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("wallet %q: %w", s, err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list wallets: %w", mapYDBError(err))
+	}
+	return ids, nil
+}
+
+func (r *YDBLedgerRepository) SaveSnapshot(ctx context.Context, snapshot *domain.BalanceSnapshot) (err error) {
+	ctx, span := tracer.Start(ctx, "YDBLedgerRepository.SaveSnapshot")
+	span.SetAttributes(attribute.String("wallet.id", snapshot.WalletID.String()), attribute.Int64("ledger.seq", snapshot.Seq))
+	defer func() { tracing.End(span, err) }()
+
+	// A snapshot of a seq never changes, so writing it again is harmless.
+	return r.wallets.write(ctx, func(q ydbQuerier) error {
+		_, err := q.ExecContext(ctx, `
+			UPSERT INTO `+ydbSnapshotsTable+` (wallet_id, seq, balance, at)
+			VALUES ($wallet_id, $seq, $balance, $at)`,
+			sql.Named("wallet_id", snapshot.WalletID.String()),
+			sql.Named("seq", snapshot.Seq),
+			sql.Named("balance", snapshot.Balance),
+			sql.Named("at", snapshot.At),
+		)
+		if err != nil {
+			return fmt.Errorf("save snapshot %d of wallet %s: %w", snapshot.Seq, snapshot.WalletID, mapYDBError(err))
+		}
+		return nil
+	})
+}
+
+func (r *YDBLedgerRepository) SnapshotBefore(ctx context.Context, walletID uuid.UUID, at time.Time) (_ *domain.BalanceSnapshot, err error) {
+	ctx, span := tracer.Start(ctx, "YDBLedgerRepository.SnapshotBefore")
+	span.SetAttributes(attribute.String("wallet.id", walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	snapshot := &domain.BalanceSnapshot{WalletID: walletID}
+	err = r.wallets.querier(ctx).QueryRowContext(ctx,
+		`SELECT seq, balance, at FROM `+ydbSnapshotsTable+`
+		WHERE wallet_id = $wallet_id AND at < $at ORDER BY at DESC, seq DESC LIMIT 1`,
+		sql.Named("wallet_id", walletID.String()),
+		sql.Named("at", at.UTC()),
+	).Scan(&snapshot.Seq, &snapshot.Balance, &snapshot.At)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get snapshot of wallet %s before %s: %w", walletID, at, mapYDBError(err))
+	}
+	snapshot.At = snapshot.At.UTC()
+	return snapshot, nil
+}
+
+func scanYDBTransaction(row interface{ Scan(dest ...any) error }) (*domain.Transaction, error) {
+	var (
+		tx             domain.Transaction
+		walletID       string
+		prevHash, hash []byte
+	)
+	err := row.Scan(&walletID, &tx.Seq, &tx.Kind, &tx.Amount, &tx.BalanceAfter, &tx.At, &prevHash, &hash)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.WalletID, err = uuid.Parse(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("wallet %q: %w", walletID, err)
+	}
+	tx.At = tx.At.UTC()
+	copy(tx.PrevHash[:], prevHash)
+	copy(tx.Hash[:], hash)
+	return &tx, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// YDBWalletRepository stores wallets in YDB. Transactions are serializable
+// and optimistic: GetForUpdate does not block, instead the transaction that
+// loses a race fails with ErrTxConflict.
+type YDBWalletRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewYDBWalletRepository(db *sql.DB, log *slog.Logger) *YDBWalletRepository {
+	return &YDBWalletRepository{db: db, log: log}
+}
+
+type ydbQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type ydbTxKeyType struct{}
+
+var ydbTxKey = ydbTxKeyType{}
+
+// WithTx begins a serializable transaction whatever isolation opts ask for:
+// it is the only level YDB offers for read-write transactions.
+func (r *YDBWalletRepository) WithTx(ctx context.Context, _ ...TxOption) (context.Context, WalletTx, error) {
+	spanCtx, span := tracer.Start(ctx, "YDBWalletRepository.WithTx")
+	tx, err := r.db.BeginTx(spanCtx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	tracing.End(span, err)
+	if err != nil {
+		return nil, nil, mapYDBError(err)
+	}
+
+	return context.WithValue(ctx, ydbTxKey, tx), &observedTx{WalletTx: ydbTx{tx}, start: time.Now()}, nil
+}
+
+func (r *YDBWalletRepository) querier(ctx context.Context) ydbQuerier {
+	if tx, ok := ctx.Value(ydbTxKey).(*sql.Tx); ok {
+		return tx
+	}
+	return r.db
+}
+
+func (r *YDBWalletRepository) Get(ctx context.Context, id uuid.UUID) (_ *domain.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "YDBWalletRepository.Get")
+	span.SetAttributes(attribute.String("wallet.id", id.String()))
+	defer func() { tracing.End(span, err) }()
+
+	balance, err := r.balance(ctx, r.querier(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewWallet(id, balance)
+}
+
+// GetForUpdate reads the wallet inside the transaction. Serializable
+// isolation makes a concurrent change to it abort one of the transactions.
+func (r *YDBWalletRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (_ *domain.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "YDBWalletRepository.GetForUpdate")
+	span.SetAttributes(attribute.String("wallet.id", id.String()))
+	defer func() { tracing.End(span, err) }()
+
+	balance, err := r.balance(ctx, r.querier(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewWallet(id, balance)
+}
+
+func (r *YDBWalletRepository) Update(ctx context.Context, wallet *domain.Wallet) (_ *domain.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "YDBWalletRepository.Update")
+	span.SetAttributes(attribute.String("wallet.id", wallet.ID().String()))
+	defer func() { tracing.End(span, err) }()
+
+	err = r.write(ctx, func(q ydbQuerier) error {
+		if _, err := r.balance(ctx, q, wallet.ID()); err != nil {
+			return err
+		}
+		return r.upsert(ctx, q, wallet)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewWallet(wallet.ID(), wallet.Balance())
+}
+
+func (r *YDBWalletRepository) Create(ctx context.Context, wallet *domain.Wallet) (_ *domain.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "YDBWalletRepository.Create")
+	span.SetAttributes(attribute.String("wallet.id", wallet.ID().String()))
+	defer func() { tracing.End(span, err) }()
+
+	err = r.write(ctx, func(q ydbQuerier) error {
+		_, err := r.balance(ctx, q, wallet.ID())
+		switch {
+		case err == nil:
+			return domain.ErrWalletExists
+		case !errors.Is(err, domain.ErrWalletNotFound):
+			return err
+		}
+		return r.upsert(ctx, q, wallet)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewWallet(wallet.ID(), wallet.Balance())
+}
+
+// write runs fn in the caller's transaction or, outside one, in its own
+// transaction committed straight away.
+func (r *YDBWalletRepository) write(ctx context.Context, fn func(q ydbQuerier) error) error {
+	if tx, ok := ctx.Value(ydbTxKey).(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	txCtx, tx, err := r.WithTx(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(r.querier(txCtx)); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *YDBWalletRepository) balance(ctx context.Context, q ydbQuerier, id uuid.UUID) (int64, error) {
+	var balance int64
+	err := q.QueryRowContext(ctx,
+		`SELECT balance FROM `+ydbWalletsTable+` WHERE id = $id`,
+		sql.Named("id", id.String()),
+	).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, domain.ErrWalletNotFound
+		}
+		return 0, fmt.Errorf("get wallet %s: %w", id, mapYDBError(err))
+	}
+	return balance, nil
+}
+
+func (r *YDBWalletRepository) upsert(ctx context.Context, q ydbQuerier, wallet *domain.Wallet) error {
+	_, err := q.ExecContext(ctx,
+		`UPSERT INTO `+ydbWalletsTable+` (id, balance) VALUES ($id, $balance)`,
+		sql.Named("id", wallet.ID().String()),
+		sql.Named("balance", wallet.Balance()),
+	)
+	if err != nil {
+		return fmt.Errorf("write wallet %s: %w", wallet.ID(), mapYDBError(err))
+	}
+	return nil
+}
+
+// ydbTx adapts *sql.Tx to WalletTx and reports conflicts detected on commit.
+type ydbTx struct {
+	tx *sql.Tx
+}
+
+func (t ydbTx) Commit(_ context.Context) error {
+	return mapYDBTxError(t.tx.Commit())
+}
+
+func (t ydbTx) Rollback(_ context.Context) error {
+	return mapYDBTxError(t.tx.Rollback())
+}
+
+func mapYDBTxError(err error) error {
+	if errors.Is(err, sql.ErrTxDone) {
+		return ErrTxClosed
+	}
+	return mapYDBError(err)
+}
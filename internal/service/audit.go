@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/repository"
+)
+
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
+)
+
+type AuditService struct {
+	log repository.Audit
+}
+
+func NewAuditService(log repository.Audit) *AuditService {
+	return &AuditService{log: log}
+}
+
+// Record audits an action that does not touch a wallet, such as an admin
+// switching maintenance mode.
+func (s *AuditService) Record(ctx context.Context, action, outcome string) error {
+	return s.log.Append(ctx, newAuditRecord(ctx, action, outcome))
+}
+
+// List returns matching records newest first, DefaultAuditLimit of them
+// unless the filter asks for another number up to MaxAuditLimit.
+func (s *AuditService) List(ctx context.Context, filter repository.AuditFilter) ([]domain.AuditRecord, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultAuditLimit
+	}
+	filter.Limit = min(filter.Limit, MaxAuditLimit)
+	return s.log.List(ctx, filter)
+}
+
+func newAuditRecord(ctx context.Context, action, outcome string) *domain.AuditRecord {
+	actor := audit.ActorFrom(ctx)
+	return &domain.AuditRecord{
+		Actor:     actor.ID,
+		SourceIP:  actor.SourceIP,
+		RequestID: logger.RequestID(ctx),
+		Action:    action,
+		Outcome:   outcome,
+	}
+}
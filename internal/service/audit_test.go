@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errAuditDown = errors.New("audit log is down")
+
+type failingAudit struct {
+	repository.Audit
+}
+
+func (failingAudit) Append(context.Context, *domain.AuditRecord) error {
+	return errAuditDown
+}
+
+func auditedContext(t *testing.T, actor string) context.Context {
+	ctx := logger.WithRequestID(t.Context(), "req-"+actor)
+	return audit.WithActor(ctx, audit.Actor{ID: actor, SourceIP: "203.0.113.7"})
+}
+
+func TestWithdraw_Audited_RecordsBalances(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := srv.Withdraw(auditedContext(t, "alice"), id, 30)
+		require.NoError(t, err)
+
+		records, err := srv.Audit.List(t.Context(), repository.AuditFilter{Actor: "alice"})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+
+		record := records[0]
+		assert.Equal(t, domain.AuditWithdraw, record.Action)
+		assert.Equal(t, "success", record.Outcome)
+		assert.Equal(t, "203.0.113.7", record.SourceIP)
+		assert.Equal(t, "req-alice", record.RequestID)
+		require.NotNil(t, record.WalletID)
+		assert.Equal(t, id, *record.WalletID)
+		require.NotNil(t, record.BalanceBefore)
+		require.NotNil(t, record.BalanceAfter)
+		assert.Equal(t, int64(100), *record.BalanceBefore)
+		assert.Equal(t, int64(70), *record.BalanceAfter)
+	})
+}
+
+func TestWithdraw_InsufficientBalance_FailureAudited(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := srv.Withdraw(auditedContext(t, "bob"), id, 1000)
+		require.ErrorIs(t, err, domain.ErrInsufficientBalance)
+
+		records, err := srv.Audit.List(t.Context(), repository.AuditFilter{Actor: "bob"})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "insufficient_balance", records[0].Outcome)
+		require.NotNil(t, records[0].BalanceBefore)
+		assert.Equal(t, int64(100), *records[0].BalanceBefore)
+		assert.Nil(t, records[0].BalanceAfter)
+	})
+}
+
+func TestDeposit_AuditAppendFails_NotApplied(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewWalletService(repo.Wallet, testLogger, WithAudit(failingAudit{}))
+		id := uuid.MustParse(testdb.WalletEmptyWalletID)
+
+		_, err := srv.Deposit(t.Context(), id, 50)
+		require.ErrorIs(t, err, errAuditDown)
+
+		// Изменение без записи в журнале не должно закоммититься
+		wallet, err := repo.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Zero(t, wallet.Balance())
+	})
+}
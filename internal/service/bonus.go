@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/maintenance"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// bonusPageSize is how many wallets are expired at a time.
+const bonusPageSize = 100
+
+// BonusService grants bonus credit and removes it once it expires.
+type BonusService struct {
+	bonuses repository.Bonuses
+	wallet  *WalletService
+	log     *slog.Logger
+}
+
+// NewBonusService grants and expires bonus through wallet, which must keep
+// its buckets in bonuses.
+func NewBonusService(bonuses repository.Bonuses, wallet *WalletService, log *slog.Logger) *BonusService {
+	return &BonusService{bonuses: bonuses, wallet: wallet, log: log}
+}
+
+// Grant deposits amount to the wallet as bonus that expires at expiresAt.
+func (s *BonusService) Grant(ctx context.Context, walletID uuid.UUID, amount int64, expiresAt time.Time) (*domain.Receipt, error) {
+	return s.wallet.grantBonus(ctx, walletID, amount, expiresAt)
+}
+
+// Expire removes the unspent bonus that has expired from every wallet and
+// returns how many wallets lost some. A wallet that cannot be expired is
+// logged and left to the next run; its bonus is not spent meanwhile, as
+// every operation expires it first.
+func (s *BonusService) Expire(ctx context.Context) (expired int, err error) {
+	ctx = audit.WithActor(ctx, audit.Actor{ID: audit.Expiry})
+	ctx, span := tracer.Start(ctx, "BonusService.Expire")
+	defer func() {
+		span.SetAttributes(attribute.Int("bonus.wallets", expired))
+		tracing.End(span, err)
+	}()
+
+	now := time.Now()
+	var after uuid.UUID
+	for {
+		ids, err := s.bonuses.Expired(ctx, now, after, bonusPageSize)
+		if err != nil {
+			return expired, err
+		}
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return expired, err
+			}
+			amount, err := s.wallet.expireBonuses(ctx, id)
+			if err != nil {
+				s.log.ErrorContext(ctx, "bonus not expired", slog.String("wallet_id", id.String()), logger.Err(err))
+				continue
+			}
+			if amount > 0 {
+				expired++
+			}
+		}
+		if len(ids) < bonusPageSize {
+			return expired, nil
+		}
+		after = ids[len(ids)-1]
+	}
+}
+
+// RunBonusExpiryWorker expires bonus every interval until ctx is done.
+// Nothing runs while mode is in maintenance.
+func RunBonusExpiryWorker(ctx context.Context, bonus Bonus, interval time.Duration, mode *maintenance.Mode, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if mode.Enabled() {
+			continue
+		}
+		if _, err := bonus.Expire(ctx); err != nil && ctx.Err() == nil {
+			log.ErrorContext(ctx, "bonus expiry failed", logger.Err(err))
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"wallet-service/internal/dbsource"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	mock_repository "wallet-service/internal/repository/mocks"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// primaryBonuses holds buckets the primary has and a stale replica may not.
+type primaryBonuses []domain.Bonus
+
+func (b primaryBonuses) Bonuses(context.Context, uuid.UUID) ([]domain.Bonus, error) {
+	return b, nil
+}
+
+func (primaryBonuses) SaveBonuses(context.Context, uuid.UUID, []domain.Bonus) error {
+	return nil
+}
+
+func (primaryBonuses) Expired(context.Context, time.Time, uuid.UUID, int) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func TestGet_StaleReplica_ReadsPrimary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	repo := mock_repository.NewMockWallet(ctrl)
+	repo.
+		EXPECT().
+		Get(gomock.Any(), id).
+		DoAndReturn(func(ctx context.Context, id uuid.UUID) (*domain.Wallet, error) {
+			// Реплика ещё не видит начисленный бонус
+			if dbsource.ConsistencyFrom(ctx) != dbsource.Strong {
+				return domain.NewWallet(id, 0)
+			}
+			return domain.NewWallet(id, 300)
+		})
+	bonuses := primaryBonuses{{ID: uuid.New(), Amount: 300, ExpiresAt: time.Now().Add(time.Hour)}}
+	srv := NewWalletService(repo, testLogger, WithBonuses(bonuses))
+
+	wallet, err := srv.Get(t.Context(), id)
+	require.NoError(t, err)
+	defer wallet.Release()
+	assert.Equal(t, int64(300), wallet.Balance())
+	assert.Equal(t, int64(300), wallet.Bonus())
+	assert.Zero(t, wallet.Cash())
+}
+
+// expireNow moves the expiry of every bucket of the wallet into the past.
+func expireNow(t *testing.T, repo *repository.Repository, id uuid.UUID) {
+	t.Helper()
+	bonuses, err := repo.Bonuses.Bonuses(t.Context(), id)
+	require.NoError(t, err)
+	for i := range bonuses {
+		bonuses[i].ExpiresAt = time.Now().Add(-time.Minute).UTC().Truncate(time.Microsecond)
+	}
+	require.NoError(t, repo.Bonuses.SaveBonuses(t.Context(), id, bonuses))
+}
+
+func TestBonus_Withdraw_SpendsBonusFirst(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, bonus := newTestService[*BonusService](t, repo)
+		id := uuid.MustParse(testdb.Wallet10000AmountID)
+
+		receipt, err := bonus.Grant(t.Context(), id, 500, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(500), receipt.Wallet.Bonus())
+		receipt.Wallet.Release()
+
+		receipt, err = srv.Withdraw(t.Context(), id, 700)
+		require.NoError(t, err)
+		receipt.Wallet.Release()
+
+		wallet, err := srv.Get(t.Context(), id)
+		require.NoError(t, err)
+		defer wallet.Release()
+		assert.Equal(t, int64(9800), wallet.Balance())
+		assert.Zero(t, wallet.Bonus())
+		assert.Equal(t, int64(9800), wallet.Cash())
+	})
+}
+
+func TestBonus_Withdraw_CashFirst(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, bonus := newTestService[*BonusService](t, repo, WithSpendingOrder(domain.SpendCashFirst))
+		id := uuid.MustParse(testdb.Wallet10000AmountID)
+
+		receipt, err := bonus.Grant(t.Context(), id, 500, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		receipt.Wallet.Release()
+
+		// Бонус тратится, только когда кончились деньги
+		receipt, err = srv.Withdraw(t.Context(), id, 10200)
+		require.NoError(t, err)
+		defer receipt.Wallet.Release()
+		assert.Equal(t, int64(300), receipt.Wallet.Balance())
+		assert.Equal(t, int64(300), receipt.Wallet.Bonus())
+		assert.Zero(t, receipt.Wallet.Cash())
+	})
+}
+
+func TestBonus_Grant_PastExpiry_Rejected(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, bonus := newTestService[*BonusService](t, repo)
+		id := uuid.MustParse(testdb.Wallet10000AmountID)
+
+		_, err := bonus.Grant(t.Context(), id, 500, time.Now().Add(-time.Hour))
+		assert.ErrorIs(t, err, domain.ErrBonusExpiry)
+		assert.Equal(t, int64(10000), balanceOf(t, srv, id))
+	})
+}
+
+func TestBonus_Expire_RemovesUnspentOnce(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, bonus := newTestService[*BonusService](t, repo)
+		id := uuid.MustParse(testdb.Wallet10000AmountID)
+
+		receipt, err := bonus.Grant(t.Context(), id, 500, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		receipt.Wallet.Release()
+		receipt, err = srv.Withdraw(t.Context(), id, 200)
+		require.NoError(t, err)
+		receipt.Wallet.Release()
+		expireNow(t, repo, id)
+
+		expired, err := bonus.Expire(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+		assert.Equal(t, int64(10000), balanceOf(t, srv, id))
+
+		last, err := repo.Ledger.Last(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionBonusExpired, last.Kind)
+		assert.Equal(t, int64(300), last.Amount)
+		assert.Equal(t, int64(10000), last.BalanceAfter)
+
+		// Повторный запуск ничего не списывает
+		expired, err = bonus.Expire(t.Context())
+		require.NoError(t, err)
+		assert.Zero(t, expired)
+		assert.Equal(t, int64(10000), balanceOf(t, srv, id))
+	})
+}
+
+func TestBonus_Withdraw_ExpiredNotSpent(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, bonus := newTestService[*BonusService](t, repo)
+		id := uuid.MustParse(testdb.Wallet10000AmountID)
+
+		receipt, err := bonus.Grant(t.Context(), id, 500, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		receipt.Wallet.Release()
+		expireNow(t, repo, id)
+
+		// Просроченный бонус списывается до операции, задача expiry не нужна
+		_, err = srv.Withdraw(t.Context(), id, 10100)
+		assert.ErrorIs(t, err, domain.ErrInsufficientBalance)
+
+		receipt, err = srv.Withdraw(t.Context(), id, 100)
+		require.NoError(t, err)
+		defer receipt.Wallet.Release()
+		assert.Equal(t, int64(9900), receipt.Wallet.Balance())
+		assert.Zero(t, receipt.Wallet.Bonus())
+	})
+}
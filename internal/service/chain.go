@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/metrics"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+)
+
+// chainPageSize is how many wallets or transactions are read at a time while
+// walking the chains.
+const chainPageSize = 500
+
+type ChainService struct {
+	wallets repository.Wallet
+	ledger  repository.Ledger
+	tx      *repository.TxRunner
+	log     *slog.Logger
+}
+
+func NewChainService(wallets repository.Wallet, ledger repository.Ledger, log *slog.Logger) *ChainService {
+	return &ChainService{
+		wallets: wallets,
+		ledger:  ledger,
+		tx:      repository.NewTxRunner(wallets, repository.DefaultRetry, log),
+		log:     log,
+	}
+}
+
+// Verify walks the transaction chain of the wallet, or of every wallet when
+// walletID is nil, and stops at the first broken link. A missing wallet is
+// domain.ErrWalletNotFound; a broken chain is reported, not returned as an
+// error.
+func (s *ChainService) Verify(ctx context.Context, walletID *uuid.UUID) (_ *domain.ChainReport, err error) {
+	ctx, span := tracer.Start(ctx, "ChainService.Verify")
+	defer func() { tracing.End(span, err) }()
+
+	report := &domain.ChainReport{}
+	if walletID != nil {
+		if err := s.verifyWallet(ctx, *walletID, report); err != nil {
+			return nil, err
+		}
+	} else {
+		err := eachWallet(ctx, s.ledger, func(id uuid.UUID) (bool, error) {
+			if err := s.verifyWallet(ctx, id, report); err != nil {
+				return false, err
+			}
+			return report.Break == nil, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := "intact"
+	if report.Break != nil {
+		result = "broken"
+		s.log.WarnContext(ctx, "transaction chain is broken",
+			slog.String("wallet_id", report.Break.WalletID.String()),
+			slog.Int64("seq", report.Break.Seq),
+			slog.String("reason", report.Break.Reason),
+		)
+	}
+	metrics.ChainVerifications.WithLabelValues(result).Inc()
+
+	return report, nil
+}
+
+// verifyWallet checks every link of the chain up to the head and that the
+// head balance is the balance of the wallet. The head and the balance are
+// read from one snapshot, so changes made during the walk are not mistaken
+// for tampering.
+func (s *ChainService) verifyWallet(ctx context.Context, id uuid.UUID, report *domain.ChainReport) error {
+	var (
+		head    *domain.Transaction
+		balance int64
+	)
+	err := s.tx.Run(ctx, func(c context.Context) error {
+		wallet, err := s.wallets.Get(c, id)
+		if err != nil {
+			return err
+		}
+		balance = wallet.Balance()
+		wallet.Release()
+
+		head, err = s.ledger.Last(c, id)
+		return err
+	}, repository.WithIsolation(config.IsolationRepeatableRead))
+	if err != nil {
+		return fmt.Errorf("read head of wallet %s: %w", id, err)
+	}
+
+	report.Wallets++
+	if head == nil {
+		return nil
+	}
+
+	var prev *domain.Transaction
+	for prev == nil || prev.Seq < head.Seq {
+		var afterSeq int64
+		if prev != nil {
+			afterSeq = prev.Seq
+		}
+		page, err := s.ledger.List(ctx, id, afterSeq, min(chainPageSize, int(head.Seq-afterSeq)))
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			report.Break = &domain.ChainBreak{WalletID: id, Seq: afterSeq + 1, Reason: "transaction is missing"}
+			return nil
+		}
+
+		for i := range page {
+			if report.Break = page[i].Check(prev); report.Break != nil {
+				return nil
+			}
+			prev = &page[i]
+			report.Transactions++
+		}
+	}
+
+	if prev.BalanceAfter != balance {
+		report.Break = &domain.ChainBreak{WalletID: id, Seq: prev.Seq, Reason: "wallet balance differs from the last transaction"}
+	}
+	return nil
+}
+
+// Checkpoint collects the current head of every chain. Wallets without
+// transactions are left out.
+func (s *ChainService) Checkpoint(ctx context.Context) (_ *domain.Checkpoint, err error) {
+	ctx, span := tracer.Start(ctx, "ChainService.Checkpoint")
+	defer func() { tracing.End(span, err) }()
+
+	at := time.Now()
+	var heads []domain.ChainHead
+	err = eachWallet(ctx, s.ledger, func(id uuid.UUID) (bool, error) {
+		head, err := s.ledger.Last(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		if head != nil {
+			heads = append(heads, domain.ChainHead{WalletID: id, Seq: head.Seq, Hash: head.Hash})
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewCheckpoint(at, heads), nil
+}
+
+// eachWallet calls fn with every wallet id until fn returns false or an
+// error.
+func eachWallet(ctx context.Context, ledger repository.Ledger, fn func(id uuid.UUID) (bool, error)) error {
+	var after uuid.UUID
+	for {
+		ids, err := ledger.WalletIDs(ctx, after, chainPageSize)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			more, err := fn(id)
+			if err != nil || !more {
+				return err
+			}
+		}
+		if len(ids) < chainPageSize {
+			return nil
+		}
+		after = ids[len(ids)-1]
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeposit_Withdraw_ChainedIntoLedger(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := srv.Deposit(t.Context(), id, 50)
+		require.NoError(t, err)
+		_, err = srv.Withdraw(t.Context(), id, 30)
+		require.NoError(t, err)
+		// Неудачная операция в цепочку не попадает
+		_, err = srv.Withdraw(t.Context(), id, 1000)
+		require.ErrorIs(t, err, domain.ErrInsufficientBalance)
+
+		txs, err := repo.Ledger.List(t.Context(), id, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, txs, 2)
+		assert.Equal(t, domain.TransactionDeposit, txs[0].Kind)
+		assert.Equal(t, int64(150), txs[0].BalanceAfter)
+		assert.Equal(t, domain.TransactionWithdraw, txs[1].Kind)
+		assert.Equal(t, int64(30), txs[1].Amount)
+		assert.Equal(t, int64(120), txs[1].BalanceAfter)
+		assert.Equal(t, txs[0].Hash, txs[1].PrevHash)
+
+		report, err := srv.Chain.Verify(t.Context(), nil)
+		require.NoError(t, err)
+		assert.Nil(t, report.Break)
+		assert.Equal(t, int64(2), report.Transactions)
+		assert.GreaterOrEqual(t, report.Wallets, 1)
+	})
+}
+
+func TestVerify_ForgedTransaction_ReportsFirstBrokenLink(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := srv.Deposit(t.Context(), id, 10)
+		require.NoError(t, err)
+
+		// Запись добавлена в обход сервиса и не ссылается на предыдущую
+		forged := domain.NewTransaction(nil, id, domain.TransactionDeposit, 5, 115, time.Now())
+		forged.Seq = 2
+		forged.Hash = forged.ComputeHash()
+		require.NoError(t, repo.Ledger.Append(t.Context(), forged))
+
+		report, err := srv.Chain.Verify(t.Context(), &id)
+		require.NoError(t, err)
+		require.NotNil(t, report.Break)
+		assert.Equal(t, id, report.Break.WalletID)
+		assert.Equal(t, int64(2), report.Break.Seq)
+		assert.Equal(t, "previous hash does not match the previous transaction", report.Break.Reason)
+		assert.Equal(t, int64(1), report.Transactions)
+	})
+}
+
+func TestVerify_BalanceChangedOutsideLedger_Reported(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := srv.Deposit(t.Context(), id, 10)
+		require.NoError(t, err)
+
+		wallet, err := domain.NewWallet(id, 1_000_000)
+		require.NoError(t, err)
+		_, err = repo.Wallet.Update(t.Context(), wallet)
+		require.NoError(t, err)
+
+		report, err := srv.Chain.Verify(t.Context(), &id)
+		require.NoError(t, err)
+		require.NotNil(t, report.Break)
+		assert.Equal(t, "wallet balance differs from the last transaction", report.Break.Reason)
+	})
+}
+
+func TestVerify_MissingWallet_NotFound(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletNonExistentID)
+
+		_, err := srv.Chain.Verify(t.Context(), &id)
+		assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+	})
+}
+
+func TestCheckpoint_HeadsOfChainedWallets(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := srv.Deposit(t.Context(), id, 10)
+		require.NoError(t, err)
+		last, err := srv.Deposit(t.Context(), id, 10)
+		require.NoError(t, err)
+		require.Equal(t, int64(120), last.Wallet.Balance())
+
+		cp, err := srv.Chain.Checkpoint(t.Context())
+		require.NoError(t, err)
+		require.Len(t, cp.Heads, 1)
+		assert.Equal(t, id, cp.Heads[0].WalletID)
+		assert.Equal(t, int64(2), cp.Heads[0].Seq)
+		assert.Equal(t, domain.CheckpointRoot(cp.Heads), cp.Root)
+	})
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/maintenance"
+	"wallet-service/internal/metrics"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// escrowPageSize is how many escrows are expired at a time.
+const escrowPageSize = 100
+
+// EscrowService holds funds of a buyer until both sides of a deal confirm
+// it. Each transition is checked against the escrow locked in the
+// transaction that makes it, and moves the funds it implies in that same
+// transaction: the escrow is locked before the wallet paid.
+type EscrowService struct {
+	escrows repository.Escrows
+	wallet  *WalletService
+	log     *slog.Logger
+}
+
+// NewEscrowService moves funds through wallet, which must share the
+// transactions of escrows.
+func NewEscrowService(escrows repository.Escrows, wallet *WalletService, log *slog.Logger) *EscrowService {
+	return &EscrowService{escrows: escrows, wallet: wallet, log: log}
+}
+
+// Create takes amount from the buyer wallet and holds it until expiresAt.
+func (s *EscrowService) Create(ctx context.Context, buyerID, sellerID uuid.UUID, amount int64, expiresAt time.Time) (_ *domain.Escrow, err error) {
+	ctx, span := startOperationSpan(ctx, "EscrowService.Create", buyerID, amount)
+	defer func() { tracing.End(span, err) }()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	escrow, created, err := domain.NewEscrow(buyerID, sellerID, amount, expiresAt.UTC().Truncate(time.Microsecond), audit.ActorFrom(ctx).ID, now)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("escrow.id", escrow.ID.String()))
+
+	receipt, err := s.wallet.update(ctx, buyerID, change{
+		action: domain.AuditEscrowHold,
+		kind:   domain.TransactionEscrowHold,
+		amount: amount,
+		apply: func(w *domain.Wallet) error {
+			return w.Withdraw(amount)
+		},
+		within: func(ctx context.Context) error {
+			return s.escrows.Create(ctx, escrow, created)
+		},
+	})
+	metrics.WalletOperations.WithLabelValues(operationEscrow, operationOutcome(err)).Inc()
+	if err != nil {
+		return nil, err
+	}
+	receipt.Wallet.Release()
+	metrics.EscrowTransitions.WithLabelValues(domain.EscrowCreate).Inc()
+	return escrow, nil
+}
+
+func (s *EscrowService) Get(ctx context.Context, id uuid.UUID) (*domain.Escrow, error) {
+	return s.escrows.Get(ctx, id)
+}
+
+func (s *EscrowService) Transitions(ctx context.Context, id uuid.UUID) ([]domain.EscrowTransition, error) {
+	return s.escrows.Transitions(ctx, id)
+}
+
+// Confirm records the confirmation of party, releasing the funds to the
+// seller once both sides have confirmed. The second confirmation must come
+// from another actor than the first; that the actor is party is left to the
+// gateway.
+func (s *EscrowService) Confirm(ctx context.Context, id uuid.UUID, party string) (*domain.Escrow, error) {
+	event, err := domain.EscrowConfirmation(party)
+	if err != nil {
+		return nil, err
+	}
+	return s.transition(ctx, id, event)
+}
+
+// Cancel refunds the funds to the buyer.
+func (s *EscrowService) Cancel(ctx context.Context, id uuid.UUID) (*domain.Escrow, error) {
+	return s.transition(ctx, id, domain.EscrowCancel)
+}
+
+// Expire refunds every escrow whose deadline has passed and returns how
+// many it refunded. An escrow that cannot be refunded is logged and left to
+// the next run; it cannot be confirmed meanwhile.
+func (s *EscrowService) Expire(ctx context.Context) (expired int, err error) {
+	ctx = audit.WithActor(ctx, audit.Actor{ID: audit.Expiry})
+	ctx, span := tracer.Start(ctx, "EscrowService.Expire")
+	defer func() {
+		span.SetAttributes(attribute.Int("escrow.expired", expired))
+		tracing.End(span, err)
+	}()
+
+	now := time.Now()
+	var after uuid.UUID
+	for {
+		ids, err := s.escrows.Expired(ctx, now, after, escrowPageSize)
+		if err != nil {
+			return expired, err
+		}
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return expired, err
+			}
+			_, err := s.transition(ctx, id, domain.EscrowExpire)
+			switch {
+			case errors.Is(err, domain.ErrEscrowTransition):
+				// Settled meanwhile.
+			case err != nil:
+				s.log.ErrorContext(ctx, "escrow not expired", slog.String("escrow_id", id.String()), logger.Err(err))
+			default:
+				expired++
+			}
+		}
+		if len(ids) < escrowPageSize {
+			return expired, nil
+		}
+		after = ids[len(ids)-1]
+	}
+}
+
+// transition applies event to the escrow and pays the funds out when the
+// escrow reaches a final status, all in one transaction.
+func (s *EscrowService) transition(ctx context.Context, id uuid.UUID, event string) (_ *domain.Escrow, err error) {
+	ctx, span := tracer.Start(ctx, "EscrowService.transition", trace.WithAttributes(
+		attribute.String("escrow.id", id.String()),
+		attribute.String("escrow.event", event),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	actor := audit.ActorFrom(ctx).ID
+	var (
+		escrow *domain.Escrow
+		payee  uuid.UUID
+		payout *change
+		res    applied
+	)
+	err = s.wallet.tx.Run(ctx, func(c context.Context) error {
+		payout, res = nil, applied{}
+
+		var err error
+		if escrow, err = s.escrows.GetForUpdate(c, id); err != nil {
+			return err
+		}
+		transition, err := escrow.Apply(event, actor, time.Now().UTC().Truncate(time.Microsecond))
+		if err != nil {
+			return err
+		}
+		if event == domain.EscrowConfirmBuyer || event == domain.EscrowConfirmSeller {
+			transitions, err := s.escrows.Transitions(c, id)
+			if err != nil {
+				return err
+			}
+			if err = domain.CheckConfirmer(transitions, actor); err != nil {
+				return err
+			}
+		}
+		var ok bool
+		if payee, ok = escrow.Payee(); ok {
+			payout = escrowPayout(escrow)
+			if err = s.wallet.apply(c, payee, *payout, &res); err != nil {
+				return err
+			}
+		}
+		return s.escrows.Update(c, escrow, transition)
+	})
+	if payout != nil {
+		metrics.WalletOperations.WithLabelValues(operationEscrow, operationOutcome(err)).Inc()
+		if receipt, err := s.wallet.finish(ctx, payee, *payout, &res, err); err == nil {
+			receipt.Wallet.Release()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	metrics.EscrowTransitions.WithLabelValues(event).Inc()
+	return escrow, nil
+}
+
+// escrowPayout deposits the funds of a final escrow to the seller or back to
+// the buyer, free of fees.
+func escrowPayout(escrow *domain.Escrow) *change {
+	ch := &change{
+		action: domain.AuditEscrowRefund,
+		kind:   domain.TransactionEscrowRefund,
+		amount: escrow.Amount,
+		apply: func(w *domain.Wallet) error {
+			return w.Deposit(escrow.Amount)
+		},
+	}
+	if escrow.Status == domain.EscrowReleased {
+		ch.action, ch.kind = domain.AuditEscrowRelease, domain.TransactionEscrowRelease
+	}
+	return ch
+}
+
+// RunEscrowExpiryWorker refunds expired escrows every interval until ctx is
+// done. Nothing runs while mode is in maintenance.
+func RunEscrowExpiryWorker(ctx context.Context, escrow Escrow, interval time.Duration, mode *maintenance.Mode, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if mode.Enabled() {
+			continue
+		}
+		if _, err := escrow.Expire(ctx); err != nil && ctx.Err() == nil {
+			log.ErrorContext(ctx, "escrow expiry failed", logger.Err(err))
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscrow_BothConfirm_ReleasedToSeller(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, escrows := newTestService[*EscrowService](t, repo)
+		buyer := uuid.MustParse(testdb.Wallet10000AmountID)
+		seller := uuid.MustParse(testdb.WalletCorrectID)
+
+		escrow, err := escrows.Create(t.Context(), buyer, seller, 700, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, domain.EscrowHeld, escrow.Status)
+		assert.Equal(t, int64(9300), balanceOf(t, srv, buyer))
+
+		escrow, err = escrows.Confirm(auditedContext(t, "seller"), escrow.ID, domain.EscrowSeller)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EscrowSellerConfirmed, escrow.Status)
+		assert.Equal(t, int64(100), balanceOf(t, srv, seller))
+
+		escrow, err = escrows.Confirm(auditedContext(t, "buyer"), escrow.ID, domain.EscrowBuyer)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EscrowReleased, escrow.Status)
+		assert.Equal(t, int64(800), balanceOf(t, srv, seller))
+		assert.Equal(t, int64(9300), balanceOf(t, srv, buyer))
+
+		last, err := repo.Ledger.Last(t.Context(), seller)
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionEscrowRelease, last.Kind)
+
+		// Отменить выплаченную сделку нельзя
+		_, err = escrows.Cancel(t.Context(), escrow.ID)
+		assert.ErrorIs(t, err, domain.ErrEscrowTransition)
+
+		transitions, err := escrows.Transitions(t.Context(), escrow.ID)
+		require.NoError(t, err)
+		require.Len(t, transitions, 3)
+		assert.Equal(t, domain.EscrowCreate, transitions[0].Event)
+		assert.Equal(t, domain.EscrowConfirmSeller, transitions[1].Event)
+		assert.Equal(t, domain.EscrowReleased, transitions[2].To)
+	})
+}
+
+func TestEscrow_ConfirmBothSidesBySameActor_Held(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, escrows := newTestService[*EscrowService](t, repo)
+		buyer := uuid.MustParse(testdb.Wallet10000AmountID)
+		seller := uuid.MustParse(testdb.WalletCorrectID)
+
+		escrow, err := escrows.Create(t.Context(), buyer, seller, 700, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		_, err = escrows.Confirm(auditedContext(t, "buyer"), escrow.ID, domain.EscrowBuyer)
+		require.NoError(t, err)
+
+		// Один участник не может подтвердить сделку за обе стороны
+		_, err = escrows.Confirm(auditedContext(t, "buyer"), escrow.ID, domain.EscrowSeller)
+		require.ErrorIs(t, err, domain.ErrEscrowSameActor)
+
+		escrow, err = escrows.Get(t.Context(), escrow.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EscrowBuyerConfirmed, escrow.Status)
+		assert.Equal(t, int64(100), balanceOf(t, srv, seller))
+	})
+}
+
+func TestEscrow_Cancel_RefundedToBuyer(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, escrows := newTestService[*EscrowService](t, repo)
+		buyer := uuid.MustParse(testdb.Wallet10000AmountID)
+		seller := uuid.MustParse(testdb.WalletCorrectID)
+
+		escrow, err := escrows.Create(t.Context(), buyer, seller, 700, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		escrow, err = escrows.Cancel(t.Context(), escrow.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EscrowCancelled, escrow.Status)
+		assert.Equal(t, int64(10000), balanceOf(t, srv, buyer))
+		assert.Equal(t, int64(100), balanceOf(t, srv, seller))
+
+		last, err := repo.Ledger.Last(t.Context(), buyer)
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionEscrowRefund, last.Kind)
+	})
+}
+
+func TestEscrow_Create_InsufficientFunds_NothingHeld(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, escrows := newTestService[*EscrowService](t, repo)
+		buyer := uuid.MustParse(testdb.WalletCorrectID)
+		seller := uuid.MustParse(testdb.Wallet10000AmountID)
+
+		_, err := escrows.Create(t.Context(), buyer, seller, 101, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, domain.ErrInsufficientBalance)
+
+		// Неизвестный продавец откатывает и списание у покупателя
+		_, err = escrows.Create(t.Context(), buyer, uuid.MustParse(testdb.WalletNonExistentID), 50, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+		assert.Equal(t, int64(100), balanceOf(t, srv, buyer))
+	})
+}
+
+func TestEscrow_Expire_RefundsOnce(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, escrows := newTestService[*EscrowService](t, repo)
+		buyer := uuid.MustParse(testdb.Wallet10000AmountID)
+		seller := uuid.MustParse(testdb.WalletCorrectID)
+
+		escrow, err := escrows.Create(t.Context(), buyer, seller, 700, time.Now().Add(50*time.Millisecond))
+		require.NoError(t, err)
+		_, err = escrows.Confirm(t.Context(), escrow.ID, domain.EscrowBuyer)
+		require.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+
+		// После срока подтверждать поздно
+		_, err = escrows.Confirm(t.Context(), escrow.ID, domain.EscrowSeller)
+		assert.ErrorIs(t, err, domain.ErrEscrowExpired)
+
+		expired, err := escrows.Expire(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+		assert.Equal(t, int64(10000), balanceOf(t, srv, buyer))
+
+		got, err := escrows.Get(t.Context(), escrow.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EscrowExpired, got.Status)
+
+		expired, err = escrows.Expire(t.Context())
+		require.NoError(t, err)
+		assert.Zero(t, expired)
+		assert.Equal(t, int64(10000), balanceOf(t, srv, buyer))
+	})
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"os"
+	"testing"
+	"wallet-service/config"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/fees"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCollectorID = uuid.MustParse(testdb.WalletEmptyWalletID)
+
+func newTestFees(t *testing.T, rules ...fees.Rule) Option {
+	t.Helper()
+	schedule, err := fees.New(rules)
+	require.NoError(t, err)
+	return WithFees(schedule, testCollectorID)
+}
+
+func balanceOf(t *testing.T, srv *Service, id uuid.UUID) int64 {
+	t.Helper()
+	wallet, err := srv.Get(t.Context(), id)
+	require.NoError(t, err)
+	defer wallet.Release()
+	return wallet.Balance()
+}
+
+func TestWithdraw_Fee_ChargedAndCollected(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger, newTestFees(t,
+			fees.Rule{Operation: fees.OperationWithdraw, Fixed: 5, BasisPoints: 100},
+		))
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		receipt, err := srv.Withdraw(t.Context(), id, 50)
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), receipt.Fee)
+		assert.Equal(t, int64(44), receipt.Wallet.Balance())
+		assert.Equal(t, int64(6), balanceOf(t, srv, testCollectorID))
+
+		// Комиссия — отдельная строка в истории плательщика
+		txs, err := repo.Ledger.List(t.Context(), id, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, txs, 2)
+		assert.Equal(t, domain.TransactionWithdraw, txs[0].Kind)
+		assert.Equal(t, int64(50), txs[0].BalanceAfter)
+		assert.Equal(t, domain.TransactionFee, txs[1].Kind)
+		assert.Equal(t, int64(-6), txs[1].Delta())
+		assert.Equal(t, int64(44), txs[1].BalanceAfter)
+
+		income, err := repo.Ledger.Last(t.Context(), testCollectorID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionFeeIncome, income.Kind)
+		assert.Equal(t, int64(6), income.Amount)
+
+		records, err := repo.Audit.List(t.Context(), repository.AuditFilter{WalletID: &testCollectorID, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, domain.AuditFee, records[0].Action)
+
+		report, err := srv.Chain.Verify(t.Context(), nil)
+		require.NoError(t, err)
+		assert.Nil(t, report.Break)
+	})
+}
+
+func TestWithdraw_FeeNotCovered_NothingApplied(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger, newTestFees(t,
+			fees.Rule{Operation: fees.OperationWithdraw, Fixed: 1},
+		))
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := srv.Withdraw(t.Context(), id, 100)
+		require.ErrorIs(t, err, domain.ErrInsufficientBalance)
+
+		assert.Equal(t, int64(100), balanceOf(t, srv, id))
+		assert.Zero(t, balanceOf(t, srv, testCollectorID))
+		last, err := repo.Ledger.Last(t.Context(), id)
+		require.NoError(t, err)
+		assert.Nil(t, last)
+	})
+}
+
+func TestWithdraw_Tier_OwnRule(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		if repo.Tiers == nil {
+			t.Skip("storage does not support fee tiers")
+		}
+		srv := NewService(repo, testLogger, newTestFees(t,
+			fees.Rule{Operation: fees.OperationWithdraw, Fixed: 5},
+			fees.Rule{Operation: fees.OperationWithdraw, Tier: "gold", Fixed: 1},
+		))
+		id := uuid.MustParse(testdb.WalletCorrectID)
+		require.NoError(t, srv.Tier.SetTier(t.Context(), id, "gold"))
+
+		receipt, err := srv.Withdraw(t.Context(), id, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), receipt.Fee)
+
+		// Пополнение без правила бесплатно
+		receipt, err = srv.Deposit(t.Context(), id, 10)
+		require.NoError(t, err)
+		assert.Zero(t, receipt.Fee)
+		assert.Equal(t, int64(99), receipt.Wallet.Balance())
+	})
+}
+
+func TestSetTier_AuditAppendFails_NotApplied(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		if repo.Tiers == nil {
+			t.Skip("storage does not support fee tiers")
+		}
+		if os.Getenv("DATABASE_DRIVER") == config.DriverMemory {
+			t.Skip("memory tiers change outside transactions")
+		}
+		tx := repository.NewTxRunner(repo.Wallet, repository.DefaultRetry, testLogger)
+		srv := NewTierService(repo.Tiers, tx, failingAudit{})
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		require.ErrorIs(t, srv.SetTier(t.Context(), id, "gold"), errAuditDown)
+
+		// Смена тарифа без записи в журнале не должна закоммититься
+		tier, err := repo.Tiers.Tier(t.Context(), id)
+		require.NoError(t, err)
+		assert.Empty(t, tier)
+	})
+}
+
+func TestWithdraw_CollectorMissing_InternalError(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		schedule, err := fees.New([]fees.Rule{{Operation: fees.OperationWithdraw, Fixed: 5}})
+		require.NoError(t, err)
+		srv := NewService(repo, testLogger, WithFees(schedule, uuid.MustParse(testdb.WalletNonExistentID)))
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err = srv.Withdraw(t.Context(), id, 50)
+		require.ErrorIs(t, err, ErrFeeWalletMissing)
+		assert.NotErrorIs(t, err, domain.ErrWalletNotFound)
+
+		// Операция откатывается целиком
+		assert.Equal(t, int64(100), balanceOf(t, srv, id))
+	})
+}
+
+func TestWithdraw_Collector_Free(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger, newTestFees(t,
+			fees.Rule{Operation: fees.OperationDeposit, Fixed: 5},
+		))
+
+		receipt, err := srv.Deposit(t.Context(), testCollectorID, 10)
+		require.NoError(t, err)
+		assert.Zero(t, receipt.Fee)
+		assert.Equal(t, int64(10), receipt.Wallet.Balance())
+	})
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/metrics"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// historyPageSize is how many transactions are replayed at a time.
+const historyPageSize = 500
+
+var ErrFutureBalance = errors.New("balance is not known for a time in the future")
+
+type HistoryService struct {
+	wallets repository.Wallet
+	ledger  repository.Ledger
+	log     *slog.Logger
+}
+
+func NewHistoryService(wallets repository.Wallet, ledger repository.Ledger, log *slog.Logger) *HistoryService {
+	return &HistoryService{wallets: wallets, ledger: ledger, log: log}
+}
+
+// BalanceAt reconstructs the balance of the wallet at at, counting the
+// transactions made at that very moment. It replays transactions from the
+// nearest earlier snapshot, or from the start of the chain when there is
+// none. Before its first transaction a wallet has the balance that
+// transaction was applied to; a wallet without transactions has always had
+// its current balance.
+func (s *HistoryService) BalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "HistoryService.BalanceAt", trace.WithAttributes(
+		attribute.String("wallet.id", walletID.String()),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if at.After(time.Now()) {
+		return 0, ErrFutureBalance
+	}
+
+	wallet, err := s.wallets.Get(ctx, walletID)
+	if err != nil {
+		return 0, err
+	}
+	balance := wallet.Balance()
+	wallet.Release()
+
+	// Transaction times are kept to the microsecond.
+	end := at.Truncate(time.Microsecond).Add(time.Microsecond)
+
+	snapshot, err := s.ledger.SnapshotBefore(ctx, walletID, end)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		afterSeq int64
+		known    bool
+		replayed int
+	)
+	if snapshot != nil {
+		afterSeq, balance, known = snapshot.Seq, snapshot.Balance, true
+	}
+	defer func() { span.SetAttributes(attribute.Int("ledger.replayed", replayed)) }()
+
+	for {
+		page, err := s.ledger.List(ctx, walletID, afterSeq, historyPageSize)
+		if err != nil {
+			return 0, err
+		}
+		for i := range page {
+			tx := &page[i]
+			if !known {
+				balance, known = tx.BalanceAfter-tx.Delta(), true
+			}
+			if !tx.At.Before(end) {
+				return balance, nil
+			}
+			if balance += tx.Delta(); balance != tx.BalanceAfter {
+				return 0, &domain.ChainBreak{WalletID: walletID, Seq: tx.Seq, Reason: "balance does not follow from the previous transaction"}
+			}
+			replayed++
+		}
+		if len(page) < historyPageSize {
+			return balance, nil
+		}
+		afterSeq = page[len(page)-1].Seq
+	}
+}
+
+// Snapshot records the balance at the head of every chain and returns how
+// many wallets have one. Heads already recorded are left as they are.
+func (s *HistoryService) Snapshot(ctx context.Context) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "HistoryService.Snapshot")
+	defer func() { tracing.End(span, err) }()
+
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "error"
+		}
+		metrics.Snapshots.WithLabelValues(outcome).Inc()
+	}()
+
+	var count int
+	err = eachWallet(ctx, s.ledger, func(id uuid.UUID) (bool, error) {
+		head, err := s.ledger.Last(ctx, id)
+		if err != nil || head == nil {
+			return err == nil, err
+		}
+		err = s.ledger.SaveSnapshot(ctx, &domain.BalanceSnapshot{WalletID: id, Seq: head.Seq, Balance: head.BalanceAfter, At: head.At})
+		if err != nil {
+			return false, err
+		}
+		count++
+		return true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.log.InfoContext(ctx, "balance snapshots taken", slog.Int("wallets", count))
+	return count, nil
+}
+
+// RunSnapshots takes snapshots every interval until ctx is done. Failures
+// are logged and retried at the next tick.
+func (s *HistoryService) RunSnapshots(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Snapshot(ctx); err != nil && ctx.Err() == nil {
+				s.log.ErrorContext(ctx, "failed to take balance snapshots", logger.Err(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceAt_ReplaysHistory(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		before := time.Now()
+		time.Sleep(time.Millisecond)
+		_, err := srv.Deposit(t.Context(), id, 50)
+		require.NoError(t, err)
+		_, err = srv.Withdraw(t.Context(), id, 30)
+		require.NoError(t, err)
+
+		txs, err := repo.Ledger.List(t.Context(), id, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, txs, 2)
+
+		for name, tc := range map[string]struct {
+			at   time.Time
+			want int64
+		}{
+			"before the first transaction": {before, 100},
+			"at the first transaction":     {txs[0].At, 150},
+			"now":                          {time.Now(), 120},
+		} {
+			balance, err := srv.History.BalanceAt(t.Context(), id, tc.at)
+			require.NoError(t, err, name)
+			assert.Equal(t, tc.want, balance, name)
+		}
+	})
+}
+
+func TestBalanceAt_FromSnapshot(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := srv.Deposit(t.Context(), id, 50)
+		require.NoError(t, err)
+		count, err := srv.History.Snapshot(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		_, err = srv.Withdraw(t.Context(), id, 30)
+		require.NoError(t, err)
+
+		snapshot, err := repo.Ledger.SnapshotBefore(t.Context(), id, time.Now())
+		require.NoError(t, err)
+		require.NotNil(t, snapshot)
+		assert.Equal(t, int64(1), snapshot.Seq)
+		assert.Equal(t, int64(150), snapshot.Balance)
+
+		balance, err := srv.History.BalanceAt(t.Context(), id, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, int64(120), balance)
+	})
+}
+
+func TestBalanceAt_NoTransactions_CurrentBalance(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+
+		balance, err := srv.History.BalanceAt(t.Context(), uuid.MustParse(testdb.WalletCorrectID), time.Now().Add(-time.Hour))
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), balance)
+	})
+}
+
+func TestBalanceAt_MissingWallet_NotFound(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+
+		_, err := srv.History.BalanceAt(t.Context(), uuid.MustParse(testdb.WalletNonExistentID), time.Now())
+
+		assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+	})
+}
+
+func TestBalanceAt_Future_Rejected(t *testing.T) {
+	t.Parallel()
+	srv := NewHistoryService(nil, nil, testLogger)
+
+	_, err := srv.BalanceAt(t.Context(), uuid.New(), time.Now().Add(time.Hour))
+
+	assert.ErrorIs(t, err, ErrFutureBalance)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/dbsource"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/maintenance"
+	"wallet-service/internal/metrics"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// interestPageSize is how many accounts are settled at a time.
+const interestPageSize = 100
+
+// errInterestPaid rolls back a payout made meanwhile by another job.
+var errInterestPaid = errors.New("interest period already paid")
+
+// InterestService accrues interest on savings wallets day by day and pays it
+// out month by month. Every day and every month is recorded once, so a job
+// that runs again, late or alongside another one, neither accrues nor pays
+// twice.
+type InterestService struct {
+	interest repository.Interest
+	wallet   *WalletService
+	history  History
+	audit    repository.Audit
+	log      *slog.Logger
+}
+
+// NewInterestService pays interest through wallet and reads end-of-day
+// balances from history. Rate changes are recorded in audit unless it is nil,
+// in the transaction of the change.
+func NewInterestService(interest repository.Interest, wallet *WalletService, history History, audit repository.Audit, log *slog.Logger) *InterestService {
+	return &InterestService{interest: interest, wallet: wallet, history: history, audit: audit, log: log}
+}
+
+// SetRate sets the annual rate of the wallet in basis points. A wallet
+// earns interest from the day its first rate is set and is paid for the
+// rest of that month at the end of it. Days not accrued yet are accrued at
+// the new rate.
+func (s *InterestService) SetRate(ctx context.Context, walletID uuid.UUID, rate int64) (_ *domain.InterestAccount, err error) {
+	ctx, span := tracer.Start(ctx, "InterestService.SetRate", trace.WithAttributes(
+		attribute.String("wallet.id", walletID.String()),
+		attribute.Int64("interest.rate", rate),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if rate < 0 || rate > domain.MaxInterestRate {
+		return nil, domain.ErrInvalidInterestRate
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	var account *domain.InterestAccount
+	err = s.wallet.tx.Run(ctx, func(c context.Context) error {
+		var err error
+		account, err = s.interest.SetRate(c, walletID, rate, domain.StartOfDay(now), domain.StartOfMonth(now), now)
+		if err != nil {
+			return err
+		}
+		if s.audit == nil {
+			return nil
+		}
+		record := newAuditRecord(c, domain.AuditInterestRateSet, operationOutcome(nil))
+		record.WalletID = &walletID
+		return s.audit.Append(c, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *InterestService) Get(ctx context.Context, walletID uuid.UUID) (*domain.InterestAccount, error) {
+	return s.interest.Account(ctx, walletID)
+}
+
+// Run accrues every day that has ended and pays out every month whose days
+// are all accrued, and returns how many accruals and payouts it made. An
+// account that cannot be settled is logged and left to the next run.
+// Balances are read from the primary, so what is accrued does not depend on
+// replica lag.
+func (s *InterestService) Run(ctx context.Context) (accrued, paid int, err error) {
+	ctx = audit.WithActor(ctx, audit.Actor{ID: audit.Interest})
+	ctx = dbsource.WithConsistency(ctx, dbsource.Strong)
+	ctx, span := tracer.Start(ctx, "InterestService.Run")
+	defer func() {
+		span.SetAttributes(attribute.Int("interest.accrued", accrued), attribute.Int("interest.paid", paid))
+		tracing.End(span, err)
+	}()
+
+	today := domain.StartOfDay(time.Now())
+	var after uuid.UUID
+	for {
+		accounts, err := s.interest.Accounts(ctx, after, interestPageSize)
+		if err != nil {
+			return accrued, paid, err
+		}
+		for i := range accounts {
+			if err := ctx.Err(); err != nil {
+				return accrued, paid, err
+			}
+			a, p, err := s.settle(ctx, &accounts[i], today)
+			accrued, paid = accrued+a, paid+p
+			if err != nil {
+				s.log.ErrorContext(ctx, "interest not settled",
+					slog.String("wallet_id", accounts[i].WalletID.String()), logger.Err(err))
+			}
+		}
+		if len(accounts) < interestPageSize {
+			return accrued, paid, nil
+		}
+		after = accounts[len(accounts)-1].WalletID
+	}
+}
+
+// settle brings one account up to today.
+func (s *InterestService) settle(ctx context.Context, account *domain.InterestAccount, today time.Time) (accrued, paid int, err error) {
+	for account.NextAccrualAt.Before(today) {
+		day := account.NextAccrualAt
+		next := day.AddDate(0, 0, 1)
+		// The balance at the last instant of the day.
+		balance, err := s.history.BalanceAt(ctx, account.WalletID, next.Add(-time.Microsecond))
+		if err != nil {
+			return accrued, paid, err
+		}
+		accrual := &domain.InterestAccrual{
+			WalletID: account.WalletID,
+			Day:      day,
+			Balance:  balance,
+			Rate:     account.Rate,
+			Amount:   domain.DailyInterest(balance, account.Rate),
+		}
+		ok, err := s.interest.Accrue(ctx, accrual, next, time.Now().UTC().Truncate(time.Microsecond))
+		if err != nil {
+			return accrued, paid, err
+		}
+		if !ok {
+			// Another job got there first.
+			if account, err = s.interest.Account(ctx, account.WalletID); err != nil {
+				return accrued, paid, err
+			}
+			continue
+		}
+		account.NextAccrualAt = next
+		account.Accrued += accrual.Amount
+		accrued++
+	}
+
+	for account.PayoutDue() {
+		period := account.NextPayoutAt
+		next := period.AddDate(0, 1, 0)
+		amount, err := s.interest.Accrued(ctx, account.WalletID, period, next)
+		if err != nil {
+			return accrued, paid, err
+		}
+		payout := &domain.InterestPayout{
+			WalletID: account.WalletID,
+			Period:   period,
+			Amount:   amount,
+			PaidAt:   time.Now().UTC().Truncate(time.Microsecond),
+		}
+		ok, err := s.pay(ctx, payout, next)
+		if err != nil {
+			return accrued, paid, err
+		}
+		if !ok {
+			if account, err = s.interest.Account(ctx, account.WalletID); err != nil {
+				return accrued, paid, err
+			}
+			continue
+		}
+		account.NextPayoutAt = next
+		account.Accrued -= amount
+		paid++
+	}
+	return accrued, paid, nil
+}
+
+// pay deposits the payout and records it in the same transaction. It
+// reports false when the period has been paid meanwhile; the deposit is
+// then rolled back. A month without interest is recorded without one.
+func (s *InterestService) pay(ctx context.Context, payout *domain.InterestPayout, next time.Time) (bool, error) {
+	if payout.Amount == 0 {
+		return s.interest.Pay(ctx, payout, next)
+	}
+
+	receipt, err := s.wallet.payInterest(ctx, payout.WalletID, payout.Amount, func(ctx context.Context) error {
+		ok, err := s.interest.Pay(ctx, payout, next)
+		if err == nil && !ok {
+			err = errInterestPaid
+		}
+		return err
+	})
+	if errors.Is(err, errInterestPaid) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	receipt.Wallet.Release()
+	metrics.InterestPaid.Add(float64(payout.Amount))
+	return true, nil
+}
+
+// RunInterestWorker runs interest every interval until ctx is done. Nothing
+// runs while mode is in maintenance; the days and months that end meanwhile
+// are settled by the next run.
+func RunInterestWorker(ctx context.Context, interest Interest, interval time.Duration, mode *maintenance.Mode, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if mode.Enabled() {
+			continue
+		}
+		if _, _, err := interest.Run(ctx); err != nil && ctx.Err() == nil {
+			log.ErrorContext(ctx, "interest run failed", logger.Err(err))
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/dbsource"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterest_Run_PaysEachMonthOnce(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, interest := newTestService[*InterestService](t, repo)
+		id := uuid.MustParse(testdb.Wallet10000AmountID)
+
+		// Счёт открыт два месяца назад; 36.5% годовых от 10000 — ровно 10 в день
+		now := time.Now().UTC()
+		today := domain.StartOfDay(now)
+		first := domain.StartOfMonth(now).AddDate(0, -2, 0)
+		_, err := repo.Interest.SetRate(t.Context(), id, 3650, first, first, now)
+		require.NoError(t, err)
+
+		accrued, paid, err := interest.Run(t.Context())
+		require.NoError(t, err)
+		days := int(today.Sub(first).Hours() / 24)
+		assert.Equal(t, days, accrued)
+		assert.Equal(t, 2, paid)
+
+		paidDays := int(domain.StartOfMonth(now).Sub(first).Hours() / 24)
+		assert.Equal(t, int64(10000+10*paidDays), balanceOf(t, srv, id))
+
+		account, err := interest.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10*(days-paidDays)), account.Accrued)
+		assert.True(t, account.NextAccrualAt.Equal(today))
+		assert.True(t, account.NextPayoutAt.Equal(domain.StartOfMonth(now)))
+
+		last, err := repo.Ledger.Last(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionInterest, last.Kind)
+
+		// Повторный запуск ничего не начисляет и не выплачивает
+		accrued, paid, err = interest.Run(t.Context())
+		require.NoError(t, err)
+		assert.Zero(t, accrued)
+		assert.Zero(t, paid)
+		assert.Equal(t, int64(10000+10*paidDays), balanceOf(t, srv, id))
+	})
+}
+
+// oneAccount serves a single interest account and keeps every accrual.
+type oneAccount struct {
+	repository.Interest
+	account  domain.InterestAccount
+	accruals []domain.InterestAccrual
+}
+
+func (i *oneAccount) Accounts(context.Context, uuid.UUID, int) ([]domain.InterestAccount, error) {
+	return []domain.InterestAccount{i.account}, nil
+}
+
+func (i *oneAccount) Accrue(_ context.Context, accrual *domain.InterestAccrual, _, _ time.Time) (bool, error) {
+	i.accruals = append(i.accruals, *accrual)
+	return true, nil
+}
+
+// replicaHistory answers a stale balance unless read from the primary.
+type replicaHistory struct{}
+
+func (replicaHistory) BalanceAt(ctx context.Context, _ uuid.UUID, _ time.Time) (int64, error) {
+	if dbsource.ConsistencyFrom(ctx) != dbsource.Strong {
+		return 0, nil
+	}
+	return 10000, nil
+}
+
+func (replicaHistory) Snapshot(context.Context) (int, error) {
+	return 0, nil
+}
+
+func TestInterest_Run_StaleReplica_AccruesFromPrimary(t *testing.T) {
+	today := domain.StartOfDay(time.Now())
+	repo := &oneAccount{account: domain.InterestAccount{
+		WalletID:      uuid.New(),
+		Rate:          3650,
+		NextAccrualAt: today.AddDate(0, 0, -1),
+		NextPayoutAt:  domain.StartOfMonth(today).AddDate(0, 1, 0),
+	}}
+	interest := NewInterestService(repo, nil, replicaHistory{}, nil, testLogger)
+
+	// Реплика отстаёт и видит нулевой баланс; начисление считается по основной базе
+	accrued, paid, err := interest.Run(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, accrued)
+	assert.Zero(t, paid)
+	require.Len(t, repo.accruals, 1)
+	assert.Equal(t, int64(10000), repo.accruals[0].Balance)
+	assert.Equal(t, int64(10), repo.accruals[0].Amount)
+}
+
+func TestInterest_Pay_PaidPeriod_RolledBack(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, interest := newTestService[*InterestService](t, repo)
+		id := uuid.MustParse(testdb.Wallet10000AmountID)
+
+		now := time.Now().UTC()
+		first := domain.StartOfMonth(now).AddDate(0, -1, 0)
+		_, err := repo.Interest.SetRate(t.Context(), id, 3650, first, first, now)
+		require.NoError(t, err)
+		_, paid, err := interest.Run(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, 1, paid)
+		balance := balanceOf(t, srv, id)
+
+		// Другой запуск уже выплатил этот месяц: пополнение откатывается
+		ok, err := interest.pay(t.Context(), &domain.InterestPayout{WalletID: id, Period: first, Amount: 300, PaidAt: now}, first.AddDate(0, 1, 0))
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, balance, balanceOf(t, srv, id))
+	})
+}
+
+func TestInterest_SetRate(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		_, interest := newTestService[*InterestService](t, repo)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := interest.Get(t.Context(), id)
+		assert.ErrorIs(t, err, domain.ErrInterestAccountNotFound)
+		_, err = interest.SetRate(t.Context(), id, domain.MaxInterestRate+1)
+		assert.ErrorIs(t, err, domain.ErrInvalidInterestRate)
+		_, err = interest.SetRate(t.Context(), uuid.MustParse(testdb.WalletNonExistentID), 500)
+		assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+
+		account, err := interest.SetRate(t.Context(), id, 500)
+		require.NoError(t, err)
+		assert.Equal(t, int64(500), account.Rate)
+		assert.True(t, account.NextAccrualAt.Equal(domain.StartOfDay(time.Now())))
+
+		// Начислять пока нечего: сегодняшний день ещё не закончился
+		accrued, paid, err := interest.Run(t.Context())
+		require.NoError(t, err)
+		assert.Zero(t, accrued)
+		assert.Zero(t, paid)
+
+		records, err := repo.Audit.List(t.Context(), repository.AuditFilter{WalletID: &id, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, domain.AuditInterestRateSet, records[0].Action)
+	})
+}
+
+func TestInterest_SetRate_AuditAppendFails_NotApplied(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, _ := newTestService[*InterestService](t, repo)
+		if os.Getenv("DATABASE_DRIVER") == config.DriverMemory {
+			t.Skip("memory interest accounts change outside transactions")
+		}
+		interest := NewInterestService(repo.Interest, srv.Wallet.(*WalletService), srv.History, failingAudit{}, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := interest.SetRate(t.Context(), id, 500)
+		require.ErrorIs(t, err, errAuditDown)
+
+		// Ставка без записи в журнале не должна закоммититься
+		_, err = repo.Interest.Account(t.Context(), id)
+		assert.ErrorIs(t, err, domain.ErrInterestAccountNotFound)
+	})
+}
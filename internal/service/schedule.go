@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/cron"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/maintenance"
+	"wallet-service/internal/metrics"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// scheduleListLimit bounds the schedules listed per wallet.
+	scheduleListLimit = 100
+	// maxScheduleRetryDelay caps the doubling backoff between attempts.
+	maxScheduleRetryDelay = time.Hour
+)
+
+var (
+	ErrInvalidCron = errors.New("invalid cron expression")
+	// ErrScheduleTiming is returned unless exactly one of a run time and a
+	// cron expression is given.
+	ErrScheduleTiming = errors.New("exactly one of run time and cron expression must be given")
+
+	errOutcomeUnknown = errors.New("run was interrupted and its outcome is unknown")
+)
+
+// SchedulerActor is the audit actor of the runs of a schedule.
+func SchedulerActor(id uuid.UUID) string {
+	return "scheduler:" + id.String()
+}
+
+// ScheduleService keeps scheduled operations and runs the due ones through
+// the wallet service, so a scheduled run is locked, chained and audited like
+// any other operation.
+type ScheduleService struct {
+	schedules repository.Schedules
+	wallet    Wallet
+	audit     repository.Audit
+	policy    config.SchedulerConfig
+	log       *slog.Logger
+}
+
+// NewScheduleService runs schedules through wallet. audit must be the log
+// wallet records operations to; without it a run interrupted by a crash
+// fails rather than risking being applied twice.
+func NewScheduleService(schedules repository.Schedules, wallet Wallet, audit repository.Audit, policy config.SchedulerConfig, log *slog.Logger) *ScheduleService {
+	return &ScheduleService{schedules: schedules, wallet: wallet, audit: audit, policy: policy, log: log}
+}
+
+// Create schedules operation for runAt, or for every occurrence of cronExpr
+// when it is not empty. The actor in ctx is kept as the creator.
+func (s *ScheduleService) Create(ctx context.Context, walletID uuid.UUID, operation string, amount int64, runAt time.Time, cronExpr string) (_ *domain.Schedule, err error) {
+	ctx, span := tracer.Start(ctx, "ScheduleService.Create", trace.WithAttributes(
+		attribute.String("wallet.id", walletID.String()),
+		attribute.String("schedule.operation", operation),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	now := time.Now()
+	if runAt.IsZero() == (cronExpr == "") {
+		return nil, ErrScheduleTiming
+	}
+	if cronExpr != "" {
+		expr, err := cron.Parse(cronExpr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCron, err)
+		}
+		runAt = expr.Next(now)
+	}
+
+	schedule, err := domain.NewSchedule(walletID, operation, amount, cronExpr, runAt, s.policy.MaxAttempts, audit.ActorFrom(ctx).ID, now)
+	if err != nil {
+		return nil, err
+	}
+	return s.schedules.Create(ctx, schedule)
+}
+
+func (s *ScheduleService) Get(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	return s.schedules.Get(ctx, id)
+}
+
+// List returns the latest schedules of the wallet, optionally only those
+// with status.
+func (s *ScheduleService) List(ctx context.Context, walletID uuid.UUID, status string) ([]domain.Schedule, error) {
+	wallet, err := s.wallet.Get(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	wallet.Release()
+
+	return s.schedules.List(ctx, walletID, status, scheduleListLimit)
+}
+
+func (s *ScheduleService) Cancel(ctx context.Context, id uuid.UUID) (*domain.Schedule, error) {
+	return s.schedules.Cancel(ctx, id, time.Now().UTC().Truncate(time.Microsecond))
+}
+
+// RunDue claims a batch of due schedules and runs them one by one. It
+// returns how many were claimed. A run whose outcome cannot be stored keeps
+// its claim until the lease expires and is picked up again.
+func (s *ScheduleService) RunDue(ctx context.Context) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "ScheduleService.RunDue")
+	defer func() { tracing.End(span, err) }()
+
+	claims, err := s.schedules.Claim(ctx, s.policy.Lease, s.policy.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	span.SetAttributes(attribute.Int("schedule.claimed", len(claims)))
+
+	for i := range claims {
+		// The rest are left to expire and are recovered like a crash.
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.run(ctx, &claims[i]); err != nil {
+			s.log.ErrorContext(ctx, "scheduled run not finished",
+				slog.String("schedule_id", claims[i].Schedule.ID.String()), logger.Err(err))
+		}
+	}
+	return len(claims), nil
+}
+
+// RunWorker runs due schedules every interval until ctx is done. A full
+// batch is followed by the next one at once. Nothing runs while mode is in
+// maintenance; schedules due meanwhile run when it ends.
+func (s *ScheduleService) RunWorker(ctx context.Context, interval time.Duration, mode *maintenance.Mode) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for ctx.Err() == nil && !mode.Enabled() {
+			n, err := s.RunDue(ctx)
+			if err != nil {
+				s.log.ErrorContext(ctx, "scheduled runs failed", logger.Err(err))
+			}
+			if err != nil || n < s.policy.BatchSize {
+				break
+			}
+		}
+	}
+}
+
+// run executes one claimed schedule and stores the outcome.
+func (s *ScheduleService) run(ctx context.Context, claim *domain.ScheduleClaim) (err error) {
+	schedule := claim.Schedule
+	ctx = audit.WithActor(ctx, audit.Actor{ID: SchedulerActor(schedule.ID)})
+	ctx, span := tracer.Start(ctx, "ScheduleService.run", trace.WithAttributes(
+		attribute.String("schedule.id", schedule.ID.String()),
+		attribute.String("wallet.id", schedule.WalletID.String()),
+		attribute.Int("schedule.attempt", schedule.Attempts),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	var runErr error
+	applied := false
+	if claim.Interrupted != nil {
+		s.log.WarnContext(ctx, "recovering interrupted scheduled run",
+			slog.String("schedule_id", schedule.ID.String()), slog.Time("claimed_at", *claim.Interrupted))
+		applied, runErr = s.appliedSince(ctx, &schedule)
+		if runErr != nil && !errors.Is(runErr, errOutcomeUnknown) {
+			// Until the log can be read, the run must neither be repeated
+			// nor given up: it is left to the next claim.
+			return runErr
+		}
+	}
+	if !applied && runErr == nil {
+		runErr = s.execute(ctx, &schedule)
+	}
+
+	outcome := s.settle(&schedule, runErr, time.Now())
+	if applied {
+		outcome = "recovered"
+	}
+	metrics.ScheduledRuns.WithLabelValues(outcome).Inc()
+	span.SetAttributes(attribute.String("schedule.outcome", outcome))
+
+	return s.schedules.Finish(context.WithoutCancel(ctx), &schedule)
+}
+
+// appliedSince reports whether an interrupted attempt of the current run of
+// the schedule was applied. Every attempt of a run starts after the run is
+// due, and every earlier run was recorded before it. Without an audit log
+// it returns errOutcomeUnknown.
+func (s *ScheduleService) appliedSince(ctx context.Context, schedule *domain.Schedule) (bool, error) {
+	if s.audit == nil {
+		return false, errOutcomeUnknown
+	}
+	records, err := s.audit.List(ctx, repository.AuditFilter{
+		WalletID: &schedule.WalletID,
+		Actor:    SchedulerActor(schedule.ID),
+		Outcome:  "success",
+		From:     schedule.NextRunAt,
+		Limit:    1,
+	})
+	if err != nil {
+		return false, fmt.Errorf("look up interrupted run: %w", err)
+	}
+	return len(records) > 0, nil
+}
+
+// execute applies the operation, leaving half the lease to store the
+// outcome.
+func (s *ScheduleService) execute(ctx context.Context, schedule *domain.Schedule) error {
+	ctx, cancel := context.WithTimeout(ctx, s.policy.Lease/2)
+	defer cancel()
+
+	operation := s.wallet.Deposit
+	if schedule.Operation == domain.ScheduleWithdraw {
+		operation = s.wallet.Withdraw
+	}
+	receipt, err := operation(ctx, schedule.WalletID, schedule.Amount)
+	if err != nil {
+		return err
+	}
+	receipt.Wallet.Release()
+	return nil
+}
+
+// settle moves the schedule on after an attempt that ended with err and
+// returns the outcome of the attempt.
+func (s *ScheduleService) settle(schedule *domain.Schedule, err error, now time.Time) string {
+	now = now.UTC().Truncate(time.Microsecond)
+	schedule.LastRunAt = now
+	schedule.UpdatedAt = now
+
+	switch {
+	case err == nil:
+		schedule.Runs++
+		schedule.LastError = ""
+		s.advance(schedule, now, domain.ScheduleCompleted)
+		return "success"
+	case retryable(err) && schedule.Attempts < schedule.MaxAttempts:
+		schedule.Status = domain.ScheduleRetrying
+		schedule.NextRunAt = now.Add(s.backoff(schedule.Attempts))
+		schedule.LastError = err.Error()
+		return "retry"
+	default:
+		schedule.Failures++
+		schedule.LastError = err.Error()
+		s.advance(schedule, now, domain.ScheduleFailed)
+		return "failed"
+	}
+}
+
+// advance ends a one-off schedule with status and moves a recurring one to
+// its next occurrence.
+func (s *ScheduleService) advance(schedule *domain.Schedule, now time.Time, status string) {
+	if !schedule.Recurring() {
+		schedule.Status = status
+		return
+	}
+
+	expr, err := cron.Parse(schedule.Cron)
+	var next time.Time
+	if err == nil {
+		next = expr.Next(now)
+	}
+	if next.IsZero() {
+		schedule.Status = domain.ScheduleCompleted
+		return
+	}
+	schedule.Status = domain.SchedulePending
+	schedule.NextRunAt = next
+	schedule.Attempts = 0
+}
+
+// backoff is the delay after the given failed attempt: RetryDelay doubled
+// for every earlier one, up to maxScheduleRetryDelay.
+func (s *ScheduleService) backoff(attempt int) time.Duration {
+	delay := s.policy.RetryDelay
+	for range attempt - 1 {
+		if delay >= maxScheduleRetryDelay/2 {
+			return maxScheduleRetryDelay
+		}
+		delay *= 2
+	}
+	return min(delay, maxScheduleRetryDelay)
+}
+
+// retryable reports whether a later attempt may succeed where this one
+// failed. Missing funds may still arrive; an unknown wallet or an invalid
+// amount will not change.
+func retryable(err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrWalletNotFound),
+		errors.Is(err, domain.ErrZeroAmount),
+		errors.Is(err, domain.ErrNegativeAmount),
+		errors.Is(err, domain.ErrOverflow),
+		errors.Is(err, errOutcomeUnknown):
+		return false
+	default:
+		return true
+	}
+}
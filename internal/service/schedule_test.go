@@ -0,0 +1,235 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/audit"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchedulerPolicy = config.SchedulerConfig{
+	BatchSize:   10,
+	Lease:       time.Minute,
+	MaxAttempts: 3,
+	RetryDelay:  time.Minute,
+}
+
+func newTestScheduler(t *testing.T, repo *repository.Repository, policy config.SchedulerConfig) (*Service, *ScheduleService) {
+	t.Helper()
+	if repo.Schedules == nil {
+		t.Skip("storage does not support scheduled operations")
+	}
+	srv := NewService(repo, testLogger)
+	return srv, NewScheduleService(repo.Schedules, srv.Wallet, repo.Audit, policy, testLogger)
+}
+
+func TestSchedule_OneOff_RunsOnce(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, scheduler := newTestScheduler(t, repo, testSchedulerPolicy)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		ctx := audit.WithActor(t.Context(), audit.Actor{ID: "alice"})
+		schedule, err := scheduler.Create(ctx, id, domain.ScheduleDeposit, 25, time.Now().Add(-time.Second), "")
+		require.NoError(t, err)
+		assert.Equal(t, domain.SchedulePending, schedule.Status)
+		assert.Equal(t, "alice", schedule.CreatedBy)
+
+		n, err := scheduler.RunDue(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		wallet, err := srv.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(125), wallet.Balance())
+
+		got, err := scheduler.Get(t.Context(), schedule.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ScheduleCompleted, got.Status)
+		assert.Equal(t, int64(1), got.Runs)
+		assert.False(t, got.LastRunAt.IsZero())
+
+		// Операция записана в аудит от имени расписания
+		records, err := repo.Audit.List(t.Context(), repository.AuditFilter{Actor: SchedulerActor(schedule.ID), Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, records, 1)
+
+		n, err = scheduler.RunDue(t.Context())
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
+
+func TestSchedule_Create_Invalid(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		_, scheduler := newTestScheduler(t, repo, testSchedulerPolicy)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+		runAt := time.Now().Add(time.Hour)
+
+		_, err := scheduler.Create(t.Context(), id, domain.ScheduleDeposit, 10, time.Time{}, "")
+		assert.ErrorIs(t, err, ErrScheduleTiming)
+		_, err = scheduler.Create(t.Context(), id, domain.ScheduleDeposit, 10, runAt, "@daily")
+		assert.ErrorIs(t, err, ErrScheduleTiming)
+		_, err = scheduler.Create(t.Context(), id, domain.ScheduleDeposit, 10, time.Time{}, "61 * * * *")
+		assert.ErrorIs(t, err, ErrInvalidCron)
+		_, err = scheduler.Create(t.Context(), id, domain.ScheduleDeposit, 0, runAt, "")
+		assert.ErrorIs(t, err, domain.ErrZeroAmount)
+		_, err = scheduler.Create(t.Context(), uuid.MustParse(testdb.WalletNonExistentID), domain.ScheduleDeposit, 10, runAt, "")
+		assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+	})
+}
+
+func TestSchedule_Recurring_NextOccurrence(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		_, scheduler := newTestScheduler(t, repo, testSchedulerPolicy)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		schedule, err := scheduler.Create(t.Context(), id, domain.ScheduleWithdraw, 10, time.Time{}, "0 9 * * *")
+		require.NoError(t, err)
+		assert.True(t, schedule.NextRunAt.After(time.Now()))
+		assert.Equal(t, 9, schedule.NextRunAt.Hour())
+
+		listed, err := scheduler.List(t.Context(), id, domain.SchedulePending)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, schedule.ID, listed[0].ID)
+
+		_, err = scheduler.List(t.Context(), uuid.MustParse(testdb.WalletNonExistentID), "")
+		assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+	})
+}
+
+func TestSchedule_InsufficientBalance_Retried(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		_, scheduler := newTestScheduler(t, repo, testSchedulerPolicy)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		schedule, err := scheduler.Create(t.Context(), id, domain.ScheduleWithdraw, 500, time.Now().Add(-time.Second), "")
+		require.NoError(t, err)
+		_, err = scheduler.RunDue(t.Context())
+		require.NoError(t, err)
+
+		got, err := scheduler.Get(t.Context(), schedule.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ScheduleRetrying, got.Status)
+		assert.Equal(t, 1, got.Attempts)
+		assert.Contains(t, got.LastError, domain.ErrInsufficientBalance.Error())
+		assert.WithinDuration(t, time.Now().Add(testSchedulerPolicy.RetryDelay), got.NextRunAt, 5*time.Second)
+
+		// Повтор ещё не наступил
+		n, err := scheduler.RunDue(t.Context())
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		cancelled, err := scheduler.Cancel(t.Context(), schedule.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ScheduleCancelled, cancelled.Status)
+	})
+}
+
+func TestSchedule_LastAttempt_Failed(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		policy := testSchedulerPolicy
+		policy.MaxAttempts = 1
+		_, scheduler := newTestScheduler(t, repo, policy)
+
+		schedule, err := scheduler.Create(t.Context(), uuid.MustParse(testdb.WalletCorrectID), domain.ScheduleWithdraw, 500, time.Now().Add(-time.Second), "")
+		require.NoError(t, err)
+		_, err = scheduler.RunDue(t.Context())
+		require.NoError(t, err)
+
+		got, err := scheduler.Get(t.Context(), schedule.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ScheduleFailed, got.Status)
+		assert.Equal(t, int64(1), got.Failures)
+
+		_, err = scheduler.Cancel(t.Context(), schedule.ID)
+		assert.ErrorIs(t, err, domain.ErrScheduleNotCancellable)
+	})
+}
+
+func TestSchedule_Interrupted_NotRepeated(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv, scheduler := newTestScheduler(t, repo, testSchedulerPolicy)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		applied, err := scheduler.Create(t.Context(), id, domain.ScheduleDeposit, 25, time.Now().Add(-time.Second), "")
+		require.NoError(t, err)
+		lost, err := scheduler.Create(t.Context(), id, domain.ScheduleDeposit, 5, time.Now().Add(-time.Second), "")
+		require.NoError(t, err)
+
+		// Исполнитель захватил оба расписания, провёл одно и упал
+		claims, err := repo.Schedules.Claim(t.Context(), time.Millisecond, 10)
+		require.NoError(t, err)
+		require.Len(t, claims, 2)
+		ctx := audit.WithActor(t.Context(), audit.Actor{ID: SchedulerActor(applied.ID)})
+		_, err = srv.Deposit(ctx, id, 25)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+
+		n, err := scheduler.RunDue(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		wallet, err := srv.Get(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(130), wallet.Balance())
+
+		for _, id := range []uuid.UUID{applied.ID, lost.ID} {
+			got, err := scheduler.Get(t.Context(), id)
+			require.NoError(t, err)
+			assert.Equal(t, domain.ScheduleCompleted, got.Status)
+			assert.Equal(t, int64(1), got.Runs)
+		}
+	})
+}
+
+func TestSchedule_Settle(t *testing.T) {
+	t.Parallel()
+	scheduler := NewScheduleService(nil, nil, nil, testSchedulerPolicy, testLogger)
+	now := time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC)
+
+	recurring := domain.Schedule{Cron: "0 9 * * *", Status: domain.ScheduleRunning, Attempts: 2, MaxAttempts: 3}
+	assert.Equal(t, "success", scheduler.settle(&recurring, nil, now))
+	assert.Equal(t, domain.SchedulePending, recurring.Status)
+	assert.Equal(t, time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC), recurring.NextRunAt)
+	assert.Zero(t, recurring.Attempts)
+	assert.Equal(t, int64(1), recurring.Runs)
+
+	// Исчерпавшее попытки повторяющееся расписание ждёт следующего запуска
+	recurring.Attempts = 3
+	assert.Equal(t, "failed", scheduler.settle(&recurring, domain.ErrInsufficientBalance, now))
+	assert.Equal(t, domain.SchedulePending, recurring.Status)
+	assert.Equal(t, int64(1), recurring.Failures)
+	assert.Equal(t, domain.ErrInsufficientBalance.Error(), recurring.LastError)
+
+	oneOff := domain.Schedule{Status: domain.ScheduleRunning, Attempts: 1, MaxAttempts: 3}
+	assert.Equal(t, "failed", scheduler.settle(&oneOff, domain.ErrWalletNotFound, now))
+	assert.Equal(t, domain.ScheduleFailed, oneOff.Status)
+
+	retried := domain.Schedule{Status: domain.ScheduleRunning, Attempts: 2, MaxAttempts: 3}
+	assert.Equal(t, "retry", scheduler.settle(&retried, domain.ErrWalletBusy, now))
+	assert.Equal(t, now.Add(2*time.Minute), retried.NextRunAt)
+}
+
+func TestSchedule_Backoff_Capped(t *testing.T) {
+	t.Parallel()
+	scheduler := NewScheduleService(nil, nil, nil, testSchedulerPolicy, testLogger)
+
+	assert.Equal(t, time.Minute, scheduler.backoff(1))
+	assert.Equal(t, 4*time.Minute, scheduler.backoff(3))
+	assert.Equal(t, maxScheduleRetryDelay, scheduler.backoff(100))
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"log/slog"
+	"time"
 	"wallet-service/internal/domain"
 	"wallet-service/internal/repository"
 
@@ -12,16 +14,118 @@ import (
 
 type Wallet interface {
 	Get(ctx context.Context, id uuid.UUID) (*domain.Wallet, error)
-	Deposit(ctx context.Context, id uuid.UUID, amount int64) (*domain.Wallet, error)
-	Withdraw(ctx context.Context, id uuid.UUID, amount int64) (*domain.Wallet, error)
+	Deposit(ctx context.Context, id uuid.UUID, amount int64) (*domain.Receipt, error)
+	Withdraw(ctx context.Context, id uuid.UUID, amount int64) (*domain.Receipt, error)
+	Split(ctx context.Context, sourceID uuid.UUID, amount int64, shares []domain.SplitShare, remainder string) (*domain.SplitReceipt, error)
+}
+
+type Audit interface {
+	Record(ctx context.Context, action, outcome string) error
+	List(ctx context.Context, filter repository.AuditFilter) ([]domain.AuditRecord, error)
+}
+
+type Chain interface {
+	Verify(ctx context.Context, walletID *uuid.UUID) (*domain.ChainReport, error)
+	Checkpoint(ctx context.Context) (*domain.Checkpoint, error)
+}
+
+// Statement emits statements line by line, so they are never held in memory
+// whole.
+type Statement interface {
+	Statement(ctx context.Context, walletID uuid.UUID, from, to time.Time, emit func(line *domain.StatementLine) error) error
+	Statements(ctx context.Context, from, to time.Time, emit func(line *domain.StatementLine) error) error
+}
+
+// History answers questions about past balances.
+type History interface {
+	BalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (int64, error)
+	Snapshot(ctx context.Context) (int, error)
+}
+
+// Tier assigns wallets to fee tiers.
+type Tier interface {
+	SetTier(ctx context.Context, walletID uuid.UUID, tier string) error
+}
+
+// Interest pays interest on savings wallets.
+type Interest interface {
+	SetRate(ctx context.Context, walletID uuid.UUID, rate int64) (*domain.InterestAccount, error)
+	Get(ctx context.Context, walletID uuid.UUID) (*domain.InterestAccount, error)
+	Run(ctx context.Context) (accrued, paid int, err error)
+}
+
+// Bonus grants bonus credit that expires.
+type Bonus interface {
+	Grant(ctx context.Context, walletID uuid.UUID, amount int64, expiresAt time.Time) (*domain.Receipt, error)
+	Expire(ctx context.Context) (expired int, err error)
+}
+
+// Escrow holds funds of a buyer until both sides of a deal confirm it.
+type Escrow interface {
+	Create(ctx context.Context, buyerID, sellerID uuid.UUID, amount int64, expiresAt time.Time) (*domain.Escrow, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Escrow, error)
+	Transitions(ctx context.Context, id uuid.UUID) ([]domain.EscrowTransition, error)
+	Confirm(ctx context.Context, id uuid.UUID, party string) (*domain.Escrow, error)
+	Cancel(ctx context.Context, id uuid.UUID) (*domain.Escrow, error)
+	Expire(ctx context.Context) (expired int, err error)
+}
+
+// Schedule runs wallet operations later, once or on a cron schedule.
+type Schedule interface {
+	Create(ctx context.Context, walletID uuid.UUID, operation string, amount int64, runAt time.Time, cron string) (*domain.Schedule, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Schedule, error)
+	List(ctx context.Context, walletID uuid.UUID, status string) ([]domain.Schedule, error)
+	Cancel(ctx context.Context, id uuid.UUID) (*domain.Schedule, error)
 }
 
 type Service struct {
 	Wallet
+	Audit     Audit
+	Chain     Chain
+	Statement Statement
+	History   History
+	// Schedule is left to the caller, which knows the scheduler policy; it
+	// stays nil on a storage without repository.Schedules.
+	Schedule Schedule
+	// Tier is nil on a storage without repository.Tiers.
+	Tier Tier
+	// Interest is nil on a storage without repository.Interest or a
+	// ledger to read past balances from.
+	Interest Interest
+	// Bonus is nil on a storage without repository.Bonuses.
+	Bonus Bonus
+	// Escrow is nil on a storage without repository.Escrows.
+	Escrow Escrow
 }
 
-func NewService(repo *repository.Repository) *Service {
-	return &Service{
-		Wallet: NewWalletService(repo.Wallet),
+// NewService wires the services to repo. Wallet operations are audited,
+// chained into the ledger, charged by tier and split into cash and bonus when
+// repo has them.
+func NewService(repo *repository.Repository, log *slog.Logger, opts ...Option) *Service {
+	opts = append([]Option{WithAudit(repo.Audit), WithLedger(repo.Ledger), WithTiers(repo.Tiers), WithBonuses(repo.Bonuses)}, opts...)
+	wallet := NewWalletService(repo.Wallet, log, opts...)
+	s := &Service{
+		Wallet: wallet,
+	}
+	if repo.Audit != nil {
+		s.Audit = NewAuditService(repo.Audit)
+	}
+	if repo.Tiers != nil {
+		s.Tier = NewTierService(repo.Tiers, wallet.tx, repo.Audit)
+	}
+	if repo.Ledger != nil {
+		s.Chain = NewChainService(repo.Wallet, repo.Ledger, log)
+		s.Statement = NewStatementService(repo.Wallet, repo.Ledger)
+		s.History = NewHistoryService(repo.Wallet, repo.Ledger, log)
+	}
+	if repo.Interest != nil && s.History != nil {
+		s.Interest = NewInterestService(repo.Interest, wallet, s.History, repo.Audit, log)
+	}
+	if repo.Bonuses != nil {
+		s.Bonus = NewBonusService(repo.Bonuses, wallet, log)
+	}
+	if repo.Escrows != nil {
+		s.Escrow = NewEscrowService(repo.Escrows, wallet, log)
 	}
+	return s
 }
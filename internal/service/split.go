@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/metrics"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Split takes amount from the source wallet and divides it among the
+// recipients by shares, in one transaction. The wallets are locked and
+// changed in the order of lockOrder, so neither splits sharing wallets nor a
+// split and an operation paying a fee deadlock each other. No fee is
+// charged: a platform fee is a share like any other.
+func (s *WalletService) Split(ctx context.Context, sourceID uuid.UUID, amount int64, shares []domain.SplitShare, remainder string) (*domain.SplitReceipt, error) {
+	ctx, span := startOperationSpan(ctx, "WalletService.Split", sourceID, amount)
+
+	receipt, err := s.split(ctx, sourceID, amount, shares, remainder)
+	metrics.WalletOperations.WithLabelValues(operationSplit, operationOutcome(err)).Inc()
+	tracing.End(span, err)
+
+	return receipt, err
+}
+
+func (s *WalletService) split(ctx context.Context, sourceID uuid.UUID, amount int64, shares []domain.SplitShare, remainder string) (*domain.SplitReceipt, error) {
+	split, err := domain.NewSplit(sourceID, amount, shares, remainder)
+	if err != nil {
+		return nil, err
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("split.recipients", len(split.Credits)))
+
+	order := s.lockOrder(split)
+	changes := make(map[uuid.UUID]change, len(order))
+	for _, id := range order {
+		changes[id] = splitChange(split, id)
+	}
+
+	results := make(map[uuid.UUID]*applied, len(order))
+	err = s.tx.Run(ctx, func(c context.Context) error {
+		clear(results)
+		for _, id := range order {
+			res := &applied{}
+			results[id] = res
+			if err := s.apply(c, id, changes[id], res); err != nil {
+				if id != sourceID {
+					return fmt.Errorf("recipient %s: %w", id, err)
+				}
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Only the source is audited: nothing was credited.
+		res, ok := results[sourceID]
+		if !ok {
+			res = &applied{}
+		}
+		_, err = s.finish(ctx, sourceID, changes[sourceID], res, err)
+		return nil, err
+	}
+
+	for _, id := range order {
+		if id == sourceID {
+			continue
+		}
+		if receipt, err := s.finish(ctx, id, changes[id], results[id], nil); err == nil {
+			receipt.Wallet.Release()
+		}
+	}
+	receipt, err := s.finish(ctx, sourceID, changes[sourceID], results[sourceID], nil)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.SplitReceipt{Wallet: receipt.Wallet, Split: split}, nil
+}
+
+// lockOrder is Split.LockOrder with the fee collector moved to the end:
+// operations paying a fee lock it after their own wallet, so every
+// transaction takes it last.
+func (s *WalletService) lockOrder(split *domain.Split) []uuid.UUID {
+	order := split.LockOrder()
+	if s.fees == nil {
+		return order
+	}
+	if i := slices.Index(order, s.collector); i >= 0 {
+		order = append(slices.Delete(order, i, i+1), s.collector)
+	}
+	return order
+}
+
+// splitChange is the change a split makes to wallet id: the debit of the
+// source or the credit of a recipient.
+func splitChange(split *domain.Split, id uuid.UUID) change {
+	if id == split.SourceID {
+		return change{
+			action: domain.AuditSplit,
+			kind:   domain.TransactionSplitOut,
+			amount: split.Debit,
+			apply: func(w *domain.Wallet) error {
+				return w.Withdraw(split.Debit)
+			},
+		}
+	}
+
+	amount := split.Credit(id)
+	return change{
+		action: domain.AuditSplitCredit,
+		kind:   domain.TransactionSplitIn,
+		amount: amount,
+		apply: func(w *domain.Wallet) error {
+			return w.Deposit(amount)
+		},
+	}
+}
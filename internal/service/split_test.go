@@ -0,0 +1,120 @@
+package service
+
+import (
+	"testing"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit_DividedAmongRecipients(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		source := uuid.MustParse(testdb.Wallet10000AmountID)
+		merchant := uuid.MustParse(testdb.WalletCorrectID)
+		platform := uuid.MustParse(testdb.WalletEmptyWalletID)
+
+		// Последний получатель не существует: откатывается весь платёж
+		receipt, err := srv.Split(t.Context(), source, 1001, []domain.SplitShare{
+			{WalletID: merchant, Percent: 6667},
+			{WalletID: platform, Amount: 50},
+			{WalletID: uuid.New(), Percent: 3333},
+		}, domain.SplitRemainderLargest)
+		require.ErrorIs(t, err, domain.ErrWalletNotFound)
+		assert.Nil(t, receipt)
+		assert.Equal(t, int64(10000), balanceOf(t, srv, source))
+		assert.Equal(t, int64(100), balanceOf(t, srv, merchant))
+		assert.Equal(t, int64(0), balanceOf(t, srv, platform))
+
+		// Доли в процентах делят остаток после фиксированных и должны давать 100%
+		receipt, err = srv.Split(t.Context(), source, 1001, []domain.SplitShare{
+			{WalletID: merchant, Percent: 6667},
+			{WalletID: platform, Amount: 50},
+		}, domain.SplitRemainderSource)
+		require.ErrorIs(t, err, domain.ErrSplitShares)
+		assert.Nil(t, receipt)
+
+		receipt, err = srv.Split(t.Context(), source, 1000, []domain.SplitShare{
+			{WalletID: merchant, Percent: 7000},
+			{WalletID: platform, Percent: 3000},
+		}, domain.SplitRemainderSource)
+		require.NoError(t, err)
+		defer receipt.Wallet.Release()
+
+		assert.Equal(t, int64(9000), receipt.Wallet.Balance())
+		assert.Equal(t, int64(800), balanceOf(t, srv, merchant))
+		assert.Equal(t, int64(300), balanceOf(t, srv, platform))
+
+		out, err := repo.Ledger.Last(t.Context(), source)
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionSplitOut, out.Kind)
+		assert.Equal(t, int64(-1000), out.Delta())
+		in, err := repo.Ledger.Last(t.Context(), platform)
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionSplitIn, in.Kind)
+	})
+}
+
+func TestSplit_LockOrder_CollectorLast(t *testing.T) {
+	srv := NewWalletService(nil, testLogger, newTestFees(t))
+	source := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	split, err := domain.NewSplit(source, 100, []domain.SplitShare{
+		{WalletID: testCollectorID, Amount: 10},
+		{WalletID: uuid.Max, Amount: 90},
+	}, "")
+	require.NoError(t, err)
+
+	// Комиссионный кошелёк блокируется последним, как при списании с комиссией
+	assert.Equal(t, []uuid.UUID{source, uuid.Max, testCollectorID}, srv.lockOrder(split))
+}
+
+func TestSplit_InsufficientFunds_NothingCredited(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		source := uuid.MustParse(testdb.WalletCorrectID)
+		recipient := uuid.MustParse(testdb.WalletEmptyWalletID)
+
+		_, err := srv.Split(t.Context(), source, 101, []domain.SplitShare{{WalletID: recipient, Amount: 101}}, "")
+		require.ErrorIs(t, err, domain.ErrInsufficientBalance)
+
+		assert.Equal(t, int64(100), balanceOf(t, srv, source))
+		assert.Equal(t, int64(0), balanceOf(t, srv, recipient))
+	})
+}
+
+func TestConcurrency_OppositeSplits_BothSucceed(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		a := uuid.MustParse(testdb.Wallet10000AmountID)
+		b := uuid.MustParse(testdb.WalletCorrectID)
+		c := uuid.MustParse(testdb.WalletEmptyWalletID)
+
+		// Встречные платежи блокируют кошельки в одном порядке и не взаимоблокируются
+		errs := make(chan error, 20)
+		for i := 0; i < 10; i++ {
+			go func() {
+				_, err := srv.Split(t.Context(), a, 10, []domain.SplitShare{{WalletID: b, Percent: 5000}, {WalletID: c, Percent: 5000}}, "")
+				errs <- err
+			}()
+			go func() {
+				_, err := srv.Split(t.Context(), b, 10, []domain.SplitShare{{WalletID: c, Amount: 5}, {WalletID: a, Amount: 5}}, "")
+				errs <- err
+			}()
+		}
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, <-errs)
+		}
+
+		assert.Equal(t, int64(10000-100+50), balanceOf(t, srv, a))
+		assert.Equal(t, int64(100+50-100), balanceOf(t, srv, b))
+		assert.Equal(t, int64(100), balanceOf(t, srv, c))
+	})
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// statementPageSize is how many transactions are held in memory at a time
+// while a statement is written.
+const statementPageSize = 500
+
+var ErrInvalidPeriod = errors.New("statement period must end after it starts")
+
+type StatementService struct {
+	wallets repository.Wallet
+	ledger  repository.Ledger
+}
+
+func NewStatementService(wallets repository.Wallet, ledger repository.Ledger) *StatementService {
+	return &StatementService{wallets: wallets, ledger: ledger}
+}
+
+// Statement passes the statement of the wallet for [from, to) to emit line
+// by line. A zero from starts at the first transaction. Nothing is emitted
+// when the wallet does not exist; an error from emit stops the statement.
+func (s *StatementService) Statement(ctx context.Context, walletID uuid.UUID, from, to time.Time, emit func(line *domain.StatementLine) error) (err error) {
+	ctx, span := tracer.Start(ctx, "StatementService.Statement", trace.WithAttributes(
+		attribute.String("wallet.id", walletID.String()),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if !from.Before(to) {
+		return ErrInvalidPeriod
+	}
+	return s.statement(ctx, walletID, from, to, emit)
+}
+
+// Statements passes the statements of all wallets for [from, to) to emit,
+// one wallet after another in id order.
+func (s *StatementService) Statements(ctx context.Context, from, to time.Time, emit func(line *domain.StatementLine) error) (err error) {
+	ctx, span := tracer.Start(ctx, "StatementService.Statements")
+	defer func() { tracing.End(span, err) }()
+
+	if !from.Before(to) {
+		return ErrInvalidPeriod
+	}
+
+	return eachWallet(ctx, s.ledger, func(id uuid.UUID) (bool, error) {
+		err := s.statement(ctx, id, from, to, emit)
+		// A wallet removed since it was listed has nothing to report.
+		if errors.Is(err, domain.ErrWalletNotFound) {
+			return true, nil
+		}
+		return err == nil, err
+	})
+}
+
+func (s *StatementService) statement(ctx context.Context, walletID uuid.UUID, from, to time.Time, emit func(line *domain.StatementLine) error) error {
+	wallet, err := s.wallets.Get(ctx, walletID)
+	if err != nil {
+		return err
+	}
+	balance := wallet.Balance()
+	wallet.Release()
+
+	prev, err := s.ledger.LastBefore(ctx, walletID, from)
+	if err != nil {
+		return err
+	}
+
+	var afterSeq int64
+	if prev != nil {
+		afterSeq, balance = prev.Seq, prev.BalanceAfter
+	}
+	page, err := s.ledger.List(ctx, walletID, afterSeq, statementPageSize)
+	if err != nil {
+		return err
+	}
+	if prev == nil && len(page) > 0 {
+		// Nothing before the period: start from the balance the first
+		// transaction was applied to.
+		balance = page[0].BalanceAfter - page[0].Delta()
+	}
+
+	line := &domain.StatementLine{Type: domain.StatementOpening, WalletID: walletID, At: from, Balance: balance}
+	if err := emit(line); err != nil {
+		return err
+	}
+
+	for len(page) > 0 {
+		for i := range page {
+			tx := &page[i]
+			if !tx.At.Before(to) {
+				page = nil
+				break
+			}
+			balance = tx.BalanceAfter
+			line := &domain.StatementLine{Type: domain.StatementMovement, WalletID: walletID, At: tx.At, Balance: balance, Movement: tx}
+			if err := emit(line); err != nil {
+				return err
+			}
+		}
+		if len(page) < statementPageSize {
+			break
+		}
+
+		page, err = s.ledger.List(ctx, walletID, page[len(page)-1].Seq, statementPageSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	line = &domain.StatementLine{Type: domain.StatementClosing, WalletID: walletID, At: to, Balance: balance}
+	return emit(line)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
+	"wallet-service/pkg/testdb"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectStatement(t *testing.T, srv *Service, id uuid.UUID, from, to time.Time) []*domain.StatementLine {
+	t.Helper()
+	var lines []*domain.StatementLine
+	err := srv.Statement.Statement(t.Context(), id, from, to, func(line *domain.StatementLine) error {
+		lines = append(lines, line)
+		return nil
+	})
+	require.NoError(t, err)
+	return lines
+}
+
+func TestStatement_Period_OpeningMovementsClosing(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		_, err := srv.Deposit(t.Context(), id, 50)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+		from := time.Now()
+		_, err = srv.Withdraw(t.Context(), id, 30)
+		require.NoError(t, err)
+		_, err = srv.Deposit(t.Context(), id, 5)
+		require.NoError(t, err)
+
+		lines := collectStatement(t, srv, id, from, time.Now().Add(time.Second))
+
+		require.Len(t, lines, 4)
+		assert.Equal(t, domain.StatementOpening, lines[0].Type)
+		assert.Equal(t, int64(150), lines[0].Balance)
+		assert.Equal(t, int64(-30), lines[1].Movement.Delta())
+		assert.Equal(t, int64(120), lines[1].Balance)
+		assert.Equal(t, int64(125), lines[2].Balance)
+		assert.Equal(t, domain.StatementClosing, lines[3].Type)
+		assert.Equal(t, int64(125), lines[3].Balance)
+	})
+}
+
+func TestStatement_NoTransactions_OpeningIsBalance(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		id := uuid.MustParse(testdb.WalletCorrectID)
+
+		lines := collectStatement(t, srv, id, time.Time{}, time.Now())
+
+		// Кошелёк без транзакций: только начальный и конечный остаток
+		require.Len(t, lines, 2)
+		assert.Equal(t, int64(100), lines[0].Balance)
+		assert.Equal(t, int64(100), lines[1].Balance)
+	})
+}
+
+func TestStatement_MissingWallet_NothingEmitted(t *testing.T) {
+	t.Parallel()
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewService(repo, testLogger)
+		emitted := false
+
+		err := srv.Statement.Statement(t.Context(), uuid.MustParse(testdb.WalletNonExistentID), time.Time{}, time.Now(),
+			func(*domain.StatementLine) error {
+				emitted = true
+				return nil
+			})
+
+		assert.ErrorIs(t, err, domain.ErrWalletNotFound)
+		assert.False(t, emitted)
+	})
+}
+
+func TestStatement_EmptyPeriod_Rejected(t *testing.T) {
+	t.Parallel()
+	srv := NewStatementService(nil, nil)
+	now := time.Now()
+
+	err := srv.Statement(t.Context(), uuid.New(), now, now, nil)
+
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"wallet-service/internal/domain"
+	"wallet-service/internal/repository"
+	"wallet-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TierService struct {
+	tiers repository.Tiers
+	tx    *repository.TxRunner
+	audit repository.Audit
+}
+
+// NewTierService records every change of a tier in audit unless it is nil,
+// in the transaction tx runs the change in.
+func NewTierService(tiers repository.Tiers, tx *repository.TxRunner, audit repository.Audit) *TierService {
+	return &TierService{tiers: tiers, tx: tx, audit: audit}
+}
+
+// SetTier moves the wallet to tier; its later operations pay the fees of
+// that tier.
+func (s *TierService) SetTier(ctx context.Context, walletID uuid.UUID, tier string) (err error) {
+	ctx, span := tracer.Start(ctx, "TierService.SetTier", trace.WithAttributes(
+		attribute.String("wallet.id", walletID.String()),
+		attribute.String("wallet.tier", tier),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return s.tx.Run(ctx, func(c context.Context) error {
+		if err := s.tiers.SetTier(c, walletID, tier); err != nil {
+			return err
+		}
+		if s.audit == nil {
+			return nil
+		}
+		record := newAuditRecord(c, domain.AuditTierSet, operationOutcome(nil))
+		record.WalletID = &walletID
+		return s.audit.Append(c, record)
+	})
+}
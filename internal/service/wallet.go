@@ -2,99 +2,534 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wallet-service/config"
+	"wallet-service/internal/dbsource"
 	"wallet-service/internal/domain"
+	"wallet-service/internal/fees"
+	"wallet-service/internal/logger"
+	"wallet-service/internal/metrics"
 	"wallet-service/internal/repository"
+	"wallet-service/internal/tracing"
 
 	"github.com/google/uuid"
-	"github.com/ydb-platform/ydb-go-sdk/v3/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("wallet-service/internal/service")
+
+// ErrFeeWalletMissing is returned when the wallet fees are credited to does
+// not exist. It is a fault of the configuration, not of the caller.
+var ErrFeeWalletMissing = errors.New("fee wallet not found")
+
+const (
+	operationDeposit  = "deposit"
+	operationWithdraw = "withdraw"
+	operationInterest = "interest"
+	operationBonus    = "bonus"
+	operationEscrow   = "escrow"
+	operationSplit    = "split"
 )
 
 type WalletService struct {
-	r repository.Wallet
+	r      repository.Wallet
+	tx     *repository.TxRunner
+	audit  repository.Audit
+	ledger repository.Ledger
+	tiers  repository.Tiers
+	fees   *fees.Schedule
+	// bonuses, when set, splits balances into cash and bonus; order is
+	// the spending order of withdrawals.
+	bonuses repository.Bonuses
+	order   string
+	// collector is the wallet fees are credited to.
+	collector uuid.UUID
+	log       *slog.Logger
 }
 
+// Get reads the wallet. With bonuses it is read from the primary, where the
+// bonus buckets live, so a lagging replica cannot split it against buckets
+// newer than its balance.
 func (s *WalletService) Get(ctx context.Context, id uuid.UUID) (*domain.Wallet, error) {
-	return s.r.Get(ctx, id)
+	if s.bonuses != nil {
+		ctx = dbsource.WithConsistency(ctx, dbsource.Strong)
+	}
+	wallet, err := s.r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.loadBonuses(ctx, wallet); err != nil {
+		wallet.Release()
+		return nil, err
+	}
+	return wallet, nil
+}
+
+func (s *WalletService) Deposit(ctx context.Context, id uuid.UUID, amount int64) (*domain.Receipt, error) {
+	ctx, span := startOperationSpan(ctx, "WalletService.Deposit", id, amount)
+
+	receipt, err := s.update(ctx, id, change{
+		action: domain.AuditDeposit,
+		kind:   domain.TransactionDeposit,
+		fee:    fees.OperationDeposit,
+		amount: amount,
+		apply: func(w *domain.Wallet) error {
+			return w.Deposit(amount)
+		},
+	})
+	metrics.WalletOperations.WithLabelValues(operationDeposit, operationOutcome(err)).Inc()
+	tracing.End(span, err)
+
+	return receipt, err
+}
+
+func (s *WalletService) Withdraw(ctx context.Context, id uuid.UUID, amount int64) (*domain.Receipt, error) {
+	ctx, span := startOperationSpan(ctx, "WalletService.Withdraw", id, amount)
+
+	receipt, err := s.update(ctx, id, change{
+		action: domain.AuditWithdraw,
+		kind:   domain.TransactionWithdraw,
+		fee:    fees.OperationWithdraw,
+		amount: amount,
+		apply: func(w *domain.Wallet) error {
+			return w.Withdraw(amount)
+		},
+	})
+	metrics.WalletOperations.WithLabelValues(operationWithdraw, operationOutcome(err)).Inc()
+	tracing.End(span, err)
+
+	return receipt, err
+}
+
+// payInterest deposits interest to the wallet free of fees. pay runs in the
+// transaction of the deposit, so the payout is recorded with it or not at
+// all.
+func (s *WalletService) payInterest(ctx context.Context, id uuid.UUID, amount int64, pay func(ctx context.Context) error) (*domain.Receipt, error) {
+	ctx, span := startOperationSpan(ctx, "WalletService.payInterest", id, amount)
+
+	receipt, err := s.update(ctx, id, change{
+		action: domain.AuditInterest,
+		kind:   domain.TransactionInterest,
+		amount: amount,
+		apply: func(w *domain.Wallet) error {
+			return w.Deposit(amount)
+		},
+		within: pay,
+	})
+	metrics.WalletOperations.WithLabelValues(operationInterest, operationOutcome(err)).Inc()
+	tracing.End(span, err)
+
+	return receipt, err
+}
+
+// grantBonus deposits a bucket of bonus expiring at expiresAt, free of
+// fees.
+func (s *WalletService) grantBonus(ctx context.Context, id uuid.UUID, amount int64, expiresAt time.Time) (*domain.Receipt, error) {
+	ctx, span := startOperationSpan(ctx, "WalletService.grantBonus", id, amount)
+
+	bonus := domain.Bonus{
+		ID:        uuid.New(),
+		Amount:    amount,
+		ExpiresAt: expiresAt.UTC().Truncate(time.Microsecond),
+		GrantedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	receipt, err := s.update(ctx, id, change{
+		action: domain.AuditBonus,
+		kind:   domain.TransactionBonus,
+		amount: amount,
+		apply: func(w *domain.Wallet) error {
+			return w.GrantBonus(bonus)
+		},
+	})
+	metrics.WalletOperations.WithLabelValues(operationBonus, operationOutcome(err)).Inc()
+	tracing.End(span, err)
+
+	return receipt, err
+}
+
+// expireBonuses removes the expired bonus of the wallet and returns how
+// much was removed.
+func (s *WalletService) expireBonuses(ctx context.Context, id uuid.UUID) (int64, error) {
+	var expired int64
+	err := s.tx.Run(ctx, func(c context.Context) error {
+		expired = 0
+
+		wallet, err := s.r.GetForUpdate(c, id)
+		if err != nil {
+			return err
+		}
+		if err = s.loadBonuses(c, wallet); err != nil {
+			return err
+		}
+		before := wallet.Balance()
+		if expired = wallet.Expire(time.Now()); expired == 0 {
+			return nil
+		}
+
+		updatedWallet, err := s.r.Update(c, wallet)
+		if err != nil {
+			return err
+		}
+		defer updatedWallet.Release()
+		if err = s.recordExpiry(c, id, expired, before, updatedWallet.Balance()); err != nil {
+			return err
+		}
+		return s.bonuses.SaveBonuses(c, id, wallet.Bonuses())
+	})
+	if err != nil {
+		return 0, err
+	}
+	metrics.BonusExpired.Add(float64(expired))
+	return expired, nil
+}
+
+// change is a balance change made by update.
+type change struct {
+	// action is audited, kind is chained into the ledger.
+	action string
+	kind   string
+	// fee is the fee operation charged on amount; empty charges none.
+	fee    string
+	amount int64
+	apply  func(w *domain.Wallet) error
+	// within, when set, runs last in the transaction of the change; an
+	// error from it rolls the change back.
+	within func(ctx context.Context) error
+}
+
+// update applies a balance change under a row lock, then charges its fee and
+// credits it to the collector, locked after the wallet. Bonus that has
+// expired is removed first, so it is never spent. The whole unit of
+// work is repeated when the transaction fails with a transient error. A
+// successful change is chained into the ledger and audited in the same
+// transaction; a failed one is audited afterwards.
+func (s *WalletService) update(ctx context.Context, id uuid.UUID, ch change) (*domain.Receipt, error) {
+	var res applied
+	err := s.tx.Run(ctx, func(c context.Context) error {
+		res = applied{}
+		return s.apply(c, id, ch, &res)
+	})
+	return s.finish(ctx, id, ch, &res, err)
+}
+
+// applied is what apply did to a wallet, filled in as it goes.
+type applied struct {
+	wallet  *domain.Wallet
+	before  *int64
+	fee     int64
+	expired int64
 }
 
-func (s *WalletService) Deposit(ctx context.Context, id uuid.UUID, amount int64) (*domain.Wallet, error) {
-	c, tx, err := s.r.WithTx(ctx)
+// apply makes the change in the transaction of ctx, for update or a caller
+// that changes more than the wallet in one transaction. Such a caller
+// reports the outcome with finish once the transaction ends.
+func (s *WalletService) apply(ctx context.Context, id uuid.UUID, ch change, res *applied) error {
+	wallet, err := s.r.GetForUpdate(ctx, id)
 	if err != nil {
-		log.Error(err)
-		return nil, err
+		return err
 	}
+	if err = s.loadBonuses(ctx, wallet); err != nil {
+		return err
+	}
+	unexpired := wallet.Balance()
+	res.expired = wallet.Expire(time.Now())
+	balance := wallet.Balance()
+	res.before = &balance
 
-	defer func() {
-		if err = tx.Rollback(ctx); err != nil {
-			log.Error(err)
+	if err = ch.apply(wallet); err != nil {
+		return err
+	}
+	applied := wallet.Balance()
+
+	if res.fee, err = s.fee(ctx, ch.fee, id, ch.amount); err != nil {
+		return err
+	}
+	if res.fee > 0 {
+		if err = wallet.Withdraw(res.fee); err != nil {
+			return err
 		}
-	}()
+	}
+
+	if res.wallet, err = s.r.Update(ctx, wallet); err != nil {
+		return err
+	}
+	if err = s.keepBonuses(ctx, wallet, res.wallet); err != nil {
+		return err
+	}
 
-	wallet, err := s.r.GetForUpdate(c, id)
+	if res.expired > 0 {
+		if err = s.recordExpiry(ctx, id, res.expired, unexpired, balance); err != nil {
+			return err
+		}
+	}
+	if err = s.appendTransaction(ctx, id, ch.kind, ch.amount, applied); err != nil {
+		return err
+	}
+	after := res.wallet.Balance()
+	if res.fee > 0 {
+		if err = s.appendTransaction(ctx, id, domain.TransactionFee, res.fee, after); err != nil {
+			return err
+		}
+	}
+	if err = s.appendAudit(ctx, ch.action, id, res.before, &after, operationOutcome(nil)); err != nil {
+		return err
+	}
+
+	if res.fee > 0 {
+		if err = s.collect(ctx, res.fee); err != nil {
+			return err
+		}
+	}
+	if ch.within != nil {
+		return ch.within(ctx)
+	}
+	return nil
+}
+
+// finish reports a change made by apply once its transaction has ended with
+// err, auditing it when it failed.
+func (s *WalletService) finish(ctx context.Context, id uuid.UUID, ch change, res *applied, err error) (*domain.Receipt, error) {
 	if err != nil {
-		log.Error(err)
+		// The request may have timed out, the record is still due.
+		if auditErr := s.appendAudit(context.WithoutCancel(ctx), ch.action, id, res.before, nil, operationOutcome(err)); auditErr != nil {
+			metrics.AuditWriteFailures.Inc()
+			s.log.WarnContext(ctx, "failed to audit wallet operation",
+				slog.String("wallet_id", id.String()),
+				slog.String("action", ch.action),
+				logger.Err(auditErr),
+			)
+		}
 		return nil, err
 	}
 
-	if err = wallet.Deposit(amount); err != nil {
-		log.Error(err)
-		return nil, err
+	if res.fee > 0 {
+		metrics.FeesCollected.WithLabelValues(ch.fee).Add(float64(res.fee))
+	}
+	if res.expired > 0 {
+		metrics.BonusExpired.Add(float64(res.expired))
+	}
+	s.log.DebugContext(ctx, "wallet balance updated",
+		slog.String("wallet_id", id.String()),
+		slog.Int64("balance", res.wallet.Balance()),
+		slog.Int64("fee", res.fee),
+	)
+
+	return &domain.Receipt{Wallet: res.wallet, Fee: res.fee}, nil
+}
+
+// fee returns the fee the wallet pays for operation on amount. The collector
+// pays none, and neither does a change without a fee operation.
+func (s *WalletService) fee(ctx context.Context, operation string, id uuid.UUID, amount int64) (int64, error) {
+	if s.fees == nil || operation == "" || id == s.collector {
+		return 0, nil
+	}
+
+	var tier string
+	if s.tiers != nil {
+		var err error
+		if tier, err = s.tiers.Tier(ctx, id); err != nil {
+			return 0, err
+		}
 	}
+	return s.fees.Fee(operation, tier, amount), nil
+}
 
-	updatedWallet, err := s.r.Update(c, wallet)
+// collect credits fee to the collector.
+func (s *WalletService) collect(ctx context.Context, fee int64) error {
+	collector, err := s.r.GetForUpdate(ctx, s.collector)
+	if errors.Is(err, domain.ErrWalletNotFound) {
+		// Not the wallet of the caller: the service is misconfigured.
+		return fmt.Errorf("%w: %s", ErrFeeWalletMissing, s.collector)
+	}
 	if err != nil {
-		log.Error(err)
-		return nil, err
+		return fmt.Errorf("fee wallet: %w", err)
+	}
+	before := collector.Balance()
+	if err = collector.Deposit(fee); err != nil {
+		return fmt.Errorf("fee wallet: %w", err)
 	}
 
-	if err = tx.Commit(c); err != nil {
-		log.Error(err)
-		return nil, err
+	updated, err := s.r.Update(ctx, collector)
+	if err != nil {
+		return err
 	}
+	after := updated.Balance()
+	updated.Release()
 
-	return updatedWallet, nil
+	if err = s.appendTransaction(ctx, s.collector, domain.TransactionFeeIncome, fee, after); err != nil {
+		return err
+	}
+	return s.appendAudit(ctx, domain.AuditFee, s.collector, &before, &after, operationOutcome(nil))
 }
 
-func (s *WalletService) Withdraw(ctx context.Context, id uuid.UUID, amount int64) (*domain.Wallet, error) {
-	c, tx, err := s.r.WithTx(ctx)
+// loadBonuses loads the bonus buckets of the wallet and its spending order.
+// Without bonuses the whole balance is cash.
+func (s *WalletService) loadBonuses(ctx context.Context, wallet *domain.Wallet) error {
+	if s.bonuses == nil {
+		return nil
+	}
+	bonuses, err := s.bonuses.Bonuses(ctx, wallet.ID())
 	if err != nil {
-		log.Error(err)
-		return nil, err
+		return err
 	}
+	wallet.SetSpendingOrder(s.order)
+	return wallet.SetBonuses(bonuses)
+}
 
-	defer func() {
-		if err = tx.Rollback(ctx); err != nil {
-			log.Error(err)
+// keepBonuses stores the buckets of wallet when the change touched them and
+// carries them over to updated, the wallet as stored.
+func (s *WalletService) keepBonuses(ctx context.Context, wallet, updated *domain.Wallet) error {
+	if s.bonuses == nil {
+		return nil
+	}
+	if wallet.BonusesChanged() {
+		if err := s.bonuses.SaveBonuses(ctx, wallet.ID(), wallet.Bonuses()); err != nil {
+			return err
 		}
-	}()
+	}
+	return updated.SetBonuses(wallet.Bonuses())
+}
 
-	wallet, err := s.r.GetForUpdate(c, id)
-	if err != nil {
-		log.Error(err)
-		return nil, err
+// recordExpiry chains the removal of expired bonus into the ledger and
+// audits it.
+func (s *WalletService) recordExpiry(ctx context.Context, id uuid.UUID, expired, before, after int64) error {
+	if err := s.appendTransaction(ctx, id, domain.TransactionBonusExpired, expired, after); err != nil {
+		return err
 	}
+	return s.appendAudit(ctx, domain.AuditBonusExpire, id, &before, &after, operationOutcome(nil))
+}
 
-	if err = wallet.Withdraw(amount); err != nil {
-		log.Error(err)
-		return nil, err
+// appendTransaction chains a change by amount of kind, leaving the balance at
+// balanceAfter, onto the last transaction of the wallet. The row lock taken
+// by update keeps concurrent changes from forking the chain.
+func (s *WalletService) appendTransaction(ctx context.Context, id uuid.UUID, kind string, amount, balanceAfter int64) error {
+	if s.ledger == nil {
+		return nil
 	}
 
-	updatedWallet, err := s.r.Update(c, wallet)
+	prev, err := s.ledger.Last(ctx, id)
 	if err != nil {
-		log.Error(err)
-		return nil, err
+		return err
 	}
+	return s.ledger.Append(ctx, domain.NewTransaction(prev, id, kind, amount, balanceAfter, time.Now()))
+}
 
-	if err = tx.Commit(c); err != nil {
-		log.Error(err)
-		return nil, err
+func (s *WalletService) appendAudit(ctx context.Context, action string, id uuid.UUID, before, after *int64, outcome string) error {
+	if s.audit == nil {
+		return nil
+	}
+
+	record := newAuditRecord(ctx, action, outcome)
+	record.WalletID = &id
+	record.BalanceBefore = before
+	record.BalanceAfter = after
+	return s.audit.Append(ctx, record)
+}
+
+func startOperationSpan(ctx context.Context, name string, id uuid.UUID, amount int64) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(
+		attribute.String("wallet.id", id.String()),
+		attribute.Int64("wallet.amount", amount),
+	))
+}
+
+func operationOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, domain.ErrWalletNotFound):
+		return "not_found"
+	case errors.Is(err, domain.ErrInsufficientBalance):
+		return "insufficient_balance"
+	case errors.Is(err, repository.ErrTxConflict):
+		return "conflict"
+	case errors.Is(err, domain.ErrWalletBusy):
+		return "busy"
+	case errors.Is(err, domain.ErrUnavailable):
+		return "unavailable"
+	case errors.Is(err, domain.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, domain.ErrZeroAmount), errors.Is(err, domain.ErrNegativeAmount), errors.Is(err, domain.ErrOverflow):
+		return "rejected"
+	default:
+		return "error"
+	}
+}
+
+type Option func(s *WalletService)
+
+// WithAudit records every deposit and withdrawal in log. A nil log disables
+// auditing.
+func WithAudit(log repository.Audit) Option {
+	return func(s *WalletService) {
+		s.audit = log
+	}
+}
+
+// WithLedger records every balance change in the transaction chain of the
+// wallet. A nil ledger disables it.
+func WithLedger(ledger repository.Ledger) Option {
+	return func(s *WalletService) {
+		s.ledger = ledger
 	}
+}
+
+// WithTiers looks up the tier a wallet pays fees by in tiers. Without it
+// every wallet pays the default fees.
+func WithTiers(tiers repository.Tiers) Option {
+	return func(s *WalletService) {
+		s.tiers = tiers
+	}
+}
 
-	return updatedWallet, nil
+// WithBonuses splits balances into cash and the bonus buckets kept in
+// bonuses. A nil bonuses keeps every balance cash.
+func WithBonuses(bonuses repository.Bonuses) Option {
+	return func(s *WalletService) {
+		s.bonuses = bonuses
+	}
+}
+
+// WithSpendingOrder sets whether withdrawals spend bonus or cash first; see
+// domain.SpendBonusFirst and domain.SpendCashFirst.
+func WithSpendingOrder(order string) Option {
+	return func(s *WalletService) {
+		s.order = order
+	}
 }
 
-func NewWalletService(r repository.Wallet) *WalletService {
-	return &WalletService{
-		r: r,
+// WithFees charges the fees of schedule on every operation and credits them
+// to the collector wallet in the same transaction. A nil schedule charges
+// nothing.
+func WithFees(schedule *fees.Schedule, collector uuid.UUID) Option {
+	return func(s *WalletService) {
+		s.fees = schedule
+		s.collector = collector
+	}
+}
+
+// WithRetry sets how operations are repeated after serialization failures,
+// deadlocks and dropped connections; repository.DefaultRetry is used
+// otherwise.
+func WithRetry(policy config.RetryConfig) Option {
+	return func(s *WalletService) {
+		s.tx = repository.NewTxRunner(s.r, policy, s.log)
+	}
+}
+
+func NewWalletService(r repository.Wallet, log *slog.Logger, opts ...Option) *WalletService {
+	s := &WalletService{
+		r:   r,
+		tx:  repository.NewTxRunner(r, repository.DefaultRetry, log),
+		log: log,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
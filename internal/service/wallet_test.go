@@ -1,21 +1,40 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"testing"
 	"wallet-service/internal/domain"
 	"wallet-service/internal/repository"
 	mock_repository "wallet-service/internal/repository/mocks"
+	"wallet-service/internal/repository/repositorytest"
 	"wallet-service/pkg/testdb"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
 var migrationsPath = []string{"../../migrations", "../../migrations/test"}
 
+var testLogger = slog.New(slog.DiscardHandler)
+
+// newTestService wires repo with NewService and returns its service of type
+// T, skipping the test when the storage leaves that service out.
+func newTestService[T any](t *testing.T, repo *repository.Repository, opts ...Option) (*Service, T) {
+	t.Helper()
+	srv := NewService(repo, testLogger, opts...)
+	for _, s := range []any{srv.Tier, srv.Interest, srv.Bonus, srv.Escrow} {
+		if picked, ok := s.(T); ok {
+			return srv, picked
+		}
+	}
+	var zero T
+	t.Skipf("storage does not support %T", zero)
+	return nil, zero
+}
+
 func TestDeposit_SuccessfulDeposit_Succeeds(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -24,7 +43,7 @@ func TestDeposit_SuccessfulDeposit_Succeeds(t *testing.T) {
 	assert.NoError(t, err)
 
 	repo := mock_repository.NewMockWallet(ctrl)
-	srv := NewWalletService(repo)
+	srv := NewWalletService(repo, testLogger)
 
 	var value int64 = 100
 
@@ -36,10 +55,60 @@ func TestDeposit_SuccessfulDeposit_Succeeds(t *testing.T) {
 	repo.EXPECT().GetForUpdate(t.Context(), wallet.ID()).Return(wallet, nil)
 	repo.EXPECT().Update(t.Context(), wallet).Return(wallet, nil)
 
-	finalWallet, err := srv.Deposit(t.Context(), wallet.ID(), value)
+	receipt, err := srv.Deposit(t.Context(), wallet.ID(), value)
 	assert.NoError(t, err)
 
-	assert.Equal(t, value, finalWallet.Balance())
+	assert.Equal(t, value, receipt.Wallet.Balance())
+}
+
+func TestDeposit_CommitConflictsOnce_Retried(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	repo := mock_repository.NewMockWallet(ctrl)
+	srv := NewWalletService(repo, testLogger)
+
+	mockTx := mock_repository.NewMockWalletTx(ctrl)
+	gomock.InOrder(
+		mockTx.EXPECT().Commit(gomock.Any()).Return(repository.ErrTxConflict),
+		mockTx.EXPECT().Commit(gomock.Any()).Return(nil),
+	)
+	mockTx.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+	// Каждая попытка заново читает кошелёк в новой транзакции
+	repo.EXPECT().WithTx(gomock.Any()).Return(t.Context(), mockTx, nil).Times(2)
+	repo.EXPECT().GetForUpdate(t.Context(), id).DoAndReturn(func(_ context.Context, id uuid.UUID) (*domain.Wallet, error) {
+		return domain.NewWallet(id, 10)
+	}).Times(2)
+	repo.EXPECT().Update(t.Context(), gomock.Any()).DoAndReturn(func(_ context.Context, w *domain.Wallet) (*domain.Wallet, error) {
+		return w, nil
+	}).Times(2)
+
+	receipt, err := srv.Deposit(t.Context(), id, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), receipt.Wallet.Balance())
+}
+
+func TestDeposit_ConflictsEveryTime_ReturnsError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := uuid.New()
+	repo := mock_repository.NewMockWallet(ctrl)
+	srv := NewWalletService(repo, testLogger)
+
+	mockTx := mock_repository.NewMockWalletTx(ctrl)
+	mockTx.EXPECT().Rollback(gomock.Any()).AnyTimes()
+
+	attempts := repository.DefaultRetry.MaxAttempts
+	repo.EXPECT().WithTx(gomock.Any()).Return(t.Context(), mockTx, nil).Times(attempts)
+	repo.EXPECT().GetForUpdate(t.Context(), id).Return(nil, repository.ErrTxConflict).Times(attempts)
+
+	_, err := srv.Deposit(t.Context(), id, 5)
+	assert.ErrorIs(t, err, repository.ErrTxConflict)
+	// Исчерпанные повторы отдаются клиенту как занятый кошелёк
+	assert.ErrorIs(t, err, domain.ErrWalletBusy)
 }
 
 func TestDeposit_GetForUpdateReturnsError_ReturnsError(t *testing.T) {
@@ -47,7 +116,7 @@ func TestDeposit_GetForUpdateReturnsError_ReturnsError(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mock_repository.NewMockWallet(ctrl)
-	srv := NewWalletService(repo)
+	srv := NewWalletService(repo, testLogger)
 
 	walletID := uuid.New()
 	expectedErr := errors.New("get for update error")
@@ -59,9 +128,9 @@ func TestDeposit_GetForUpdateReturnsError_ReturnsError(t *testing.T) {
 	repo.EXPECT().GetForUpdate(t.Context(), walletID).Return(nil, expectedErr)
 	repo.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
 
-	finalWallet, err := srv.Deposit(t.Context(), walletID, 100)
+	receipt, err := srv.Deposit(t.Context(), walletID, 100)
 	assert.Error(t, err)
-	assert.Nil(t, finalWallet)
+	assert.Nil(t, receipt)
 }
 
 func TestDeposit_UpdateReturnsError_ReturnsError(t *testing.T) {
@@ -72,7 +141,7 @@ func TestDeposit_UpdateReturnsError_ReturnsError(t *testing.T) {
 	assert.NoError(t, err)
 
 	repo := mock_repository.NewMockWallet(ctrl)
-	srv := NewWalletService(repo)
+	srv := NewWalletService(repo, testLogger)
 
 	updateErr := errors.New("update balance error")
 
@@ -85,9 +154,9 @@ func TestDeposit_UpdateReturnsError_ReturnsError(t *testing.T) {
 	repo.EXPECT().GetForUpdate(t.Context(), wallet.ID()).Return(wallet, nil)
 	repo.EXPECT().Update(t.Context(), wallet).Return(nil, updateErr)
 
-	finalWallet, err := srv.Deposit(t.Context(), wallet.ID(), value)
+	receipt, err := srv.Deposit(t.Context(), wallet.ID(), value)
 	assert.Error(t, err)
-	assert.Nil(t, finalWallet)
+	assert.Nil(t, receipt)
 }
 
 func TestWithdraw_SuccessfulWithdrawal_Succeeds(t *testing.T) {
@@ -100,7 +169,7 @@ func TestWithdraw_SuccessfulWithdrawal_Succeeds(t *testing.T) {
 	assert.NoError(t, err)
 
 	repo := mock_repository.NewMockWallet(ctrl)
-	srv := NewWalletService(repo)
+	srv := NewWalletService(repo, testLogger)
 
 	mockTx := mock_repository.NewMockWalletTx(ctrl)
 	mockTx.EXPECT().Commit(gomock.Any()).Return(nil).Times(1)
@@ -110,9 +179,9 @@ func TestWithdraw_SuccessfulWithdrawal_Succeeds(t *testing.T) {
 	repo.EXPECT().GetForUpdate(t.Context(), wallet.ID()).Return(wallet, nil)
 	repo.EXPECT().Update(t.Context(), wallet).Return(wallet, nil)
 
-	finalWallet, err := srv.Withdraw(t.Context(), wallet.ID(), withdrawAmount)
+	receipt, err := srv.Withdraw(t.Context(), wallet.ID(), withdrawAmount)
 	assert.NoError(t, err)
-	assert.Equal(t, initialBalance-withdrawAmount, finalWallet.Balance())
+	assert.Equal(t, initialBalance-withdrawAmount, receipt.Wallet.Balance())
 }
 
 func TestWithdraw_InsufficientFundsError_ReturnsError(t *testing.T) {
@@ -126,7 +195,7 @@ func TestWithdraw_InsufficientFundsError_ReturnsError(t *testing.T) {
 	assert.NoError(t, err)
 
 	repo := mock_repository.NewMockWallet(ctrl)
-	srv := NewWalletService(repo)
+	srv := NewWalletService(repo, testLogger)
 
 	mockTx := mock_repository.NewMockWalletTx(ctrl)
 	mockTx.EXPECT().Rollback(gomock.Any()).Times(1)
@@ -135,20 +204,15 @@ func TestWithdraw_InsufficientFundsError_ReturnsError(t *testing.T) {
 	repo.EXPECT().GetForUpdate(t.Context(), wallet.ID()).Return(wallet, nil)
 	repo.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
 
-	finalWallet, err := srv.Withdraw(t.Context(), wallet.ID(), withdrawAmount)
+	receipt, err := srv.Withdraw(t.Context(), wallet.ID(), withdrawAmount)
 	assert.ErrorIs(t, err, domain.ErrInsufficientBalance)
-	assert.Nil(t, finalWallet)
+	assert.Nil(t, receipt)
 }
 
 func TestConcurrency_TwoParallelWithdrawSecondGetsInsufficientFundsError_ReturnsError(t *testing.T) {
 	t.Parallel()
-	testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
-		repo, err := repository.NewPostgresRepository(pool)
-		if err != nil {
-			t.Fatalf("error inititalization repository: %v", err)
-		}
-
-		srv := NewWalletService(repo.Wallet)
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewWalletService(repo.Wallet, testLogger)
 
 		id, err := uuid.Parse(testdb.WalletCorrectID)
 		assert.NoError(t, err)
@@ -185,13 +249,8 @@ func TestConcurrency_TwoParallelWithdrawSecondGetsInsufficientFundsError_Returns
 
 func TestConcurrency_TwoParallelDepositBothSucceed_Succeed(t *testing.T) {
 	t.Parallel()
-	testdb.WithDB(t, migrationsPath, func(pool *pgxpool.Pool) {
-		repo, err := repository.NewPostgresRepository(pool)
-		if err != nil {
-			t.Fatalf("error inititalization repository: %v", err)
-		}
-
-		srv := NewWalletService(repo.Wallet)
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		srv := NewWalletService(repo.Wallet, testLogger)
 
 		id, err := uuid.Parse(testdb.WalletEmptyWalletID)
 		assert.NoError(t, err)
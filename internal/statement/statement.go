@@ -0,0 +1,121 @@
+// Package statement encodes wallet statements as CSV or JSON Lines. Lines are
+// written as they come, so a statement of any length is streamed.
+package statement
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"wallet-service/internal/domain"
+)
+
+// Supported formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+var Formats = []string{FormatCSV, FormatJSONL}
+
+type Encoder interface {
+	Encode(line *domain.StatementLine) error
+	// Flush writes buffered lines to the underlying writer.
+	Flush() error
+}
+
+// NewEncoder returns an encoder writing format to w.
+func NewEncoder(w io.Writer, format string) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}, nil
+	case FormatJSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlEncoder{w: bw, enc: json.NewEncoder(bw)}, nil
+	default:
+		return nil, fmt.Errorf("unknown statement format %q", format)
+	}
+}
+
+// ContentType is the media type of format.
+func ContentType(format string) string {
+	if format == FormatJSONL {
+		return "application/jsonl"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+var csvHeader = []string{"wallet_id", "type", "seq", "at", "kind", "amount", "balance"}
+
+type csvEncoder struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+// Encode writes a record per line; amount is signed, negative for
+// withdrawals.
+func (e *csvEncoder) Encode(line *domain.StatementLine) error {
+	if !e.headerWritten {
+		e.headerWritten = true
+		if err := e.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	record := []string{line.WalletID.String(), line.Type, "", formatTime(line.At), "", "", strconv.FormatInt(line.Balance, 10)}
+	if tx := line.Movement; tx != nil {
+		record[2] = strconv.FormatInt(tx.Seq, 10)
+		record[4] = tx.Kind
+		record[5] = strconv.FormatInt(tx.Delta(), 10)
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// Line is a statement line as written in JSON Lines.
+type Line struct {
+	WalletID string  `json:"walletId"`
+	Type     string  `json:"type"`
+	Seq      int64   `json:"seq,omitempty"`
+	At       *string `json:"at,omitempty"`
+	Kind     string  `json:"kind,omitempty"`
+	Amount   *int64  `json:"amount,omitempty"`
+	Balance  int64   `json:"balance"`
+}
+
+type jsonlEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *jsonlEncoder) Encode(line *domain.StatementLine) error {
+	out := Line{WalletID: line.WalletID.String(), Type: line.Type, Balance: line.Balance}
+	if !line.At.IsZero() {
+		at := formatTime(line.At)
+		out.At = &at
+	}
+	if tx := line.Movement; tx != nil {
+		amount := tx.Delta()
+		out.Seq, out.Kind, out.Amount = tx.Seq, tx.Kind, &amount
+	}
+	return e.enc.Encode(out)
+}
+
+func (e *jsonlEncoder) Flush() error {
+	return e.w.Flush()
+}
+
+// formatTime leaves an unbounded period start empty.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
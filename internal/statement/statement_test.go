@@ -0,0 +1,56 @@
+package statement
+
+import (
+	"bytes"
+	"testing"
+	"time"
+	"wallet-service/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLines() []*domain.StatementLine {
+	id := uuid.MustParse("3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901")
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tx := domain.NewTransaction(nil, id, domain.TransactionWithdraw, 30, 70, at)
+	return []*domain.StatementLine{
+		{Type: domain.StatementOpening, WalletID: id, Balance: 100},
+		{Type: domain.StatementMovement, WalletID: id, At: at, Balance: 70, Movement: tx},
+		{Type: domain.StatementClosing, WalletID: id, At: at.Add(time.Hour), Balance: 70},
+	}
+}
+
+func encode(t *testing.T, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	enc, err := NewEncoder(&buf, format)
+	require.NoError(t, err)
+	for _, line := range testLines() {
+		require.NoError(t, enc.Encode(line))
+	}
+	require.NoError(t, enc.Flush())
+	return buf.String()
+}
+
+func TestEncoder_CSV(t *testing.T) {
+	assert.Equal(t, "wallet_id,type,seq,at,kind,amount,balance\n"+
+		"3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901,opening,,,,,100\n"+
+		"3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901,movement,1,2026-10-19T12:00:00Z,withdraw,-30,70\n"+
+		"3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901,closing,,2026-10-19T13:00:00Z,,,70\n",
+		encode(t, FormatCSV))
+}
+
+func TestEncoder_JSONL(t *testing.T) {
+	assert.Equal(t, `{"walletId":"3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901","type":"opening","balance":100}`+"\n"+
+		`{"walletId":"3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901","type":"movement","seq":1,"at":"2026-10-19T12:00:00Z","kind":"withdraw","amount":-30,"balance":70}`+"\n"+
+		`{"walletId":"3f9a1b9e-2f64-4f42-9b4d-2d1c9a5ef901","type":"closing","at":"2026-10-19T13:00:00Z","balance":70}`+"\n",
+		encode(t, FormatJSONL))
+}
+
+func TestNewEncoder_UnknownFormat(t *testing.T) {
+	_, err := NewEncoder(&bytes.Buffer{}, "xml")
+
+	assert.Error(t, err)
+}
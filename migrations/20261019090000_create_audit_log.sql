@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE app.audit_log (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor TEXT NOT NULL,
+    source_ip TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    wallet_id UUID,
+    balance_before BIGINT,
+    balance_after BIGINT,
+    outcome TEXT NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX audit_log_wallet_id_idx ON app.audit_log (wallet_id, id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX audit_log_at_idx ON app.audit_log (at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION app.audit_log_append_only() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'app.audit_log is append-only: % is not allowed', TG_OP
+        USING ERRCODE = 'insufficient_privilege';
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER audit_log_no_update_delete
+    BEFORE UPDATE OR DELETE ON app.audit_log
+    FOR EACH ROW EXECUTE FUNCTION app.audit_log_append_only();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON app.audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION app.audit_log_append_only();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+REVOKE UPDATE, DELETE, TRUNCATE ON app.audit_log FROM PUBLIC;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS app.audit_log;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS app.audit_log_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX wallet_transactions_created_at_idx ON app.wallet_transactions (wallet_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS app.wallet_transactions_created_at_idx;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE app.wallet_balance_snapshots (
+    wallet_id UUID NOT NULL REFERENCES app.wallets (id),
+    seq BIGINT NOT NULL,
+    balance BIGINT NOT NULL,
+    at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (wallet_id, seq)
+);
+CREATE INDEX wallet_balance_snapshots_at_idx ON app.wallet_balance_snapshots (wallet_id, at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS app.wallet_balance_snapshots;
+-- +goose StatementEnd
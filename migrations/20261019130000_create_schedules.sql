@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE app.schedules (
+    id UUID PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES app.wallets (id),
+    operation TEXT NOT NULL CHECK (operation IN ('DEPOSIT', 'WITHDRAW')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    cron TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL CHECK (status IN ('pending', 'running', 'retrying', 'completed', 'failed', 'cancelled')),
+    next_run_at TIMESTAMPTZ NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL CHECK (max_attempts > 0),
+    runs BIGINT NOT NULL DEFAULT 0,
+    failures BIGINT NOT NULL DEFAULT 0,
+    last_run_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    claimed_at TIMESTAMPTZ,
+    lease_until TIMESTAMPTZ
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX schedules_due_idx ON app.schedules (next_run_at) WHERE status IN ('pending', 'retrying');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX schedules_lease_idx ON app.schedules (lease_until) WHERE status = 'running';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX schedules_wallet_id_idx ON app.schedules (wallet_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS app.schedules;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE app.wallet_tiers (
+    wallet_id UUID PRIMARY KEY REFERENCES app.wallets (id),
+    tier TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS app.wallet_tiers;
+-- +goose StatementEnd
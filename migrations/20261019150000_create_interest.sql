@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE app.interest_accounts (
+    wallet_id UUID PRIMARY KEY REFERENCES app.wallets (id),
+    rate BIGINT NOT NULL CHECK (rate BETWEEN 0 AND 10000),
+    accrued BIGINT NOT NULL DEFAULT 0 CHECK (accrued >= 0),
+    next_accrual_at TIMESTAMPTZ NOT NULL,
+    next_payout_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- One accrual per wallet and day, one payout per wallet and month: a job
+-- that runs again finds them taken.
+CREATE TABLE app.interest_accruals (
+    wallet_id UUID NOT NULL REFERENCES app.interest_accounts (wallet_id),
+    day TIMESTAMPTZ NOT NULL,
+    balance BIGINT NOT NULL,
+    rate BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (wallet_id, day)
+);
+
+CREATE TABLE app.interest_payouts (
+    wallet_id UUID NOT NULL REFERENCES app.interest_accounts (wallet_id),
+    period TIMESTAMPTZ NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    paid_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (wallet_id, period)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS app.interest_payouts;
+DROP TABLE IF EXISTS app.interest_accruals;
+DROP TABLE IF EXISTS app.interest_accounts;
+-- +goose StatementEnd
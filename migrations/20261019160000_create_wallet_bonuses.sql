@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Bonus buckets of a wallet. app.wallets.balance is the total of cash and
+-- bonus; a bucket holds what is left of one grant until it expires.
+CREATE TABLE app.wallet_bonuses (
+    id UUID PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES app.wallets (id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    expires_at TIMESTAMPTZ NOT NULL,
+    granted_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX wallet_bonuses_wallet_id_idx ON app.wallet_bonuses (wallet_id);
+CREATE INDEX wallet_bonuses_expires_at_idx ON app.wallet_bonuses (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS app.wallet_bonuses;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Funds held between a buyer and a seller. The amount has left the buyer
+-- wallet and is in no wallet until the escrow is released or refunded.
+CREATE TABLE app.escrows (
+    id UUID PRIMARY KEY,
+    buyer_id UUID NOT NULL REFERENCES app.wallets (id),
+    seller_id UUID NOT NULL REFERENCES app.wallets (id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    status TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CHECK (buyer_id <> seller_id)
+);
+
+CREATE INDEX escrows_open_expires_at_idx ON app.escrows (expires_at)
+    WHERE status IN ('held', 'buyer_confirmed', 'seller_confirmed');
+
+-- Every status change of an escrow, in order; from_status is empty for the
+-- one that created it.
+CREATE TABLE app.escrow_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    escrow_id UUID NOT NULL REFERENCES app.escrows (id),
+    event TEXT NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX escrow_transitions_escrow_id_idx ON app.escrow_transitions (escrow_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS app.escrow_transitions;
+DROP TABLE IF EXISTS app.escrows;
+-- +goose StatementEnd
@@ -58,7 +58,8 @@ func WithDB(t *testing.T, migrationsPath []string, fn func(pool *pgxpool.Pool))
 	}()
 
 	for _, path := range migrationsPath {
-		if err := goose.Up(sqlDB, path); err != nil {
+		// Fixtures are older than the latest schema migrations.
+		if err := goose.Up(sqlDB, path, goose.WithAllowMissing()); err != nil {
 			t.Fatalf("failed to apply migrations: %v", err)
 		}
 	}
@@ -0,0 +1,41 @@
+package testdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// WithYDB starts a single-node YDB and passes its connection string to fn.
+// The node advertises its container address, so clients must connect
+// through a single-connection balancer.
+func WithYDB(t *testing.T, fn func(dsn string)) {
+	ydbContainer, err := testcontainers.Run(t.Context(),
+		"ydbplatform/local-ydb:24.4",
+		testcontainers.WithExposedPorts("2136/tcp"),
+		testcontainers.WithEnv(map[string]string{
+			"GRPC_PORT":                "2136",
+			"YDB_USE_IN_MEMORY_PDISKS": "true",
+		}),
+		testcontainers.WithWaitStrategy(
+			wait.ForListeningPort("2136/tcp").WithStartupTimeout(2*time.Minute),
+		),
+	)
+	defer func() {
+		if err = testcontainers.TerminateContainer(ydbContainer); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	}()
+	if err != nil {
+		t.Fatalf("failed to start container: %s", err)
+	}
+
+	endpoint, err := ydbContainer.PortEndpoint(t.Context(), "2136/tcp", "grpc")
+	if err != nil {
+		t.Fatalf("failed to get ydb endpoint: %s", err)
+	}
+
+	fn(endpoint + "/local")
+}
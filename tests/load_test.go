@@ -7,22 +7,20 @@ import (
 	"testing"
 	"time"
 	"wallet-service/internal/handler"
+	"wallet-service/internal/health"
 	"wallet-service/internal/repository"
+	"wallet-service/internal/repository/repositorytest"
 	"wallet-service/internal/service"
 	"wallet-service/pkg/testdb"
 
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
 	vegeta "github.com/tsenart/vegeta/v12/lib"
 )
 
 func TestLoad_Deposit(t *testing.T) {
-	testdb.WithDB(t, []string{"../migrations", "../migrations/test"}, func(pool *pgxpool.Pool) {
-		repo, err := repository.NewPostgresRepository(pool)
-		assert.NoError(t, err)
-
-		services := service.NewService(repo)
-		handlers := handler.NewHandler(services)
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		services := service.NewService(repo, testLogger)
+		handlers := handler.NewHandler(services, health.New(time.Second), testLogger)
 		router := handlers.GetRouter()
 
 		server := httptest.NewServer(router)
@@ -63,12 +61,9 @@ func TestLoad_Deposit(t *testing.T) {
 }
 
 func TestLoad_Withdraw(t *testing.T) {
-	testdb.WithDB(t, []string{"../migrations", "../migrations/test"}, func(pool *pgxpool.Pool) {
-		repo, err := repository.NewPostgresRepository(pool)
-		assert.NoError(t, err)
-
-		services := service.NewService(repo)
-		handlers := handler.NewHandler(services)
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		services := service.NewService(repo, testLogger)
+		handlers := handler.NewHandler(services, health.New(time.Second), testLogger)
 		router := handlers.GetRouter()
 
 		server := httptest.NewServer(router)
@@ -108,12 +103,9 @@ func TestLoad_Withdraw(t *testing.T) {
 }
 
 func TestLoad_Get(t *testing.T) {
-	testdb.WithDB(t, []string{"../migrations", "../migrations/test"}, func(pool *pgxpool.Pool) {
-		repo, err := repository.NewPostgresRepository(pool)
-		assert.NoError(t, err)
-
-		services := service.NewService(repo)
-		handlers := handler.NewHandler(services)
+	repositorytest.WithRepository(t, migrationsPath, func(repo *repository.Repository) {
+		services := service.NewService(repo, testLogger)
+		handlers := handler.NewHandler(services, health.New(time.Second), testLogger)
 		router := handlers.GetRouter()
 
 		server := httptest.NewServer(router)